@@ -1,9 +1,12 @@
 package main
 import (
 	"context"
-	"database/sql"
+	"crypto/rand"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,120 +14,407 @@ import (
 	"time"
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
+	"desafio-api/internal/adapters/auth"
 	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/adapters/events"
+	"desafio-api/internal/adapters/health"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/metrics"
+	"desafio-api/internal/adapters/ratelimit"
 	httpHandler "desafio-api/internal/adapters/http"
 	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/adapters/storage"
+	"desafio-api/internal/adapters/tracing"
 	"desafio-api/internal/application/service"
+	"desafio-api/internal/config"
 	repoPort "desafio-api/internal/ports/repository"
 	"desafio-api/internal/domain"
-	"golang.org/x/crypto/bcrypt"
-	"strconv"
 )
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
-	cfg := loadConfig()
-	log.Printf("DB Config: Host=%s, Port=%s, User=%s, DBName=%s", 
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBName)
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	logger, err := logging.New(os.Stdout, logging.Config{Level: cfg.Log.Level, Format: cfg.Log.Format})
+	if err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+	slog.SetDefault(logger)
+	if len(args) > 0 && args[0] == "migrate" {
+		os.Exit(migrateCommand(cfg.Database, args[1:], logger))
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{Exporter: cfg.Tracing.Exporter, ServiceName: cfg.Tracing.ServiceName})
+	if err != nil {
+		fatal(logger, "failed to configure tracing", err)
+	}
+	logger.Info("database config", slog.String("env", cfg.Env), slog.String("driver", cfg.Database.Driver), slog.String("host", cfg.Database.Host), slog.String("port", cfg.Database.Port), slog.String("user", cfg.Database.User), slog.String("database", cfg.Database.Name))
 	var itemRepo repoPort.ItemRepository
 	var userRepo service.UserRepository
-	db, err := database.NewDB(database.Config{
-		Host:         cfg.DBHost,
-		Port:         cfg.DBPort,
-		User:         cfg.DBUser,
-		Password:     cfg.DBPassword,
-		DBName:       cfg.DBName,
-		SSLMode:      "disable",
-		MaxOpenConns: 25,
-		MaxIdleConns: 25,
-		MaxIdleTime:  15 * time.Minute,
-	})
+	var refreshTokenRepo repoPort.RefreshTokenRepository
+	var stockMovementRepo repoPort.StockMovementRepository
+	var stockReservationRepo repoPort.StockReservationRepository
+	var jobRepo repoPort.JobRepository
+	var outboxRepo repoPort.OutboxRepository
+	var webhookRepo repoPort.WebhookRepository
+	var itemRevisionRepo repoPort.ItemRevisionRepository
+	var idempotencyRepo repoPort.IdempotencyRepository
+	var txManager repoPort.TxManager
+	var migrationStatus httpHandler.MigrationStatusReader
+	appMetrics := metrics.New()
+	liveness := health.NewRegistry()
+	readiness := health.NewRegistry()
+	db, err := openDB(cfg.Database)
 	if err != nil {
-		log.Printf(" Erro ao conectar ao banco de dados: %v", err)
-		log.Println(" Usando repositórios simulados para demonstração")
+		logger.Error("database connection failed, using in-memory repositories", slog.Any("error", err))
+		readiness.Register("database", health.Unavailable(fmt.Errorf("using in-memory repositories: %w", err)))
 		itemRepo = repository.NewMockItemRepository()
 		userRepo = repository.NewMockUserRepository()
+		refreshTokenRepo = repository.NewMockRefreshTokenRepository()
+		stockMovementRepo = repository.NewMockStockMovementRepository()
+		stockReservationRepo = repository.NewMockStockReservationRepository()
+		jobRepo = repository.NewMockJobRepository()
+		outboxRepo = repository.NewMockOutboxRepository()
+		webhookRepo = repository.NewMockWebhookRepository()
+		itemRevisionRepo = repository.NewMockItemRevisionRepository()
+		idempotencyRepo = repository.NewMockIdempotencyRepository()
+		txManager = repository.NewMockTxManager()
 	} else {
-		log.Println(" Conexão com o banco de dados estabelecida")
-		itemRepo = repository.NewItemRepository(db)
-		userRepo = repository.NewUserRepository(db)
+		logger.Info("database connection established")
+		itemRepo, userRepo = newRepositories(cfg.Database.Driver, db)
+		refreshTokenRepo = repository.NewRefreshTokenRepository(db)
+		stockMovementRepo = repository.NewStockMovementRepository(db)
+		stockReservationRepo = repository.NewStockReservationRepository(db)
+		jobRepo = repository.NewJobRepository(db)
+		outboxRepo = repository.NewOutboxRepository(db)
+		webhookRepo = repository.NewWebhookRepository(db)
+		itemRevisionRepo = repository.NewItemRevisionRepository(db)
+		idempotencyRepo = repository.NewIdempotencyRepository(db)
+		txManager = database.NewTxManager(db)
 		defer db.Close()
-		if err := runMigrations(db); err != nil {
-			log.Printf(" Erro ao executar migrações: %v", err)
+		if err := appMetrics.RegisterDB(db.DB, cfg.Database.Name); err != nil {
+			logger.Warn("could not register database metrics", slog.Any("error", err))
+		}
+		readiness.Register("database", health.Database(db.DB))
+		migrator, err := newMigrator(db, logger)
+		if err != nil {
+			fatal(logger, "failed to load migrations", err)
+		}
+		readiness.Register("migrations", health.Migrations(migrator))
+		migrationStatus = migrator
+		if cfg.Database.AutoMigrate {
+			if err := runMigrations(migrator, logger); err != nil {
+				fatal(logger, "migrations failed; for a schema created before migrations were tracked, run migrate baseline <version> first", err)
+			}
+		}
+	}
+	readiness.RegisterInformational("outbox", health.OutboxLag(outboxRepo, cfg.Health.OutboxMaxLag))
+	itemService := service.NewItemService(itemRepo, stockMovementRepo, outboxRepo, itemRevisionRepo, txManager)
+	stockService := service.NewStockService(itemRepo, stockMovementRepo, stockReservationRepo, outboxRepo, itemRevisionRepo, txManager)
+	publisher, err := newEventPublisher(cfg.Events, logger)
+	if err != nil {
+		fatal(logger, "failed to configure event publisher", err)
+	}
+	webhookService := service.NewWebhookService(webhookRepo, events.NewWebhookSender(cfg.Events.WebhookTimeout), cfg.Events.WebhookMaxAttempts, logger)
+	outboxRelay := service.NewOutboxRelay(outboxRepo, events.NewMultiPublisher(publisher, webhookService), logger)
+	signer, err := newTokenSigner(cfg.Auth, logger)
+	if err != nil {
+		fatal(logger, "failed to configure JWT signing keys", err)
+	}
+	userService := service.NewUserService(userRepo, refreshTokenRepo, signer, logger)
+	if cfg.Auth.AdminUsername != "" {
+		if err := userService.BootstrapAdmin(context.Background(), cfg.Auth.AdminUsername); err != nil {
+			logger.Error("could not promote admin", slog.String("username", cfg.Auth.AdminUsername), slog.Any("error", err))
 		}
 	}
-	itemService := service.NewItemService(itemRepo)
-	userService := service.NewUserService(userRepo)
-	itemHandler := httpHandler.NewItemHandler(itemService)
-	authHandler := httpHandler.NewAuthHandler(userService)
-	router := setupRouter(itemHandler, authHandler, userService, db, cfg.DBName)
+	cursors, err := newCursorCodec(cfg.Auth, logger)
+	if err != nil {
+		fatal(logger, "failed to configure cursor signing key", err)
+	}
+	jobFiles, err := storage.NewLocalDir(cfg.Jobs.Dir)
+	if err != nil {
+		fatal(logger, "failed to prepare jobs directory", err)
+	}
+	readiness.Register("disk", health.Disk(cfg.Jobs.Dir, uint64(cfg.Health.DiskMinFreeMB)<<20))
+	jobService := service.NewJobService(jobRepo, logger)
+	itemHandler := httpHandler.NewItemHandler(itemService, cursors, logger)
+	itemJobHandler := httpHandler.NewItemJobHandler(itemService, jobService, jobFiles, logger)
+	itemJobHandler.RegisterRunners(jobService)
+	jobHandler := httpHandler.NewJobHandler(jobService, logger)
+	webhookHandler := httpHandler.NewWebhookHandler(webhookService, logger)
+	limiter := ratelimit.NewMemoryStore()
+	limits := newRateLimits(cfg.RateLimit, limiter, logger)
+	lockoutPolicy := ratelimit.DefaultLockoutPolicy
+	lockoutPolicy.Threshold = cfg.Auth.LoginLockout
+	authHandler := httpHandler.NewAuthHandler(userService, ratelimit.NewLockout(limiter, lockoutPolicy), appMetrics, logger)
+	adminHandler := httpHandler.NewAdminHandler(userService, logger)
+	stockHandler := httpHandler.NewStockHandler(stockService, logger)
+	router := setupRouter(itemHandler, itemJobHandler, jobHandler, webhookHandler, authHandler, adminHandler, stockHandler, userService, idempotencyRepo, limits, appMetrics, liveness, readiness, signer, logger)
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxyList()); err != nil {
+		fatal(logger, "invalid trusted proxies", err)
+	}
+	var metricsSrv *http.Server
+	if cfg.Metrics.Enabled {
+		metricsSrv = setupMetricsRoute(router, appMetrics, cfg, logger)
+	}
+	var debugSrv *http.Server
+	if cfg.Debug.Enabled {
+		debugSrv = setupDebugRoutes(router, httpHandler.NewDebugHandler(db, migrationStatus, cfg.Redacted(), logger), userService, cfg, logger)
+	}
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Port),
+		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
 		Handler:      router,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  120 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go runPeriodically(backgroundCtx, logger, time.Hour, "purge expired refresh tokens", func(ctx context.Context) error {
+		_, err := userService.PurgeExpiredRefreshTokens(ctx)
+		return err
+	})
+	go runPeriodically(backgroundCtx, logger, time.Minute, "release expired stock reservations", func(ctx context.Context) error {
+		released, err := stockService.ExpireReservations(ctx)
+		if released > 0 {
+			logger.InfoContext(ctx, "expired stock reservations released", slog.Int("count", released))
+		}
+		return err
+	})
+	go runPeriodically(backgroundCtx, logger, time.Hour, "purge deleted items", func(ctx context.Context) error {
+		purged, err := itemService.PurgeDeleted(ctx, time.Duration(cfg.Items.RetentionDays)*24*time.Hour)
+		if purged > 0 {
+			logger.InfoContext(ctx, "deleted items purged", slog.Int64("count", purged), slog.Int("retention_days", cfg.Items.RetentionDays))
+		}
+		return err
+	})
+	go runPeriodically(backgroundCtx, logger, 15*time.Second, "refresh item metrics", func(ctx context.Context) error {
+		stats, err := itemService.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		appMetrics.SetItemStats(stats)
+		return nil
+	})
+	go runPeriodically(backgroundCtx, logger, time.Minute, "prune rate limit buckets", func(ctx context.Context) error {
+		limiter.Prune()
+		return nil
+	})
+	go runPeriodically(backgroundCtx, logger, time.Hour, "remove expired job files", func(ctx context.Context) error {
+		removed, err := jobFiles.RemoveOlderThan(time.Now().Add(-cfg.Jobs.FileRetention))
+		if removed > 0 {
+			logger.InfoContext(ctx, "expired job files removed", slog.Int("count", removed))
+		}
+		return err
+	})
+	go runPeriodically(backgroundCtx, logger, time.Hour, "delete expired idempotency keys", func(ctx context.Context) error {
+		_, err := idempotencyRepo.DeleteExpired(ctx, time.Now())
+		return err
+	})
+	go runPeriodically(backgroundCtx, logger, time.Second, "relay outbox events", func(ctx context.Context) error {
+		_, err := outboxRelay.RelayPending(ctx)
+		return err
+	})
+	go runPeriodically(backgroundCtx, logger, 5*time.Second, "deliver webhooks", func(ctx context.Context) error {
+		_, err := webhookService.DeliverDue(ctx)
+		return err
+	})
+	go runPeriodically(backgroundCtx, logger, time.Minute, "requeue stale jobs", func(ctx context.Context) error {
+		requeued, failed, err := jobService.RequeueStale(ctx)
+		if requeued > 0 {
+			logger.InfoContext(ctx, "stale jobs requeued", slog.Int("count", requeued))
+		}
+		if failed > 0 {
+			logger.WarnContext(ctx, "stale jobs out of attempts failed", slog.Int("count", failed))
+		}
+		return err
+	})
+	workers := service.NewJobWorkerPool(jobService, cfg.Jobs.Workers, time.Second)
+	workers.Start()
 	go func() {
-		log.Printf("Server is running on port %d", cfg.Port)
+		logger.Info("server listening", slog.Int("port", cfg.Server.Port))
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal(logger, "failed to start server", err)
 		}
 	}()
+	if metricsSrv != nil {
+		go func() {
+			logger.Info("metrics server listening", slog.String("addr", metricsSrv.Addr))
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal(logger, "failed to start metrics server", err)
+			}
+		}()
+	}
+	if debugSrv != nil {
+		go func() {
+			logger.Warn("debug server listening", slog.String("addr", debugSrv.Addr))
+			if err := debugSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal(logger, "failed to start debug server", err)
+			}
+		}()
+	}
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	readiness.Shutdown()
+	logger.Info("shutting down server", slog.Duration("drain", cfg.Server.ShutdownDrain))
+	time.Sleep(cfg.Server.ShutdownDrain)
+	stopBackground()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal(logger, "server forced to shutdown", err)
 	}
-	log.Println("Server exiting")
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			logger.Warn("metrics server forced to shutdown", slog.Any("error", err))
+		}
+	}
+	if debugSrv != nil {
+		if err := debugSrv.Shutdown(ctx); err != nil {
+			logger.Warn("debug server forced to shutdown", slog.Any("error", err))
+		}
+	}
+	if err := workers.Shutdown(ctx); err != nil {
+		logger.Warn("running jobs were interrupted and requeued", slog.Any("error", err))
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("could not flush pending spans", slog.Any("error", err))
+	}
+	logger.Info("server exiting")
+}
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, slog.Any("error", err))
+	os.Exit(1)
 }
-type Config struct {
-	Port       int
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	JWTSecret  string
+type tokenSigner interface {
+	service.TokenSigner
+	JWKS() auth.JWKS
 }
-func loadConfig() Config {
-	return Config{
-		Port:       8080,
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "3306"),
-		DBUser:     getEnv("DB_USER", "root"),
-		DBPassword: getEnv("DB_PASSWORD", ""),
-		DBName:     getEnv("DB_NAME", "mercadolibre_challenge"),
-		JWTSecret:  getEnv("JWT_SECRET", "your-default-jwt-secret-for-development"),
+func newTokenSigner(cfg config.AuthConfig, logger *slog.Logger) (tokenSigner, error) {
+	switch {
+	case cfg.JWTKeysDir != "":
+		keySet, err := auth.LoadKeySet(cfg.JWTKeysDir, cfg.JWTActiveKey)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("JWT signing with key set", slog.String("kid", keySet.ActiveKeyID()), slog.String("dir", cfg.JWTKeysDir))
+		return keySet, nil
+	case cfg.JWTSecret != "":
+		logger.Warn("JWT signing with shared HMAC secret; set JWT_KEYS_DIR to publish keys via JWKS")
+		return auth.NewHMACSigner(cfg.JWTSecret)
+	default:
+		logger.Warn("JWT_KEYS_DIR not set, using an ephemeral Ed25519 key (tokens will not survive restarts)")
+		return auth.NewEphemeralKeySet()
 	}
 }
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+func newCursorCodec(cfg config.AuthConfig, logger *slog.Logger) (*httpHandler.CursorCodec, error) {
+	if cfg.CursorSecret != "" {
+		return httpHandler.NewCursorCodec([]byte(cfg.CursorSecret)), nil
+	}
+	logger.Warn("CURSOR_SECRET not set, using an ephemeral key (pagination cursors will not survive restarts)")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return httpHandler.NewCursorCodec(secret), nil
+}
+func newEventPublisher(cfg config.EventsConfig, logger *slog.Logger) (service.EventPublisher, error) {
+	switch cfg.Publisher {
+	case "log":
+		return events.NewLogPublisher(logger), nil
+	case "webhook":
+		logger.Info("publishing domain events to webhook", slog.String("url", cfg.WebhookURL))
+		return events.NewWebhookPublisher(cfg.WebhookURL, cfg.WebhookTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown events publisher %q (use log or webhook)", cfg.Publisher)
 	}
-	return defaultValue
 }
-func setupRouter(itemHandler *httpHandler.ItemHandler, authHandler *httpHandler.AuthHandler, userService *service.UserService, db *sqlx.DB, dbName string) *gin.Engine {
+// rateLimits are the middlewares built from the RATE_LIMIT_* settings: auth
+// guards the login and token endpoints per IP and api the /api/v1 routes per
+// IP and per user.
+type rateLimits struct {
+	auth gin.HandlerFunc
+	api  gin.HandlerFunc
+}
+func newRateLimits(cfg config.RateLimitConfig, store ratelimit.Store, logger *slog.Logger) rateLimits {
+	logger.Info("rate limits configured", slog.String("auth_per_ip", cfg.Auth.String()), slog.String("api_per_ip", cfg.APIPerIP.String()), slog.String("api_per_user", cfg.APIPerUser.String()))
+	return rateLimits{
+		auth: httpHandler.RateLimitMiddleware(store, "auth", cfg.Auth, ratelimit.Limit{}, logger),
+		api:  httpHandler.RateLimitMiddleware(store, "api", cfg.APIPerIP, cfg.APIPerUser, logger),
+	}
+}
+func openDB(cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	return database.NewDB(database.Config{
+		Driver:       cfg.Driver,
+		Host:         cfg.Host,
+		Port:         cfg.Port,
+		User:         cfg.User,
+		Password:     cfg.Password,
+		DBName:       cfg.Name,
+		SSLMode:      cfg.SSLMode,
+		MaxOpenConns: cfg.MaxOpenConns,
+		MaxIdleConns: cfg.MaxIdleConns,
+		MaxIdleTime:  cfg.MaxIdleTime,
+	})
+}
+func migrateCommand(cfg config.DatabaseConfig, args []string, logger *slog.Logger) int {
+	db, err := openDB(cfg)
+	if err != nil {
+		logger.Error("database connection failed", slog.Any("error", err))
+		return 1
+	}
+	defer db.Close()
+	if err := runMigrateCommand(db, args, logger); err != nil {
+		logger.Error("migrations failed", slog.Any("error", err))
+		return 1
+	}
+	return 0
+}
+func newRepositories(driver string, db *sqlx.DB) (repoPort.ItemRepository, service.UserRepository) {
+	if driver == database.DriverPostgres {
+		return repository.NewPostgresItemRepository(db), repository.NewPostgresUserRepository(db)
+	}
+	return repository.NewItemRepository(db), repository.NewUserRepository(db)
+}
+func runPeriodically(ctx context.Context, logger *slog.Logger, interval time.Duration, name string, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				logger.ErrorContext(ctx, "background task failed", slog.String("task", name), slog.Any("error", err))
+			}
+		}
+	}
+}
+func setupRouter(itemHandler *httpHandler.ItemHandler, itemJobHandler *httpHandler.ItemJobHandler, jobHandler *httpHandler.JobHandler, webhookHandler *httpHandler.WebhookHandler, authHandler *httpHandler.AuthHandler, adminHandler *httpHandler.AdminHandler, stockHandler *httpHandler.StockHandler, userService *service.UserService, idempotencyRepo repoPort.IdempotencyRepository, limits rateLimits, appMetrics *metrics.Metrics, liveness, readiness *health.Registry, signer tokenSigner, logger *slog.Logger) *gin.Engine {
 	if gin.Mode() == gin.DebugMode {
-		log.Println("Running in DEBUG mode")
+		logger.Info("running in debug mode")
 	}
 	router := gin.New()
-	router.Use(httpHandler.LoggingMiddleware()) 
-	router.Use(httpHandler.ErrorMiddleware())   
+	router.Use(httpHandler.RequestIDMiddleware())
+	router.Use(httpHandler.TracingMiddleware())
+	router.Use(httpHandler.MetricsMiddleware(appMetrics))
+	router.Use(httpHandler.LoggingMiddleware(logger))
+	router.Use(httpHandler.ErrorMiddleware(logger))
 	router.Use(gin.Recovery())                  
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, Idempotency-Key, If-Match, If-None-Match, traceparent, tracestate")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Link")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -137,216 +427,126 @@ func setupRouter(itemHandler *httpHandler.ItemHandler, authHandler *httpHandler.
 			"time": time.Now().Format(time.RFC3339),
 		})
 	})
-	router.GET("/debug/database", func(c *gin.Context) {
-		err := db.Ping()
-		if err != nil {
-			c.JSON(500, gin.H{
-				"status": "erro",
-				"error": err.Error(),
-			})
-			return
-		}
-		var userCount int
-		err = db.Get(&userCount, "SELECT COUNT(*) FROM users")
-		var itemCount int
-		err2 := db.Get(&itemCount, "SELECT COUNT(*) FROM items")
-		var dbErrorMsg, itemErrorMsg interface{}
-		if err != nil {
-			dbErrorMsg = err.Error()
-		}
-		if err2 != nil {
-			itemErrorMsg = err2.Error()
-		}
-		c.JSON(200, gin.H{
-			"status": "conectado",
-			"database": dbName,
-			"user_count": userCount,
-			"item_count": itemCount,
-			"db_error": dbErrorMsg,
-			"item_error": itemErrorMsg,
-		})
-	})
-	router.POST("/debug/register-test", func(c *gin.Context) {
-		var req struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
-		}
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": err.Error()})
-			return
-		}
-		user := &domain.User{
-			Username: req.Username,
-			Password: req.Password,
-		}
-		log.Printf("[DEBUG] Test Register: Starting validation")
-		if err := user.Validate(); err != nil {
-			c.JSON(400, gin.H{"error": fmt.Sprintf("Validation failed: %v", err)})
-			return
-		}
-		log.Printf("[DEBUG] Test Register: Starting password hash")
-		if err := user.HashPassword(); err != nil {
-			c.JSON(500, gin.H{"error": fmt.Sprintf("Hash failed: %v", err)})
-			return
-		}
-		log.Printf("[DEBUG] Test Register: Starting repository operation")
-		err := userService.Register(c.Request.Context(), user)
-		if err != nil {
-			c.JSON(500, gin.H{"error": fmt.Sprintf("Repository error: %v", err)})
-			return
-		}
-		c.JSON(201, gin.H{
-			"status": "success",
-			"user_id": user.ID,
-			"username": user.Username,
-		})
-	})
-	router.GET("/debug/user-test", func(c *gin.Context) {
-		user := &domain.User{
-			Username: "debuguser",
-			Password: "password123",
-		}
-		if err := user.HashPassword(); err != nil {
-			log.Printf("Erro ao criptografar senha: %v", err)
-			c.JSON(500, gin.H{"error": "Falha ao criptografar senha"})
-			return
-		}
-		repoType := "unknown"
-		switch r := userService.GetRepository().(type) {
-		case *repository.UserRepository:
-			repoType = "MySQL"
-		case *repository.MockUserRepository:
-			repoType = "Mock"
-		default:
-			repoType = fmt.Sprintf("%T", r)
-		}
-		log.Printf("Tentando salvar usuário no repositório tipo: %s", repoType)
-		err := userService.Register(c.Request.Context(), user)
-		if err != nil {
-			log.Printf("Erro ao registrar usuário: %v", err)
-			c.JSON(500, gin.H{
-				"error": fmt.Sprintf("Falha ao registrar usuário: %v", err),
-				"repository_type": repoType,
-			})
-			return
-		}
-		c.JSON(200, gin.H{
-			"status": "Usuário de teste criado com sucesso",
-			"user_id": user.ID,
-			"repository_type": repoType,
-		})
-	})
-	router.GET("/debug/jwt-test", func(c *gin.Context) {
-		user := &domain.User{
-			ID:       1,
-			Username: "test",
-		}
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "your-super-secret-jwt-key"
-		}
-		claims := &domain.JWTClaims{
-			UserID:   1,
-			Username: "test",
-			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
-				IssuedAt:  jwt.NewNumericDate(time.Now()),
-			},
+	router.GET("/livez", httpHandler.HealthHandler(liveness))
+	router.GET("/readyz", httpHandler.HealthHandler(readiness))
+	router.GET("/.well-known/jwks.json", httpHandler.JWKSHandler(signer))
+	router.POST("/register", limits.auth, authHandler.Register)
+	router.POST("/login", limits.auth, authHandler.Login)
+	router.POST("/token/refresh", limits.auth, authHandler.Refresh)
+	router.POST("/logout", authHandler.Logout)
+	v1 := router.Group("/api/v1")
+	v1.Use(httpHandler.AuthMiddleware(userService, logger))
+	v1.Use(limits.api)
+	{
+		items := v1.Group("/items")
+		items.Use(httpHandler.IdempotencyMiddleware(idempotencyRepo, httpHandler.DefaultIdempotencyTTL, logger))
+		{
+			canRead := httpHandler.RequirePermission(domain.PermissionItemsRead)
+			canWrite := httpHandler.RequirePermission(domain.PermissionItemsWrite)
+			canDelete := httpHandler.RequirePermission(domain.PermissionItemsDelete)
+			items.POST("", canWrite, itemHandler.Create)
+			items.POST("/import", canWrite, itemHandler.Import)
+			items.POST("/import/async", canWrite, itemJobHandler.Import)
+			items.GET("", canRead, itemHandler.List)
+			items.GET("/export", canRead, itemHandler.Export)
+			items.POST("/export/async", canRead, itemJobHandler.Export)
+			items.GET("/:id", canRead, itemHandler.GetByID)
+			items.PUT("/:id", canWrite, itemHandler.Update)
+			items.PATCH("/:id", canWrite, itemHandler.Patch)
+			items.DELETE("/:id", canDelete, itemHandler.Delete)
+			items.POST("/:id/restore", canDelete, itemHandler.Restore)
+			items.GET("/:id/history", canRead, itemHandler.History)
+			items.POST("/:id/stock/increment", canWrite, stockHandler.Increment)
+			items.POST("/:id/stock/decrement", canWrite, stockHandler.Decrement)
+			items.GET("/:id/stock/movements", canRead, stockHandler.ListMovements)
+			items.POST("/:id/reservations", canWrite, stockHandler.Reserve)
 		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString([]byte(secret))
-		if err != nil {
-			log.Printf("Erro ao assinar token: %v", err)
-			c.JSON(500, gin.H{
-				"error": "Falha ao gerar token: " + err.Error(),
-				"secret_length": len(secret),
-			})
-			return
+		reservations := v1.Group("/reservations")
+		{
+			canRead := httpHandler.RequirePermission(domain.PermissionItemsRead)
+			canWrite := httpHandler.RequirePermission(domain.PermissionItemsWrite)
+			reservations.GET("/:id", canRead, stockHandler.GetReservation)
+			reservations.POST("/:id/commit", canWrite, stockHandler.Commit)
+			reservations.POST("/:id/release", canWrite, stockHandler.Release)
 		}
-		c.JSON(200, gin.H{
-			"token": tokenString,
-			"user":  user,
-			"secret_used": secret,
-		})
-	})
-	router.GET("/debug/insert-user", func(c *gin.Context) {
-		log.Println("Iniciando teste de inserção direta de usuário")
-		username := "testuser_" + strconv.FormatInt(time.Now().Unix(), 10)
-		password := "password123"
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-		if err != nil {
-			c.JSON(500, gin.H{"error": "Falha ao gerar hash: " + err.Error()})
-			return
+		jobs := v1.Group("/jobs")
+		{
+			jobs.GET("/:id", jobHandler.Get)
+			jobs.POST("/:id/cancel", jobHandler.Cancel)
+			jobs.POST("/:id/retry", jobHandler.Retry)
+			jobs.GET("/:id/download", itemJobHandler.Download)
 		}
-		query := "INSERT INTO users (username, password) VALUES (?, ?)"
-		result, err := db.ExecContext(c.Request.Context(), query, username, string(hashedPassword))
-		if err != nil {
-			log.Printf("[ERROR] Falha ao inserir usuário: %v", err)
-			c.JSON(500, gin.H{"error": "Falha ao inserir usuário: " + err.Error()})
-			return
+		webhooks := v1.Group("/webhooks")
+		webhooks.Use(httpHandler.RequireRole(domain.RoleAdmin))
+		{
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.GET("", webhookHandler.List)
+			webhooks.GET("/:id", webhookHandler.Get)
+			webhooks.PUT("/:id", webhookHandler.Update)
+			webhooks.DELETE("/:id", webhookHandler.Delete)
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
 		}
-		id, _ := result.LastInsertId()
-		c.JSON(200, gin.H{
-			"message": "Usuário inserido com sucesso via SQL direto",
-			"user_id": id,
-			"username": username,
-		})
-	})
-	router.POST("/register", authHandler.Register)
-	router.POST("/login", authHandler.Login)
-	v1 := router.Group("/api/v1")
-	v1.Use(httpHandler.AuthMiddleware(userService))
-	{
-		items := v1.Group("/items")
+		admin := v1.Group("/admin")
+		admin.Use(httpHandler.RequireRole(domain.RoleAdmin))
 		{
-			items.POST("", itemHandler.Create)
-			items.GET("", itemHandler.List)
-			items.GET("/:id", itemHandler.GetByID)
-			items.PUT("/:id", itemHandler.Update)
-			items.DELETE("/:id", itemHandler.Delete)
+			admin.GET("/users", adminHandler.ListUsers)
+			admin.PUT("/users/:id/role", adminHandler.AssignRole)
 		}
 	}
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-		})
-	})
+	router.GET("/health", httpHandler.HealthHandler(readiness))
 	return router
 }
-func runMigrations(db *sqlx.DB) error {
-	var tableItems string
-	err := db.Get(&tableItems, `
-        SELECT table_name 
-        FROM information_schema.tables 
-        WHERE table_schema = DATABASE() 
-        AND table_name = 'items'
-        LIMIT 1
-    `)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Println("Warning: items table does not exist. Please run migrations first.")
-			log.Println("You can run the SQL migrations from the migrations/001_create_items_table.mysql.sql file.")
-			return nil
-		}
-		return fmt.Errorf("failed to check if items table exists: %w", err)
-	}
-	log.Printf("Table 'items' exists: %s\n", tableItems)
-	var tableUsers string
-	err = db.Get(&tableUsers, `
-        SELECT table_name 
-        FROM information_schema.tables 
-        WHERE table_schema = DATABASE() 
-        AND table_name = 'users'
-        LIMIT 1
-    `)
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("failed to check if users table exists: %w", err)
-	}
-	if err == sql.ErrNoRows {
-		log.Println("Warning: users table does not exist. Please run migrations first.")
-		log.Println("You can run the SQL migrations from the migrations/002_create_users_table.mysql.sql file.")
-	} else {
-		log.Printf("Table 'users' exists: %s\n", tableUsers)
+// setupMetricsRoute mounts /metrics on router or, when cfg.Metrics.Addr is
+// set, on a separate server that it returns so main can start and stop it.
+func setupMetricsRoute(router *gin.Engine, appMetrics *metrics.Metrics, cfg config.Config, logger *slog.Logger) *http.Server {
+	if cfg.Metrics.Addr == "" {
+		logger.Warn("metrics exposed on the main server without authentication")
+		router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", appMetrics.Handler())
+	return &http.Server{
+		Addr:         cfg.Metrics.Addr,
+		Handler:      mux,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+}
+// setupDebugRoutes mounts the debug handler under /debug, on router or, when
+// cfg.Debug.Addr is set, on a separate server that it returns so main can
+// start and stop it. That server has no write timeout since CPU profiles and
+// traces stream for as long as the client asks.
+func setupDebugRoutes(router *gin.Engine, handler *httpHandler.DebugHandler, userService *service.UserService, cfg config.Config, logger *slog.Logger) *http.Server {
+	engine := router
+	if cfg.Debug.Addr != "" {
+		engine = gin.New()
+		// The list was already accepted by the main router.
+		engine.SetTrustedProxies(cfg.Server.TrustedProxyList())
+		engine.Use(httpHandler.RequestIDMiddleware())
+		engine.Use(httpHandler.LoggingMiddleware(logger))
+		engine.Use(httpHandler.ErrorMiddleware(logger))
+		engine.Use(gin.Recovery())
+	}
+	debug := engine.Group("/debug", httpHandler.DebugAuthMiddleware(cfg.Debug.Token, userService, logger))
+	debug.GET("/database", handler.Database)
+	debug.GET("/migrations", handler.Migrations)
+	debug.GET("/build", handler.Build)
+	debug.GET("/config", handler.Config)
+	if cfg.Debug.Pprof {
+		debug.GET("/pprof/*profile", handler.Pprof)
+		debug.POST("/pprof/*profile", handler.Pprof)
+	}
+	logger.Warn("debug routes enabled", slog.String("addr", cfg.Debug.Addr), slog.Bool("pprof", cfg.Debug.Pprof), slog.Bool("token", cfg.Debug.Token != ""))
+	if cfg.Debug.Addr == "" {
+		return nil
+	}
+	return &http.Server{
+		Addr:        cfg.Debug.Addr,
+		Handler:     engine,
+		ReadTimeout: cfg.Server.ReadTimeout,
+		IdleTimeout: cfg.Server.IdleTimeout,
 	}
-	return nil
 }
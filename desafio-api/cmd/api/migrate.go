@@ -0,0 +1,113 @@
+package main
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"desafio-api/internal/adapters/database/migrate"
+	"desafio-api/migrations"
+	"github.com/jmoiron/sqlx"
+)
+const migrateUsage = "usage: desafio-api migrate up|down [steps]|status|redo|baseline <version>"
+func newMigrator(db *sqlx.DB, logger *slog.Logger) (*migrate.Migrator, error) {
+	return migrate.New(db, migrations.FS, logger)
+}
+func runMigrations(migrator *migrate.Migrator, logger *slog.Logger) error {
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		logger.Info("database schema is up to date", slog.Int64("version", migrator.LatestVersion()))
+		return nil
+	}
+	logger.Info("migrations applied", slog.Int("count", len(applied)), slog.Int64("version", applied[len(applied)-1].Version))
+	return nil
+}
+func runMigrateCommand(db *sqlx.DB, args []string, logger *slog.Logger) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	migrator, err := newMigrator(db, logger)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("no applied migrations to revert")
+		}
+		return err
+	case "redo":
+		migration, err := migrator.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("redone   %04d_%s\n", migration.Version, migration.Name)
+		return nil
+	case "baseline":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version: %q", args[1])
+		}
+		recorded, err := migrator.Baseline(ctx, version)
+		for _, migration := range recorded {
+			fmt.Printf("baseline %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(recorded) == 0 {
+			fmt.Println("nothing to record")
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			switch {
+			case status.Missing:
+				state = "missing"
+			case status.Modified:
+				state = "modified"
+			case status.Applied:
+				state = "applied"
+			}
+			appliedAt := ""
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d  %-40s %-9s %s\n", status.Version, status.Name, state, appliedAt)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -3,10 +3,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"desafio-api/internal/adapters/auth"
 	"desafio-api/internal/adapters/database"
 	"desafio-api/internal/adapters/repository"
 	"desafio-api/internal/application/service"
+	"desafio-api/internal/config"
 	"desafio-api/internal/domain"
 	"github.com/joho/godotenv"
 	"golang.org/x/crypto/bcrypt"
@@ -14,18 +17,33 @@ import (
 func main() {
 	fmt.Println("=== Diagnóstico Direto da API ===")
 	_ = godotenv.Load()
+	cfg, _, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("ERRO: Configuração inválida: %v", err)
+	}
+	driver := cfg.Database.Driver
 	db, err := database.NewDB(database.Config{
-		Host:     getEnv("DB_HOST", "db"),  
-		Port:     getEnv("DB_PORT", "3306"),
-		User:     getEnv("DB_USER", "root"),
-		Password: getEnv("DB_PASSWORD", "password"),
-		DBName:   getEnv("DB_NAME", "desafio_db"),
+		Driver:       driver,
+		Host:         cfg.Database.Host,
+		Port:         cfg.Database.Port,
+		User:         cfg.Database.User,
+		Password:     cfg.Database.Password,
+		DBName:       cfg.Database.Name,
+		SSLMode:      cfg.Database.SSLMode,
+		MaxOpenConns: cfg.Database.MaxOpenConns,
+		MaxIdleConns: cfg.Database.MaxIdleConns,
+		MaxIdleTime:  cfg.Database.MaxIdleTime,
 	})
 	if err != nil {
 		log.Fatalf("ERRO: Falha na conexão com o banco de dados: %v", err)
 	}
 	fmt.Println("✓ Conexão com banco de dados estabelecida")
-	userRepo := repository.NewUserRepository(db)
+	var userRepo service.UserRepository
+	if driver == database.DriverPostgres {
+		userRepo = repository.NewPostgresUserRepository(db)
+	} else {
+		userRepo = repository.NewUserRepository(db)
+	}
 	fmt.Println("✓ Repositório de usuários criado")
 	fmt.Println("\n=== Teste 1: Buscar usuário existente ===")
 	user, err := userRepo.FindByUsername(context.Background(), "direct_test_user")
@@ -61,7 +79,11 @@ func main() {
 		}
 	}
 	fmt.Println("\n=== Teste 4: Testar serviço de usuário ===")
-	userService := service.NewUserService(userRepo)
+	signer, err := auth.NewEphemeralKeySet()
+	if err != nil {
+		log.Fatalf("ERRO: Falha ao gerar chave de assinatura: %v", err)
+	}
+	userService := service.NewUserService(userRepo, repository.NewRefreshTokenRepository(db), signer, slog.Default())
 	fmt.Println("✓ Serviço de usuário criado")
 	fmt.Println("\n=== Teste 5: Registrar usuário via serviço ===")
 	serviceUser := &domain.User{
@@ -75,18 +97,12 @@ func main() {
 		fmt.Printf("✓ Usuário registrado via serviço: ID=%d, Username=%s\n", serviceUser.ID, serviceUser.Username)
 	}
 	fmt.Println("\n=== Teste 6: Login via serviço ===")
-	token, err := userService.Login(context.Background(), serviceUser.Username, "password123")
+	pair, err := userService.Login(context.Background(), serviceUser.Username, "password123")
 	if err != nil {
 		fmt.Printf("✗ ERRO: Falha ao fazer login: %v\n", err)
 	} else {
-		fmt.Printf("✓ Login bem-sucedido, token gerado: %s...\n", token[:20])
-	}
-}
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+		fmt.Printf("✓ Login bem-sucedido, token gerado: %s...\n", pair.AccessToken[:20])
 	}
-	return defaultValue
 }
 func comparePassword(hashedPassword, password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
@@ -0,0 +1,32 @@
+package auth
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+)
+// HMACSigner keeps supporting deployments that still share JWT_SECRET with
+// their consumers. It publishes no JWKS since the key is symmetric.
+type HMACSigner struct {
+	secret []byte
+}
+func NewHMACSigner(secret string) (*HMACSigner, error) {
+	if secret == "" {
+		return nil, errors.New("HMAC secret must not be empty")
+	}
+	return &HMACSigner{secret: []byte(secret)}, nil
+}
+func (s *HMACSigner) Sign(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+func (s *HMACSigner) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+	return s.secret, nil
+}
+func (s *HMACSigner) Methods() []string {
+	return []string{jwt.SigningMethodHS256.Alg()}
+}
+func (s *HMACSigner) JWKS() JWKS {
+	return JWKS{Keys: []JWK{}}
+}
@@ -0,0 +1,205 @@
+package auth
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"github.com/golang-jwt/jwt/v5"
+)
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+	minRSAKeyBits  = 2048
+)
+var (
+	ErrNoSigningKey = errors.New("keyset has no private key to sign with")
+	ErrUnknownKey   = errors.New("token signed with an unknown key")
+)
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+// KeySet signs tokens with a single active private key and verifies tokens
+// signed by any key it holds, so rotated-out keys (kept as public-only PEM
+// files) keep validating until they are removed.
+type KeySet struct {
+	keys   map[string]*Key
+	active *Key
+}
+func NewKeySet(activeKeyID string, keys ...*Key) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		if _, exists := set.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+	if activeKeyID == "" {
+		activeKeyID = newestPrivateKeyID(keys)
+	}
+	active, ok := set.keys[activeKeyID]
+	if !ok || active.PrivateKey == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNoSigningKey, activeKeyID)
+	}
+	set.active = active
+	return set, nil
+}
+// LoadKeySet reads every *.pem file in dir; the file name (without
+// extension) is used as the key id.
+func LoadKeySet(dir, activeKeyID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no PEM keys found in %s", dir)
+	}
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+		}
+		keyID := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, err := ParsePEMKey(keyID, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	return NewKeySet(activeKeyID, keys...)
+}
+func NewEphemeralKeySet() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	key := &Key{ID: "ephemeral", Algorithm: AlgorithmEdDSA, PrivateKey: private, PublicKey: public}
+	return NewKeySet(key.ID, key)
+}
+func ParsePEMKey(keyID string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key := &Key{ID: keyID}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.PrivateKey, key.PublicKey = AlgorithmRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Algorithm, key.PublicKey = AlgorithmRS256, k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.PrivateKey, key.PublicKey = AlgorithmEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Algorithm, key.PublicKey = AlgorithmEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", parsed)
+	}
+	if rsaKey, ok := key.PublicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+	}
+	return key, nil
+}
+func (s *KeySet) ActiveKeyID() string {
+	return s.active.ID
+}
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(signingMethod(s.active.Algorithm), claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.PrivateKey)
+}
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %q for key %q", token.Method.Alg(), keyID)
+	}
+	return key.PublicKey, nil
+}
+func (s *KeySet) Methods() []string {
+	return []string{AlgorithmRS256, AlgorithmEdDSA}
+}
+func (s *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	set := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		key := s.keys[id]
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+func newestPrivateKeyID(keys []*Key) string {
+	newest := ""
+	for _, key := range keys {
+		if key.PrivateKey != nil && key.ID > newest {
+			newest = key.ID
+		}
+	}
+	return newest
+}
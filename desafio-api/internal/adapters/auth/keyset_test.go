@@ -0,0 +1,127 @@
+package auth_test
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func writeRSAKey(t *testing.T, dir, name string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	writePEM(t, dir, name, "PRIVATE KEY", der)
+	return key
+}
+func writeEd25519Key(t *testing.T, dir, name string) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	writePEM(t, dir, name, "PRIVATE KEY", der)
+	return key
+}
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"), data, 0600))
+}
+func testClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+func parse(t *testing.T, set *auth.KeySet, token string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, set.Keyfunc, jwt.WithValidMethods(set.Methods()))
+}
+func TestKeySet_SignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "2024-01")
+	writeEd25519Key(t, dir, "2024-06")
+	set, err := auth.LoadKeySet(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06", set.ActiveKeyID())
+	token, err := set.Sign(testClaims())
+	require.NoError(t, err)
+	parsed, err := parse(t, set, token)
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+	assert.Equal(t, "2024-06", parsed.Header["kid"])
+	assert.Equal(t, auth.AlgorithmEdDSA, parsed.Method.Alg())
+	set, err = auth.LoadKeySet(dir, "2024-01")
+	require.NoError(t, err)
+	token, err = set.Sign(testClaims())
+	require.NoError(t, err)
+	parsed, err = parse(t, set, token)
+	require.NoError(t, err)
+	assert.Equal(t, auth.AlgorithmRS256, parsed.Method.Alg())
+}
+func TestKeySet_RotatedKeyStillVerifies(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := writeRSAKey(t, dir, "2024-01")
+	oldSet, err := auth.LoadKeySet(dir, "")
+	require.NoError(t, err)
+	token, err := oldSet.Sign(testClaims())
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, "2024-01.pem")))
+	der, err := x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	require.NoError(t, err)
+	writePEM(t, dir, "2024-01", "PUBLIC KEY", der)
+	writeEd25519Key(t, dir, "2024-06")
+	newSet, err := auth.LoadKeySet(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06", newSet.ActiveKeyID())
+	parsed, err := parse(t, newSet, token)
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+	_, err = auth.LoadKeySet(dir, "2024-01")
+	assert.ErrorIs(t, err, auth.ErrNoSigningKey)
+}
+func TestKeySet_RejectsUnknownKey(t *testing.T) {
+	first, err := auth.NewEphemeralKeySet()
+	require.NoError(t, err)
+	second, err := auth.NewEphemeralKeySet()
+	require.NoError(t, err)
+	token, err := first.Sign(testClaims())
+	require.NoError(t, err)
+	_, err = parse(t, second, token)
+	assert.Error(t, err)
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = parse(t, first, hmacToken)
+	assert.Error(t, err)
+}
+func TestKeySet_RejectsWeakRSAKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	_, err = auth.ParsePEMKey("weak", data)
+	assert.Error(t, err)
+}
+func TestKeySet_JWKS(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "2024-01")
+	writeEd25519Key(t, dir, "2024-06")
+	set, err := auth.LoadKeySet(dir, "")
+	require.NoError(t, err)
+	jwks := set.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "2024-01", jwks.Keys[0].KeyID)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, auth.AlgorithmRS256, jwks.Keys[0].Algorithm)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+	assert.Equal(t, "2024-06", jwks.Keys[1].KeyID)
+	assert.Equal(t, "OKP", jwks.Keys[1].KeyType)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Curve)
+	assert.NotEmpty(t, jwks.Keys[1].X)
+}
@@ -5,8 +5,14 @@ import (
     "time"
     _ "github.com/go-sql-driver/mysql"
     "github.com/jmoiron/sqlx"
+    _ "github.com/lib/pq"
+)
+const (
+    DriverMySQL    = "mysql"
+    DriverPostgres = "postgres"
 )
 type Config struct {
+    Driver       string
     Host         string
     Port         string
     User         string
@@ -18,11 +24,15 @@ type Config struct {
     MaxIdleTime  time.Duration
 }
 func NewDB(cfg Config) (*sqlx.DB, error) {
-    dsn := fmt.Sprintf(
-        "%s:%s@tcp(%s:%s)/%s?parseTime=true",
-        cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName,
-    )
-    db, err := sqlx.Connect("mysql", dsn)
+    driver := cfg.Driver
+    if driver == "" {
+        driver = DriverMySQL
+    }
+    dsn, err := buildDSN(driver, cfg)
+    if err != nil {
+        return nil, err
+    }
+    db, err := sqlx.Connect(driver, dsn)
     if err != nil {
         return nil, fmt.Errorf("failed to connect to database: %w", err)
     }
@@ -36,7 +46,39 @@ func NewDB(cfg Config) (*sqlx.DB, error) {
     }
     return db, nil
 }
-func WithTransaction(db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+func buildDSN(driver string, cfg Config) (string, error) {
+    switch driver {
+    case DriverMySQL:
+        // clientFoundRows makes RowsAffected count matched rows, as on
+        // PostgreSQL, rather than only the rows whose values changed.
+        return fmt.Sprintf(
+            "%s:%s@tcp(%s:%s)/%s?parseTime=true&clientFoundRows=true",
+            cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName,
+        ), nil
+    case DriverPostgres:
+        sslMode := cfg.SSLMode
+        if sslMode == "" {
+            sslMode = "disable"
+        }
+        return fmt.Sprintf(
+            "host=%s port=%s user=%s password='%s' dbname=%s sslmode=%s",
+            cfg.Host, cfg.Port, cfg.User, escapeDSNValue(cfg.Password), cfg.DBName, sslMode,
+        ), nil
+    default:
+        return "", fmt.Errorf("unsupported database driver: %q", driver)
+    }
+}
+func escapeDSNValue(value string) string {
+    escaped := make([]rune, 0, len(value))
+    for _, r := range value {
+        if r == '\'' || r == '\\' {
+            escaped = append(escaped, '\\')
+        }
+        escaped = append(escaped, r)
+    }
+    return string(escaped)
+}
+func WithTransaction(db *sqlx.DB, fn func(tx *sqlx.Tx) error) (err error) {
     tx, err := db.Beginx()
     if err != nil {
         return err
@@ -0,0 +1,384 @@
+package migrate
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"github.com/jmoiron/sqlx"
+)
+const lockName = "desafio_api_schema_migrations"
+var (
+	ErrChecksumMismatch = errors.New("applied migration was modified after being applied")
+	ErrLockTimeout      = errors.New("timed out waiting for the migration lock")
+	ErrUnknownMigration = errors.New("applied migration not found in source")
+	ErrNothingToRevert  = errors.New("no applied migrations to revert")
+	ErrUnknownVersion   = errors.New("version not found in source")
+)
+var createTableStatements = map[string]string{
+	database.DriverMySQL: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+	database.DriverPostgres: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`,
+}
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Modified  bool       `json:"modified"`
+	Missing   bool       `json:"missing"`
+}
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+type Migrator struct {
+	db          *sqlx.DB
+	dialect     string
+	migrations  []Migration
+	LockTimeout time.Duration
+	logger      *slog.Logger
+}
+func New(db *sqlx.DB, source fs.FS, logger *slog.Logger) (*Migrator, error) {
+	dialect := db.DriverName()
+	if _, ok := createTableStatements[dialect]; !ok {
+		return nil, fmt.Errorf("migrations not supported for driver %q", dialect)
+	}
+	migrations, err := Load(source, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:          db,
+		dialect:     dialect,
+		migrations:  migrations,
+		LockTimeout: time.Minute,
+		logger:      logger,
+	}, nil
+}
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+func (m *Migrator) LatestVersion() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+// CurrentVersion returns the newest applied version without taking the
+// migration lock, or 0 when nothing was applied yet.
+func (m *Migrator) CurrentVersion(ctx context.Context) (int64, error) {
+	var version sql.NullInt64
+	if err := m.db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	return version.Int64, nil
+}
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(current); err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, done := current[migration.Version]; done {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+// Baseline records every migration up to version as applied without running
+// it, for databases whose schema was created before migrations were tracked.
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]Migration, error) {
+	if !m.known(version) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	var recorded []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, done := current[migration.Version]; done {
+				continue
+			}
+			query := m.rebind("INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)")
+			if _, err := conn.ExecContext(ctx, query, migration.Version, migration.Name, migration.Checksum); err != nil {
+				return fmt.Errorf("migration %d_%s: failed to update schema_migrations: %w", migration.Version, migration.Name, err)
+			}
+			recorded = append(recorded, migration)
+		}
+		return nil
+	})
+	return recorded, err
+}
+func (m *Migrator) known(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		targets, err := m.lastApplied(ctx, conn, steps)
+		if err != nil {
+			return err
+		}
+		for _, migration := range targets {
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		targets, err := m.lastApplied(ctx, conn, 1)
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			return ErrNothingToRevert
+		}
+		migration := targets[0]
+		if err := m.revert(ctx, conn, migration); err != nil {
+			return err
+		}
+		if err := m.apply(ctx, conn, migration); err != nil {
+			return err
+		}
+		redone = &migration
+		return nil
+	})
+	return redone, err
+}
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	current, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := current[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = record.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range current {
+		if known[version] {
+			continue
+		}
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   version,
+			Name:      record.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+	return statuses, nil
+}
+func (m *Migrator) verify(current map[int64]appliedMigration) error {
+	for _, migration := range m.migrations {
+		record, ok := current[migration.Version]
+		if ok && record.Checksum != migration.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+func (m *Migrator) lastApplied(ctx context.Context, conn *sql.Conn, steps int) ([]Migration, error) {
+	current, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(current); err != nil {
+		return nil, err
+	}
+	var newest appliedMigration
+	for _, record := range current {
+		if record.Version > newest.Version {
+			newest = record
+		}
+	}
+	if newest.Version > m.LatestVersion() {
+		return nil, fmt.Errorf("%w: %d_%s", ErrUnknownMigration, newest.Version, newest.Name)
+	}
+	var targets []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(targets) < steps; i-- {
+		if _, ok := current[m.migrations[i].Version]; ok {
+			targets = append(targets, m.migrations[i])
+		}
+	}
+	return targets, nil
+}
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	if _, err := conn.ExecContext(ctx, createTableStatements[m.dialect]); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	current := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var record appliedMigration
+		if err := rows.Scan(&record.Version, &record.Name, &record.Checksum, &record.AppliedAt); err != nil {
+			return nil, err
+		}
+		current[record.Version] = record
+	}
+	return current, rows.Err()
+}
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	m.logger.InfoContext(ctx, "applying migration", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+	return m.execute(ctx, conn, migration, migration.UpSQL,
+		"INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)",
+		migration.Version, migration.Name, migration.Checksum)
+}
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	m.logger.InfoContext(ctx, "reverting migration", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+	return m.execute(ctx, conn, migration, migration.DownSQL,
+		"DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+}
+func (m *Migrator) execute(ctx context.Context, conn *sql.Conn, migration Migration, script, bookkeeping string, args ...interface{}) error {
+	statements, err := SplitStatements(script)
+	if err != nil {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for i, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d_%s failed at statement %d: %w", migration.Version, migration.Name, i+1, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, m.rebind(bookkeeping), args...); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %d_%s: failed to update schema_migrations: %w", migration.Version, migration.Name, err)
+	}
+	return tx.Commit()
+}
+// withLock serializes migrations across replicas with a database advisory
+// lock held on a dedicated connection for the whole run.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := m.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer func() {
+		if err := m.unlock(conn); err != nil {
+			m.logger.WarnContext(ctx, "releasing migration lock failed", slog.Any("error", err))
+		}
+	}()
+	return fn(conn)
+}
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	switch m.dialect {
+	case database.DriverMySQL:
+		var acquired sql.NullInt64
+		seconds := int(m.LockTimeout.Seconds())
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, seconds).Scan(&acquired); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if !acquired.Valid || acquired.Int64 != 1 {
+			return ErrLockTimeout
+		}
+		return nil
+	default:
+		deadline := time.Now().Add(m.LockTimeout)
+		for {
+			var acquired bool
+			if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey()).Scan(&acquired); err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			if acquired {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return ErrLockTimeout
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+	}
+}
+func (m *Migrator) unlock(conn *sql.Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if m.dialect == database.DriverMySQL {
+		_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey())
+	return err
+}
+func (m *Migrator) rebind(query string) string {
+	return sqlx.Rebind(sqlx.BindType(m.dialect), query)
+}
+func lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(lockName))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,16 @@
+package migrate
+import (
+	"context"
+	"testing"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/migrations"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func TestBaseline_RejectsUnknownVersion(t *testing.T) {
+	migrator, err := New(sqlx.NewDb(nil, "mysql"), migrations.FS, logging.Nop())
+	require.NoError(t, err)
+	_, err = migrator.Baseline(context.Background(), migrator.LatestVersion()+1)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+}
@@ -0,0 +1,130 @@
+package migrate
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+const (
+	statementBegin = "-- +migrate StatementBegin"
+	statementEnd   = "-- +migrate StatementEnd"
+)
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+type Migration struct {
+	Version  int64
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string
+}
+func Load(source fs.FS, dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations for %s: %w", dialect, err)
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		content, err := fs.ReadFile(source, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s and %s", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.UpSQL = string(content)
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.UpSQL) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		if strings.TrimSpace(migration.DownSQL) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+		sum := sha256.Sum256([]byte(migration.UpSQL))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+// SplitStatements breaks a migration file into statements terminated by a
+// trailing ";". Bodies that contain semicolons themselves (triggers,
+// functions) must be wrapped in StatementBegin/StatementEnd markers.
+func SplitStatements(script string) ([]string, error) {
+	var statements []string
+	var current strings.Builder
+	inBlock := false
+	flush := func() {
+		statement := strings.TrimSpace(current.String())
+		statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == statementBegin:
+			if inBlock {
+				return nil, fmt.Errorf("nested %q", statementBegin)
+			}
+			flush()
+			inBlock = true
+			continue
+		case trimmed == statementEnd:
+			if !inBlock {
+				return nil, fmt.Errorf("%q without %q", statementEnd, statementBegin)
+			}
+			flush()
+			inBlock = false
+			continue
+		case !inBlock && current.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")):
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if inBlock {
+		return nil, fmt.Errorf("%q without %q", statementBegin, statementEnd)
+	}
+	flush()
+	return statements, nil
+}
@@ -0,0 +1,71 @@
+package migrate
+import (
+	"testing"
+	"testing/fstest"
+	"desafio-api/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func TestSplitStatements(t *testing.T) {
+	script := `-- comentário inicial
+CREATE TABLE a (id INT);
+
+-- +migrate StatementBegin
+CREATE TRIGGER t BEFORE UPDATE ON a
+FOR EACH ROW
+BEGIN
+    SET NEW.id = 1;
+END;
+-- +migrate StatementEnd
+DROP TABLE b;
+`
+	statements, err := SplitStatements(script)
+	require.NoError(t, err)
+	require.Len(t, statements, 3)
+	assert.Equal(t, "CREATE TABLE a (id INT)", statements[0])
+	assert.Contains(t, statements[1], "SET NEW.id = 1;")
+	assert.Equal(t, "DROP TABLE b", statements[2])
+}
+func TestSplitStatements_UnterminatedBlock(t *testing.T) {
+	_, err := SplitStatements("-- +migrate StatementBegin\nSELECT 1;\n")
+	assert.Error(t, err)
+}
+func TestLoad(t *testing.T) {
+	source := fstest.MapFS{
+		"mysql/0002_second.up.sql":   {Data: []byte("SELECT 2;")},
+		"mysql/0002_second.down.sql": {Data: []byte("SELECT -2;")},
+		"mysql/0001_first.up.sql":    {Data: []byte("SELECT 1;")},
+		"mysql/0001_first.down.sql":  {Data: []byte("SELECT -1;")},
+	}
+	loaded, err := Load(source, "mysql")
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, int64(1), loaded[0].Version)
+	assert.Equal(t, "first", loaded[0].Name)
+	assert.Equal(t, int64(2), loaded[1].Version)
+	assert.Len(t, loaded[0].Checksum, 64)
+	assert.NotEqual(t, loaded[0].Checksum, loaded[1].Checksum)
+}
+func TestLoad_MissingDownFile(t *testing.T) {
+	source := fstest.MapFS{
+		"mysql/0001_first.up.sql": {Data: []byte("SELECT 1;")},
+	}
+	_, err := Load(source, "mysql")
+	assert.Error(t, err)
+}
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	versions := make(map[string][]int64)
+	for _, dialect := range []string{"mysql", "postgres"} {
+		loaded, err := Load(migrations.FS, dialect)
+		require.NoError(t, err, dialect)
+		require.NotEmpty(t, loaded, dialect)
+		for _, migration := range loaded {
+			_, err := SplitStatements(migration.UpSQL)
+			assert.NoError(t, err, "%s %d up", dialect, migration.Version)
+			_, err = SplitStatements(migration.DownSQL)
+			assert.NoError(t, err, "%s %d down", dialect, migration.Version)
+			versions[dialect] = append(versions[dialect], migration.Version)
+		}
+	}
+	assert.Equal(t, versions["mysql"], versions["postgres"], "every migration must exist for both dialects")
+}
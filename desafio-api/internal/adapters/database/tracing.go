@@ -0,0 +1,79 @@
+package database
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "strings"
+    "github.com/jmoiron/sqlx"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+)
+const tracerName = "desafio-api/database"
+// tracedQuerier records a client span for every statement. Only the SQL
+// text is attached, never the arguments.
+type tracedQuerier struct {
+    Querier
+}
+func (q tracedQuerier) start(ctx context.Context, query string) (context.Context, trace.Span) {
+    operation := "SQL"
+    if fields := strings.Fields(query); len(fields) > 0 {
+        operation = strings.ToUpper(fields[0])
+    }
+    system := q.DriverName()
+    if system == DriverPostgres {
+        system = "postgresql"
+    }
+    return otel.Tracer(tracerName).Start(ctx, operation,
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(
+            attribute.String("db.system", system),
+            attribute.String("db.operation", operation),
+            attribute.String("db.statement", strings.Join(strings.Fields(query), " ")),
+        ),
+    )
+}
+func endSpan(span trace.Span, err error) {
+    if err != nil && !errors.Is(err, sql.ErrNoRows) {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+    }
+    span.End()
+}
+func (q tracedQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+    ctx, span := q.start(ctx, query)
+    rows, err := q.Querier.QueryContext(ctx, query, args...)
+    endSpan(span, err)
+    return rows, err
+}
+func (q tracedQuerier) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+    ctx, span := q.start(ctx, query)
+    rows, err := q.Querier.QueryxContext(ctx, query, args...)
+    endSpan(span, err)
+    return rows, err
+}
+func (q tracedQuerier) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+    ctx, span := q.start(ctx, query)
+    row := q.Querier.QueryRowxContext(ctx, query, args...)
+    endSpan(span, row.Err())
+    return row
+}
+func (q tracedQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+    ctx, span := q.start(ctx, query)
+    result, err := q.Querier.ExecContext(ctx, query, args...)
+    endSpan(span, err)
+    return result, err
+}
+func (q tracedQuerier) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+    ctx, span := q.start(ctx, query)
+    err := q.Querier.GetContext(ctx, dest, query, args...)
+    endSpan(span, err)
+    return err
+}
+func (q tracedQuerier) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+    ctx, span := q.start(ctx, query)
+    err := q.Querier.SelectContext(ctx, dest, query, args...)
+    endSpan(span, err)
+    return err
+}
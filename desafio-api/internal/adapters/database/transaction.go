@@ -0,0 +1,72 @@
+package database
+import (
+    "context"
+    "fmt"
+    "github.com/jmoiron/sqlx"
+)
+// Querier is implemented by both *sqlx.DB and *sqlx.Tx so repositories can
+// run the same statements inside or outside a transaction.
+type Querier interface {
+    sqlx.ExtContext
+    GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+    SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+type txKey struct{}
+type savepointKey struct{}
+type TxManager struct {
+    db *sqlx.DB
+}
+func NewTxManager(db *sqlx.DB) *TxManager {
+    return &TxManager{db: db}
+}
+// WithinTx runs fn in a transaction carried by the context. Repositories
+// resolve it through Conn; nested calls join the outer transaction under a
+// savepoint, so a failed nested call is undone without aborting the outer
+// one, which Postgres would otherwise refuse to use again.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+    if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+        return withinSavepoint(ctx, tx, fn)
+    }
+    tx, err := m.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer func() {
+        if p := recover(); p != nil {
+            tx.Rollback()
+            panic(p)
+        } else if err != nil {
+            tx.Rollback()
+        } else {
+            err = tx.Commit()
+        }
+    }()
+    return fn(context.WithValue(ctx, txKey{}, tx))
+}
+func withinSavepoint(ctx context.Context, tx *sqlx.Tx, fn func(ctx context.Context) error) (err error) {
+    depth, _ := ctx.Value(savepointKey{}).(int)
+    name := fmt.Sprintf("sp_%d", depth+1)
+    if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+        return err
+    }
+    defer func() {
+        if p := recover(); p != nil {
+            panic(p)
+        } else if err != nil {
+            // The outer transaction is rolled back anyway if this fails.
+            tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+            tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+        } else {
+            _, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+        }
+    }()
+    return fn(context.WithValue(ctx, savepointKey{}, depth+1))
+}
+// Conn returns the transaction carried by ctx, or db outside one, wrapped so
+// every statement gets its own trace span.
+func Conn(ctx context.Context, db *sqlx.DB) Querier {
+    if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+        return tracedQuerier{tx}
+    }
+    return tracedQuerier{db}
+}
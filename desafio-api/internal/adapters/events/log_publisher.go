@@ -0,0 +1,24 @@
+package events
+import (
+	"context"
+	"log/slog"
+	"desafio-api/internal/domain"
+)
+// LogPublisher writes events to the application log. It is the default when
+// no broker is configured.
+type LogPublisher struct {
+	logger *slog.Logger
+}
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+func (p *LogPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	p.logger.InfoContext(ctx, "domain event",
+		slog.Int64("event_id", event.ID),
+		slog.String("event_type", event.Type),
+		slog.String("aggregate_type", event.AggregateType),
+		slog.Int64("aggregate_id", event.AggregateID),
+		slog.String("payload", string(event.Payload)),
+	)
+	return nil
+}
@@ -0,0 +1,36 @@
+package events
+import (
+	"context"
+	"sync"
+	"desafio-api/internal/domain"
+)
+// MemoryPublisher keeps published events in memory, for tests.
+type MemoryPublisher struct {
+	events []*domain.OutboxEvent
+	err    error
+	mu     sync.Mutex
+}
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+func (p *MemoryPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	published := *event
+	p.events = append(p.events, &published)
+	return nil
+}
+// FailWith makes every following Publish return err; nil restores delivery.
+func (p *MemoryPublisher) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+func (p *MemoryPublisher) Events() []*domain.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*domain.OutboxEvent(nil), p.events...)
+}
@@ -0,0 +1,22 @@
+package events
+import (
+	"context"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+)
+// MultiPublisher hands every event to several publishers. If one fails the
+// whole event is retried, so the others may see it more than once.
+type MultiPublisher struct {
+	publishers []service.EventPublisher
+}
+func NewMultiPublisher(publishers ...service.EventPublisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+func (p *MultiPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
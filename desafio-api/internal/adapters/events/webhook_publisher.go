@@ -0,0 +1,44 @@
+package events
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"desafio-api/internal/domain"
+)
+// WebhookPublisher POSTs each event envelope to a fixed URL. Any response
+// other than 2xx is a failure and the relay retries the event later.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{url: url, client: &http.Client{Timeout: timeout}}
+}
+func (p *WebhookPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	body, err := json.Marshal(event.Envelope())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Event-Type", event.Type)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
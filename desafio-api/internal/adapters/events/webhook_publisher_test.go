@@ -0,0 +1,46 @@
+package events
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"desafio-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func TestWebhookPublisher_PostsEnvelope(t *testing.T) {
+	var received domain.EventEnvelope
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	event := &domain.OutboxEvent{
+		ID:            42,
+		AggregateType: domain.AggregateItem,
+		AggregateID:   7,
+		Type:          domain.EventItemDeleted,
+		Payload:       []byte(`{"item_id":7}`),
+		OccurredAt:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, NewWebhookPublisher(server.URL, time.Second).Publish(context.Background(), event))
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	assert.Equal(t, "42", headers.Get("X-Event-ID"))
+	assert.Equal(t, domain.EventItemDeleted, headers.Get("X-Event-Type"))
+	assert.Equal(t, int64(42), received.ID)
+	assert.Equal(t, int64(7), received.AggregateID)
+	assert.JSONEq(t, `{"item_id":7}`, string(received.Data))
+	assert.True(t, event.OccurredAt.Equal(received.OccurredAt))
+}
+func TestWebhookPublisher_FailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	err := NewWebhookPublisher(server.URL, time.Second).Publish(context.Background(), &domain.OutboxEvent{ID: 1, Payload: []byte(`{}`)})
+	assert.EqualError(t, err, "webhook responded with status 503")
+}
@@ -0,0 +1,57 @@
+package events
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"desafio-api/internal/domain"
+)
+const SignatureHeader = "X-Signature"
+// WebhookSender delivers webhook subscriptions. Each body is signed with
+// the subscription secret so receivers can check it came from us.
+type WebhookSender struct {
+	client *http.Client
+}
+func NewWebhookSender(timeout time.Duration) *WebhookSender {
+	return &WebhookSender{client: &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+// Sign returns the X-Signature value for body: "sha256=" followed by the
+// hex HMAC-SHA256 of the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+func (s *WebhookSender) Send(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "desafio-api-webhooks")
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, delivery.Payload))
+	req.Header.Set("X-Event-ID", strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set("X-Event-Type", delivery.EventType)
+	req.Header.Set("X-Delivery-ID", strconv.FormatInt(delivery.ID, 10))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,85 @@
+package health
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	repoPort "desafio-api/internal/ports/repository"
+)
+// Database pings the connection pool and reports its usage.
+func Database(db *sql.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) (Detail, error) {
+		err := db.PingContext(ctx)
+		stats := db.Stats()
+		return Detail{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		}, err
+	})
+}
+// Unavailable always fails with err, e.g. for the database when the API fell
+// back to the in-memory repositories.
+func Unavailable(err error) Checker {
+	return CheckerFunc(func(ctx context.Context) (Detail, error) {
+		return nil, err
+	})
+}
+type SchemaVersioner interface {
+	CurrentVersion(ctx context.Context) (int64, error)
+	LatestVersion() int64
+}
+// Migrations fails while the database schema is behind the migrations built
+// into the binary. A newer schema is accepted so the previous release keeps
+// serving during a rolling deploy.
+func Migrations(schema SchemaVersioner) Checker {
+	return CheckerFunc(func(ctx context.Context) (Detail, error) {
+		current, err := schema.CurrentVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		expected := schema.LatestVersion()
+		detail := Detail{"current": current, "expected": expected}
+		if current < expected {
+			return detail, fmt.Errorf("%d pending migrations", expected-current)
+		}
+		return detail, nil
+	})
+}
+// OutboxLag fails when the oldest unpublished event is older than maxLag.
+func OutboxLag(outbox repoPort.OutboxRepository, maxLag time.Duration) Checker {
+	return CheckerFunc(func(ctx context.Context) (Detail, error) {
+		pending, err := outbox.CountPending(ctx)
+		if err != nil {
+			return nil, err
+		}
+		oldest, err := outbox.OldestPending(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var lag time.Duration
+		if oldest != nil {
+			lag = time.Since(*oldest)
+		}
+		detail := Detail{"pending": pending, "lag_seconds": int64(lag.Seconds())}
+		if lag > maxLag {
+			return detail, fmt.Errorf("oldest pending event is %s old (max %s)", lag.Round(time.Second), maxLag)
+		}
+		return detail, nil
+	})
+}
+// Disk fails when the filesystem holding path has less than minFree bytes
+// available.
+func Disk(path string, minFree uint64) Checker {
+	return CheckerFunc(func(ctx context.Context) (Detail, error) {
+		free, total, err := diskSpace(path)
+		if err != nil {
+			return nil, err
+		}
+		detail := Detail{"path": path, "free_bytes": free, "total_bytes": total}
+		if free < minFree {
+			return detail, fmt.Errorf("only %d MB free (min %d MB)", free>>20, minFree>>20)
+		}
+		return detail, nil
+	})
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+package health
+import (
+	"errors"
+	"runtime"
+)
+func diskSpace(path string) (free, total uint64, err error) {
+	return 0, 0, errors.New("disk space check not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,159 @@
+package health
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+// Detail is extra information a check reports next to its status, e.g. the
+// applied schema version or the free disk space.
+type Detail map[string]interface{}
+type Checker interface {
+	Check(ctx context.Context) (Detail, error)
+}
+type CheckerFunc func(ctx context.Context) (Detail, error)
+func (f CheckerFunc) Check(ctx context.Context) (Detail, error) {
+	return f(ctx)
+}
+type Result struct {
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Detail     Detail    `json:"detail,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+type Report struct {
+	Status       string            `json:"status"`
+	ShuttingDown bool              `json:"shutting_down,omitempty"`
+	Checks       map[string]Result `json:"checks"`
+}
+func (r Report) Healthy() bool {
+	return r.Status != StatusFail
+}
+type registered struct {
+	name     string
+	checker  Checker
+	critical bool
+}
+// Registry runs a set of named checks and caches the combined report for
+// CacheTTL, so frequent probes do not hit the database on every request.
+type Registry struct {
+	// Timeout bounds each check; a check still running is reported as failed.
+	Timeout  time.Duration
+	CacheTTL time.Duration
+	mu           sync.Mutex
+	checks       []registered
+	cached       *Report
+	cachedAt     time.Time
+	shuttingDown atomic.Bool
+	now          func() time.Time
+}
+func NewRegistry() *Registry {
+	return &Registry{
+		Timeout:  2 * time.Second,
+		CacheTTL: 2 * time.Second,
+		now:      time.Now,
+	}
+}
+// Register adds a check whose failure fails the whole report.
+func (r *Registry) Register(name string, checker Checker) {
+	r.add(registered{name: name, checker: checker, critical: true})
+}
+// RegisterInformational adds a check whose failure is reported as "warn"
+// without failing the report.
+func (r *Registry) RegisterInformational(name string, checker Checker) {
+	r.add(registered{name: name, checker: checker})
+}
+func (r *Registry) add(check registered) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check)
+	sort.Slice(r.checks, func(i, j int) bool { return r.checks[i].name < r.checks[j].name })
+	r.cached = nil
+}
+// Shutdown makes every later report fail without running the checks, so a
+// load balancer stops routing new requests while the server drains.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+// Run returns the cached report or runs the checks. They run detached from
+// ctx's cancellation, bounded by Timeout alone, since their report is shared
+// with other callers: a probe that disconnects must not cache a failure.
+func (r *Registry) Run(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{Status: StatusFail, ShuttingDown: true, Checks: map[string]Result{}}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cached != nil && r.now().Sub(r.cachedAt) < r.CacheTTL {
+		return *r.cached
+	}
+	results := make([]Result, len(r.checks))
+	var wg sync.WaitGroup
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func(i int, check registered) {
+			defer wg.Done()
+			results[i] = r.run(context.WithoutCancel(ctx), check)
+		}(i, check)
+	}
+	wg.Wait()
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(r.checks))}
+	for i, check := range r.checks {
+		report.Checks[check.name] = results[i]
+		switch results[i].Status {
+		case StatusFail:
+			report.Status = StatusFail
+		case StatusWarn:
+			if report.Status == StatusOK {
+				report.Status = StatusWarn
+			}
+		}
+	}
+	r.cached = &report
+	r.cachedAt = r.now()
+	return report
+}
+func (r *Registry) run(ctx context.Context, check registered) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	started := r.now()
+	type outcome struct {
+		detail Detail
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		detail, err := check.checker.Check(ctx)
+		done <- outcome{detail, err}
+	}()
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = ctx.Err()
+	}
+	status := StatusOK
+	if result.err != nil {
+		status = StatusWarn
+		if check.critical {
+			status = StatusFail
+		}
+	}
+	res := Result{
+		Status:     status,
+		Detail:     result.detail,
+		DurationMS: float64(r.now().Sub(started).Microseconds()) / 1000,
+		CheckedAt:  started.UTC(),
+	}
+	if result.err != nil {
+		res.Error = result.err.Error()
+	}
+	return res
+}
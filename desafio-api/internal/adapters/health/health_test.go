@@ -0,0 +1,116 @@
+package health
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func TestRegistry_CombinesChecks(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("ok", CheckerFunc(func(ctx context.Context) (Detail, error) {
+		return Detail{"answer": 42}, nil
+	}))
+	registry.RegisterInformational("lagging", Unavailable(errors.New("behind")))
+	report := registry.Run(context.Background())
+	assert.Equal(t, StatusWarn, report.Status)
+	assert.True(t, report.Healthy())
+	assert.Equal(t, StatusOK, report.Checks["ok"].Status)
+	assert.Equal(t, Detail{"answer": 42}, report.Checks["ok"].Detail)
+	assert.Equal(t, StatusWarn, report.Checks["lagging"].Status)
+	assert.Equal(t, "behind", report.Checks["lagging"].Error)
+	registry.Register("database", Unavailable(errors.New("connection refused")))
+	report = registry.Run(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, StatusFail, report.Checks["database"].Status)
+}
+func TestRegistry_TimesOutSlowChecks(t *testing.T) {
+	registry := NewRegistry()
+	registry.Timeout = 10 * time.Millisecond
+	registry.Register("slow", CheckerFunc(func(ctx context.Context) (Detail, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}))
+	started := time.Now()
+	report := registry.Run(context.Background())
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+	assert.Equal(t, StatusFail, report.Checks["slow"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+func TestRegistry_CachesResults(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry := NewRegistry()
+	registry.now = func() time.Time { return now }
+	calls := 0
+	registry.Register("counter", CheckerFunc(func(ctx context.Context) (Detail, error) {
+		calls++
+		return nil, nil
+	}))
+	registry.Run(context.Background())
+	registry.Run(context.Background())
+	assert.Equal(t, 1, calls)
+	now = now.Add(registry.CacheTTL)
+	registry.Run(context.Background())
+	assert.Equal(t, 2, calls)
+}
+func TestRegistry_IgnoresCallerCancellation(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("database", CheckerFunc(func(ctx context.Context) (Detail, error) {
+		return nil, ctx.Err()
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := registry.Run(ctx)
+	assert.Equal(t, StatusOK, report.Status, "a disconnected probe must not fail the checks")
+	assert.Equal(t, StatusOK, registry.Run(context.Background()).Status)
+}
+func TestRegistry_FailsAfterShutdown(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("ok", CheckerFunc(func(ctx context.Context) (Detail, error) { return nil, nil }))
+	require.True(t, registry.Run(context.Background()).Healthy())
+	registry.Shutdown()
+	report := registry.Run(context.Background())
+	assert.False(t, report.Healthy())
+	assert.True(t, report.ShuttingDown)
+}
+type fakeSchema struct {
+	current, latest int64
+}
+func (f fakeSchema) CurrentVersion(ctx context.Context) (int64, error) {
+	return f.current, nil
+}
+func (f fakeSchema) LatestVersion() int64 {
+	return f.latest
+}
+func TestMigrations(t *testing.T) {
+	detail, err := Migrations(fakeSchema{current: 12, latest: 13}).Check(context.Background())
+	assert.EqualError(t, err, "1 pending migrations")
+	assert.Equal(t, Detail{"current": int64(12), "expected": int64(13)}, detail)
+	_, err = Migrations(fakeSchema{current: 13, latest: 13}).Check(context.Background())
+	assert.NoError(t, err)
+	_, err = Migrations(fakeSchema{current: 14, latest: 13}).Check(context.Background())
+	assert.NoError(t, err)
+}
+func TestOutboxLag(t *testing.T) {
+	ctx := context.Background()
+	outbox := repository.NewMockOutboxRepository()
+	check := OutboxLag(outbox, time.Minute)
+	detail, err := check.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, detail["pending"])
+	require.NoError(t, outbox.Save(ctx, &domain.OutboxEvent{AggregateType: "item", AggregateID: 1, Type: "ItemCreated", OccurredAt: time.Now().Add(-time.Hour)}))
+	detail, err = check.Check(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 1, detail["pending"])
+}
+func TestDisk(t *testing.T) {
+	detail, err := Disk(t.TempDir(), 0).Check(context.Background())
+	require.NoError(t, err)
+	assert.NotZero(t, detail["total_bytes"])
+	_, err = Disk(t.TempDir(), 1<<62).Check(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package http
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+type AdminHandler struct {
+	userService service.UserServiceInterface
+	logger      *slog.Logger
+}
+func NewAdminHandler(userService service.UserServiceInterface, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{userService: userService, logger: logger}
+}
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	users, err := h.userService.ListUsers(c.Request.Context())
+	if err != nil {
+		respondInternalError(c, h.logger, "Falha ao listar usuários", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": users})
+}
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de usuário inválido")
+		return
+	}
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
+		return
+	}
+	user, err := h.userService.AssignRole(c.Request.Context(), id, req.Role)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidRole:
+			RespondWithError(c, http.StatusBadRequest, "Perfil inválido. Use 'admin', 'editor' ou 'viewer'")
+		case domain.ErrCannotChangeOwnRole:
+			RespondWithError(c, http.StatusForbidden, "Não é permitido alterar o próprio perfil")
+		case domain.ErrUserNotFound:
+			RespondWithError(c, http.StatusNotFound, "Usuário não encontrado")
+		default:
+			respondInternalError(c, h.logger, "Falha ao atribuir perfil", err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,60 @@
+package http
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+func setupAdminTest() (*gin.Engine, *MockUserService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	handler := NewAdminHandler(mockService, logging.Nop())
+	router := gin.New()
+	router.GET("/admin/users", handler.ListUsers)
+	router.PUT("/admin/users/:id/role", handler.AssignRole)
+	return router, mockService
+}
+func TestAssignRole_Success(t *testing.T) {
+	router, mockService := setupAdminTest()
+	mockService.On("AssignRole", mock.Anything, 2, domain.RoleEditor).
+		Return(&domain.User{ID: 2, Username: "maria", Role: domain.RoleEditor}, nil)
+	jsonData, _ := json.Marshal(map[string]string{"role": domain.RoleEditor})
+	req, _ := http.NewRequest("PUT", "/admin/users/2/role", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, domain.RoleEditor, response["role"])
+	assert.NotContains(t, response, "password")
+	mockService.AssertExpectations(t)
+}
+func TestAssignRole_InvalidRole(t *testing.T) {
+	router, mockService := setupAdminTest()
+	mockService.On("AssignRole", mock.Anything, 2, "superuser").Return(nil, domain.ErrInvalidRole)
+	jsonData, _ := json.Marshal(map[string]string{"role": "superuser"})
+	req, _ := http.NewRequest("PUT", "/admin/users/2/role", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+func TestAssignRole_UserNotFound(t *testing.T) {
+	router, mockService := setupAdminTest()
+	mockService.On("AssignRole", mock.Anything, 99, domain.RoleViewer).Return(nil, domain.ErrUserNotFound)
+	jsonData, _ := json.Marshal(map[string]string{"role": domain.RoleViewer})
+	req, _ := http.NewRequest("PUT", "/admin/users/99/role", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -1,17 +1,22 @@
 package http
 import (
-	"log"
+	"log/slog"
 	"net/http"
-	"time"
+	"strings"
+	"desafio-api/internal/adapters/metrics"
+	"desafio-api/internal/adapters/ratelimit"
 	"desafio-api/internal/application/service"
 	"desafio-api/internal/domain"
 	"github.com/gin-gonic/gin"
 )
 type AuthHandler struct {
 	userService service.UserServiceInterface
+	lockout     *ratelimit.Lockout
+	metrics     *metrics.Metrics
+	logger      *slog.Logger
 }
-func NewAuthHandler(userService service.UserServiceInterface) *AuthHandler {
-	return &AuthHandler{userService: userService}
+func NewAuthHandler(userService service.UserServiceInterface, lockout *ratelimit.Lockout, metrics *metrics.Metrics, logger *slog.Logger) *AuthHandler {
+	return &AuthHandler{userService: userService, lockout: lockout, metrics: metrics, logger: logger}
 }
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -22,26 +27,26 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token            string `json:"token"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in"`
+}
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 func respondWithError(c *gin.Context, status int, message string) {
-	log.Printf("[DEBUG] Respondendo com erro HTTP %d: %s", status, message)
-	c.JSON(status, gin.H{
-		"error": message,
-		"status": status,
-		"timestamp": time.Now().Format(time.RFC3339),
-	})
+	RespondWithError(c, status, message)
 }
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("[ERROR] Register: Erro na validação de dados: %v", err)
 		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
 		return
 	}
-	log.Printf("[DEBUG] Register: Tentativa de registro para usuário: %s", req.Username)
 	if len(req.Password) < 6 {
-		log.Printf("[ERROR] Register: Senha muito curta para usuário %s", req.Username)
 		RespondWithError(c, http.StatusBadRequest, "Senha deve ter pelo menos 6 caracteres")
 		return
 	}
@@ -49,9 +54,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Username: req.Username,
 		Password: req.Password,
 	}
-	err := h.userService.Register(c.Request.Context(), user)
+	ctx := c.Request.Context()
+	err := h.userService.Register(ctx, user)
 	if err != nil {
-		log.Printf("[ERROR] Register: Erro ao registrar usuário %s: %v", req.Username, err)
 		switch err {
 		case domain.ErrDuplicateUsername:
 			RespondWithError(c, http.StatusConflict, "Usuário já existe")
@@ -60,28 +65,102 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		case domain.ErrPasswordTooShort:
 			RespondWithError(c, http.StatusBadRequest, "Senha deve ter pelo menos 6 caracteres")
 		default:
-			log.Printf("[ERROR] Register: Erro interno no registro: %v", err)
+			h.logger.ErrorContext(ctx, "register failed", slog.String("username", req.Username), slog.Any("error", err))
 			RespondWithError(c, http.StatusInternalServerError, "Erro interno ao registrar usuário")
 		}
 		return
 	}
-	log.Printf("[INFO] Register: Usuário %s registrado com sucesso (ID: %d)", user.Username, user.ID)
-	c.JSON(http.StatusCreated, gin.H{"id": user.ID, "username": user.Username})
+	h.logger.InfoContext(ctx, "user registered", slog.String("username", user.Username), slog.Int("new_user_id", user.ID))
+	c.JSON(http.StatusCreated, gin.H{"id": user.ID, "username": user.Username, "role": user.Role})
 }
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("[ERROR] Login: Erro na validação de dados: %v", err)
 		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
 		return
 	}
-	log.Printf("[DEBUG] Login: Tentativa de login para usuário: %s", req.Username)
-	token, err := h.userService.Login(c.Request.Context(), req.Username, req.Password)
+	ctx := c.Request.Context()
+	logger := h.logger.With(slog.String("username", req.Username), slog.String("client_ip", c.ClientIP()))
+	// The account bucket stops one account being guessed from many addresses
+	// and the address bucket one address guessing many accounts. Only the
+	// account bucket is cleared on success, or logging into an account of
+	// one's own would reset the address.
+	accountKey := "login:user:" + strings.ToLower(req.Username)
+	lockoutKeys := []string{accountKey, "login:ip:" + c.ClientIP()}
+	if lockedFor, err := h.lockout.Locked(ctx, lockoutKeys...); err != nil {
+		logger.ErrorContext(ctx, "login lockout check failed", slog.Any("error", err))
+	} else if lockedFor > 0 {
+		h.metrics.ObserveLogin(metrics.LoginLocked)
+		logger.WarnContext(ctx, "login rejected while locked out", slog.Duration("locked_for", lockedFor))
+		respondTooManyRequests(c, lockedFor, "Muitas tentativas de login inválidas. Tente novamente mais tarde")
+		return
+	}
+	pair, err := h.userService.Login(ctx, req.Username, req.Password)
 	if err != nil {
-		log.Printf("[ERROR] Login: Falha na autenticação para usuário %s: %v", req.Username, err)
-		RespondWithError(c, http.StatusUnauthorized, "Credenciais inválidas")
+		if err == domain.ErrInvalidCredentials {
+			h.metrics.ObserveLogin(metrics.LoginFailure)
+			if lockedFor, err := h.lockout.Fail(ctx, lockoutKeys...); err != nil {
+				logger.ErrorContext(ctx, "recording failed login failed", slog.Any("error", err))
+			} else if lockedFor > 0 {
+				logger.WarnContext(ctx, "login locked out after repeated failures", slog.Duration("locked_for", lockedFor))
+			} else {
+				logger.InfoContext(ctx, "login failed: invalid credentials")
+			}
+			RespondWithError(c, http.StatusUnauthorized, "Credenciais inválidas")
+		} else {
+			logger.ErrorContext(ctx, "login failed", slog.Any("error", err))
+			RespondWithError(c, http.StatusInternalServerError, "Erro interno ao autenticar usuário")
+		}
 		return
 	}
-	log.Printf("[INFO] Login: Usuário %s autenticado com sucesso", req.Username)
-	c.JSON(http.StatusOK, LoginResponse{Token: token})
+	if err := h.lockout.Reset(ctx, accountKey); err != nil {
+		logger.ErrorContext(ctx, "clearing login lockout failed", slog.Any("error", err))
+	}
+	h.metrics.ObserveLogin(metrics.LoginSuccess)
+	logger.InfoContext(ctx, "login succeeded")
+	c.JSON(http.StatusOK, toLoginResponse(pair))
+}
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
+		return
+	}
+	ctx := c.Request.Context()
+	pair, err := h.userService.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidRefreshToken, domain.ErrRefreshTokenReused:
+			h.logger.WarnContext(ctx, "refresh token rejected", slog.Any("error", err))
+			RespondWithError(c, http.StatusUnauthorized, "Refresh token inválido ou expirado")
+		default:
+			h.logger.ErrorContext(ctx, "token refresh failed", slog.Any("error", err))
+			RespondWithError(c, http.StatusInternalServerError, "Erro interno ao renovar token")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toLoginResponse(pair))
+}
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
+		return
+	}
+	if err := h.userService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "logout failed", slog.Any("error", err))
+		RespondWithError(c, http.StatusInternalServerError, "Erro interno ao encerrar sessão")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+func toLoginResponse(pair *domain.TokenPair) LoginResponse {
+	return LoginResponse{
+		Token:            pair.AccessToken,
+		AccessToken:      pair.AccessToken,
+		RefreshToken:     pair.RefreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        pair.ExpiresIn,
+		RefreshExpiresIn: pair.RefreshExpiresIn,
+	}
 }
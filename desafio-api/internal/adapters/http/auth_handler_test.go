@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"desafio-api/internal/application/service"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/metrics"
+	"desafio-api/internal/adapters/ratelimit"
 	"desafio-api/internal/domain"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 type MockUserService struct {
 	mock.Mock
@@ -20,9 +24,23 @@ func (m *MockUserService) Register(ctx context.Context, user *domain.User) error
 	args := m.Called(ctx, user)
 	return args.Error(0)
 }
-func (m *MockUserService) Login(ctx context.Context, username, password string) (string, error) {
+func (m *MockUserService) Login(ctx context.Context, username, password string) (*domain.TokenPair, error) {
 	args := m.Called(ctx, username, password)
-	return args.String(0), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+func (m *MockUserService) RefreshToken(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+func (m *MockUserService) Logout(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
+	return args.Error(0)
 }
 func (m *MockUserService) ValidateToken(tokenString string) (*domain.JWTClaims, error) {
 	args := m.Called(tokenString)
@@ -38,20 +56,40 @@ func (m *MockUserService) GetUserByID(ctx context.Context, id int) (*domain.User
 	}
 	return args.Get(0).(*domain.User), args.Error(1)
 }
-func (m *MockUserService) GetRepository() service.UserRepository {
-	args := m.Called()
+func (m *MockUserService) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+func (m *MockUserService) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
-		return nil
+		return nil, args.Error(1)
 	}
-	return args.Get(0).(service.UserRepository)
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+func (m *MockUserService) AssignRole(ctx context.Context, userID int, role string) (*domain.User, error) {
+	args := m.Called(ctx, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+func (m *MockUserService) GetRepository() interface{} {
+	args := m.Called()
+	return args.Get(0)
 }
 func setupTest() (*gin.Engine, *MockUserService) {
 	gin.SetMode(gin.TestMode)
 	mockService := new(MockUserService)
-	handler := NewAuthHandler(mockService)
+	handler := NewAuthHandler(mockService, ratelimit.NewLockout(ratelimit.NewMemoryStore(), ratelimit.DefaultLockoutPolicy), metrics.New(), logging.Nop())
 	router := gin.Default()
 	router.POST("/register", handler.Register)
 	router.POST("/login", handler.Login)
+	router.POST("/token/refresh", handler.Refresh)
+	router.POST("/logout", handler.Logout)
 	return router, mockService
 }
 func TestRegister_Success(t *testing.T) {
@@ -116,7 +154,11 @@ func TestRegister_ServerError(t *testing.T) {
 }
 func TestLogin_Success(t *testing.T) {
 	router, mockService := setupTest()
-	mockService.On("Login", mock.Anything, "testuser", "password123").Return("jwt-token-123", nil)
+	mockService.On("Login", mock.Anything, "testuser", "password123").Return(&domain.TokenPair{
+		AccessToken:  "jwt-token-123",
+		RefreshToken: "refresh-token-123",
+		ExpiresIn:    900,
+	}, nil)
 	reqBody := map[string]string{
 		"username": "testuser",
 		"password": "password123",
@@ -127,14 +169,18 @@ func TestLogin_Success(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
-	var response map[string]string
+	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Equal(t, "jwt-token-123", response["token"])
+	assert.Equal(t, "jwt-token-123", response["access_token"])
+	assert.Equal(t, "refresh-token-123", response["refresh_token"])
+	assert.Equal(t, "Bearer", response["token_type"])
+	assert.Equal(t, float64(900), response["expires_in"])
 	mockService.AssertExpectations(t)
 }
 func TestLogin_InvalidCredentials(t *testing.T) {
 	router, mockService := setupTest()
-	mockService.On("Login", mock.Anything, "wronguser", "wrongpass").Return("", domain.ErrInvalidCredentials)
+	mockService.On("Login", mock.Anything, "wronguser", "wrongpass").Return(nil, domain.ErrInvalidCredentials)
 	reqBody := map[string]string{
 		"username": "wronguser",
 		"password": "wrongpass",
@@ -147,6 +193,65 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	mockService.AssertExpectations(t)
 }
+func TestLogin_LockoutAfterRepeatedFailures(t *testing.T) {
+	router, mockService := setupTest()
+	mockService.On("Login", mock.Anything, "testuser", "wrongpass").Return(nil, domain.ErrInvalidCredentials)
+	login := func(username, password string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]string{"username": username, "password": password})
+		req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	for i := 0; i < ratelimit.DefaultLockoutPolicy.Threshold; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login("testuser", "wrongpass").Code)
+	}
+	w := login("TestUser", "password123")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	mockService.AssertNumberOfCalls(t, "Login", ratelimit.DefaultLockoutPolicy.Threshold)
+}
+func TestLogin_SpoofedForwardedForDoesNotBypassLockout(t *testing.T) {
+	router, mockService := setupTest()
+	require.NoError(t, router.SetTrustedProxies(nil))
+	mockService.On("Login", mock.Anything, mock.Anything, "wrongpass").Return(nil, domain.ErrInvalidCredentials)
+	login := func(username, forwardedFor string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]string{"username": username, "password": "wrongpass"})
+		req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	for i := 0; i < ratelimit.DefaultLockoutPolicy.Threshold; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login("testuser", fmt.Sprintf("203.0.113.%d", i)).Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login("testuser", "198.51.100.1").Code, "the account is locked whatever the address")
+	assert.Equal(t, http.StatusTooManyRequests, login("otheruser", "198.51.100.2").Code, "the real address is locked too")
+	mockService.AssertNumberOfCalls(t, "Login", ratelimit.DefaultLockoutPolicy.Threshold)
+}
+func TestLogin_SuccessDoesNotClearTheAddressBucket(t *testing.T) {
+	router, mockService := setupTest()
+	mockService.On("Login", mock.Anything, mock.Anything, "wrongpass").Return(nil, domain.ErrInvalidCredentials)
+	mockService.On("Login", mock.Anything, "attacker", "password123").Return(&domain.TokenPair{AccessToken: "token"}, nil)
+	login := func(username, password string) int {
+		jsonData, _ := json.Marshal(map[string]string{"username": username, "password": password})
+		req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	for i := 0; i < ratelimit.DefaultLockoutPolicy.Threshold-1; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login(fmt.Sprintf("victim%d", i), "wrongpass"))
+	}
+	assert.Equal(t, http.StatusOK, login("attacker", "password123"))
+	assert.Equal(t, http.StatusUnauthorized, login("victim9", "wrongpass"))
+	assert.Equal(t, http.StatusTooManyRequests, login("victim10", "wrongpass"))
+}
 func TestLogin_InvalidRequest(t *testing.T) {
 	router, _ := setupTest()
 	reqBody := map[string]string{
@@ -162,7 +267,7 @@ func TestLogin_InvalidRequest(t *testing.T) {
 }
 func TestLogin_ServerError(t *testing.T) {
 	router, mockService := setupTest()
-	mockService.On("Login", mock.Anything, "testuser", "password123").Return("", errors.New("database error"))
+	mockService.On("Login", mock.Anything, "testuser", "password123").Return(nil, errors.New("database error"))
 	reqBody := map[string]string{
 		"username": "testuser",
 		"password": "password123",
@@ -175,3 +280,52 @@ func TestLogin_ServerError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	mockService.AssertExpectations(t)
 }
+func TestRefresh_Success(t *testing.T) {
+	router, mockService := setupTest()
+	mockService.On("RefreshToken", mock.Anything, "refresh-token-123").Return(&domain.TokenPair{
+		AccessToken:  "jwt-token-456",
+		RefreshToken: "refresh-token-456",
+		ExpiresIn:    900,
+	}, nil)
+	jsonData, _ := json.Marshal(map[string]string{"refresh_token": "refresh-token-123"})
+	req, _ := http.NewRequest("POST", "/token/refresh", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "jwt-token-456", response["access_token"])
+	assert.Equal(t, "refresh-token-456", response["refresh_token"])
+	mockService.AssertExpectations(t)
+}
+func TestRefresh_ReusedToken(t *testing.T) {
+	router, mockService := setupTest()
+	mockService.On("RefreshToken", mock.Anything, "old-token").Return(nil, domain.ErrRefreshTokenReused)
+	jsonData, _ := json.Marshal(map[string]string{"refresh_token": "old-token"})
+	req, _ := http.NewRequest("POST", "/token/refresh", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertExpectations(t)
+}
+func TestRefresh_MissingToken(t *testing.T) {
+	router, _ := setupTest()
+	req, _ := http.NewRequest("POST", "/token/refresh", bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+func TestLogout_Success(t *testing.T) {
+	router, mockService := setupTest()
+	mockService.On("Logout", mock.Anything, "refresh-token-123").Return(nil)
+	jsonData, _ := json.Marshal(map[string]string{"refresh_token": "refresh-token-123"})
+	req, _ := http.NewRequest("POST", "/logout", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
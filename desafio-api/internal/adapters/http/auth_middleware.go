@@ -1,27 +1,61 @@
 package http
 import (
+	"log/slog"
 	"net/http"
 	"strings"
 	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
 	"github.com/gin-gonic/gin"
 )
-func AuthMiddleware(userService service.UserServiceInterface) gin.HandlerFunc {
+func AuthMiddleware(userService service.UserServiceInterface, logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			respondWithError(c, http.StatusUnauthorized, "Token de autenticação ausente ou inválido")
+		if authenticate(c, userService, logger) {
+			c.Next()
+		}
+	}
+}
+// authenticate validates the bearer token and stores the user in the
+// context, or aborts with 401. The role in the token is as old as the token,
+// so requests that change data use the stored role instead; a demotion then
+// applies to writes at once rather than when the token expires.
+func authenticate(c *gin.Context, userService service.UserServiceInterface, logger *slog.Logger) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		respondWithError(c, http.StatusUnauthorized, "Token de autenticação ausente ou inválido")
+		c.Abort()
+		return false
+	}
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	claims, err := userService.ValidateToken(tokenString)
+	if err != nil {
+		respondWithError(c, http.StatusUnauthorized, "Token de autenticação inválido ou expirado")
+		c.Abort()
+		return false
+	}
+	role := claims.Role
+	if !isReadOnlyMethod(c.Request.Method) {
+		user, err := userService.GetUserByID(c.Request.Context(), claims.UserID)
+		if err == domain.ErrUserNotFound {
+			respondWithError(c, http.StatusUnauthorized, "Token de autenticação inválido ou expirado")
 			c.Abort()
-			return
+			return false
 		}
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := userService.ValidateToken(tokenString)
 		if err != nil {
-			respondWithError(c, http.StatusUnauthorized, "Token de autenticação inválido ou expirado")
+			respondInternalError(c, logger, "Falha ao verificar o usuário", err)
 			c.Abort()
-			return
+			return false
 		}
-		c.Set("userID", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Next()
+		role = user.Role
 	}
+	if role == "" {
+		role = domain.RoleViewer
+	}
+	c.Set("userID", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("role", role)
+	c.Request = c.Request.WithContext(domain.ContextWithUser(c.Request.Context(), claims.UserID, role))
+	return true
+}
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
 }
@@ -5,7 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"desafio-api/internal/application/service"
+	"desafio-api/internal/adapters/logging"
 	"desafio-api/internal/domain"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -18,9 +18,23 @@ func (m *MockUserServiceForAuth) Register(ctx context.Context, user *domain.User
 	args := m.Called(ctx, user)
 	return args.Error(0)
 }
-func (m *MockUserServiceForAuth) Login(ctx context.Context, username, password string) (string, error) {
+func (m *MockUserServiceForAuth) Login(ctx context.Context, username, password string) (*domain.TokenPair, error) {
 	args := m.Called(ctx, username, password)
-	return args.String(0), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+func (m *MockUserServiceForAuth) RefreshToken(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+func (m *MockUserServiceForAuth) Logout(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
+	return args.Error(0)
 }
 func (m *MockUserServiceForAuth) ValidateToken(tokenString string) (*domain.JWTClaims, error) {
 	args := m.Called(tokenString)
@@ -36,17 +50,35 @@ func (m *MockUserServiceForAuth) GetUserByID(ctx context.Context, id int) (*doma
 	}
 	return args.Get(0).(*domain.User), args.Error(1)
 }
-func (m *MockUserServiceForAuth) GetRepository() service.UserRepository {
-	args := m.Called()
+func (m *MockUserServiceForAuth) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+func (m *MockUserServiceForAuth) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+func (m *MockUserServiceForAuth) AssignRole(ctx context.Context, userID int, role string) (*domain.User, error) {
+	args := m.Called(ctx, userID, role)
 	if args.Get(0) == nil {
-		return nil
+		return nil, args.Error(1)
 	}
-	return args.Get(0).(service.UserRepository)
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+func (m *MockUserServiceForAuth) GetRepository() interface{} {
+	args := m.Called()
+	return args.Get(0)
 }
 func TestAuthMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUserService := new(MockUserServiceForAuth)
-	middleware := AuthMiddleware(mockUserService)
+	middleware := AuthMiddleware(mockUserService, logging.Nop())
 	router := gin.New()
 	router.GET("/protected", middleware, func(c *gin.Context) {
 		userID, exists := c.Get("userID")
@@ -93,3 +125,36 @@ func TestAuthMiddleware(t *testing.T) {
 		mockUserService.AssertExpectations(t)
 	})
 }
+func TestAuthMiddleware_WritesUseStoredRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUserService := new(MockUserServiceForAuth)
+	router := gin.New()
+	router.Use(AuthMiddleware(mockUserService, logging.Nop()))
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"role": c.GetString("role")})
+	}
+	router.GET("/items", handler)
+	router.POST("/items", handler)
+	claims := &domain.JWTClaims{UserID: 7, Username: "editor", Role: domain.RoleEditor}
+	mockUserService.On("ValidateToken", "stale-token").Return(claims, nil)
+	mockUserService.On("GetUserByID", mock.Anything, 7).Return(&domain.User{ID: 7, Username: "editor", Role: domain.RoleViewer}, nil).Once()
+	roleFor := func(method string) (int, string) {
+		req, _ := http.NewRequest(method, "/items", nil)
+		req.Header.Set("Authorization", "Bearer stale-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response map[string]string
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response["role"]
+	}
+	code, role := roleFor("GET")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, domain.RoleEditor, role)
+	code, role = roleFor("POST")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, domain.RoleViewer, role)
+	mockUserService.On("GetUserByID", mock.Anything, 7).Return(nil, domain.ErrUserNotFound).Once()
+	code, _ = roleFor("POST")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	mockUserService.AssertExpectations(t)
+}
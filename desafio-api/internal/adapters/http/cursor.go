@@ -0,0 +1,56 @@
+package http
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	repoPort "desafio-api/internal/ports/repository"
+)
+var ErrInvalidCursor = errors.New("invalid or tampered cursor")
+// CursorCodec turns keyset positions into opaque tokens signed with
+// HMAC-SHA256, so clients cannot craft or alter positions.
+type CursorCodec struct {
+	secret []byte
+}
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+func (c *CursorCodec) Encode(key repoPort.ItemKey) string {
+	payload := strconv.FormatInt(key.UpdatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(key.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+func (c *CursorCodec) Decode(cursor string) (*repoPort.ItemKey, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(cursor, ".")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(string(payload))) {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, found := strings.Cut(string(payload), ":")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	itemID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repoPort.ItemKey{UpdatedAt: time.Unix(0, unixNano).UTC(), ID: itemID}, nil
+}
+func (c *CursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,145 @@
+package http
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+	"desafio-api/internal/adapters/database/migrate"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+const DebugTokenHeader = "X-Debug-Token"
+type MigrationStatusReader interface {
+	Status(ctx context.Context) ([]migrate.MigrationStatus, error)
+}
+// DebugHandler serves read-only diagnostics. db and migrations are nil when
+// the API runs on the in-memory repositories.
+type DebugHandler struct {
+	db         *sqlx.DB
+	migrations MigrationStatusReader
+	settings   map[string]interface{}
+	started    time.Time
+	logger     *slog.Logger
+}
+// NewDebugHandler takes settings already redacted, as returned by
+// config.Config.Redacted.
+func NewDebugHandler(db *sqlx.DB, migrations MigrationStatusReader, settings map[string]interface{}, logger *slog.Logger) *DebugHandler {
+	return &DebugHandler{db: db, migrations: migrations, settings: settings, started: time.Now(), logger: logger}
+}
+// DebugAuthMiddleware accepts the debug token in X-Debug-Token or, without
+// that header, the bearer token of an admin.
+func DebugAuthMiddleware(token string, userService service.UserServiceInterface, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if provided := c.GetHeader(DebugTokenHeader); provided != "" {
+			if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				RespondWithError(c, http.StatusUnauthorized, "Token de depuração inválido")
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+		if !authenticate(c, userService, logger) {
+			return
+		}
+		if c.GetString("role") != domain.RoleAdmin {
+			RespondWithError(c, http.StatusForbidden, "Acesso negado para o perfil do usuário")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+func (h *DebugHandler) Database(c *gin.Context) {
+	if h.db == nil {
+		RespondWithError(c, http.StatusServiceUnavailable, "Banco de dados indisponível; a API está usando repositórios simulados")
+		return
+	}
+	started := time.Now()
+	err := h.db.PingContext(c.Request.Context())
+	body := gin.H{
+		"driver":  h.db.DriverName(),
+		"ping_ms": float64(time.Since(started).Microseconds()) / 1000,
+	}
+	if err != nil {
+		body["ping_error"] = err.Error()
+	}
+	stats := h.db.Stats()
+	body["pool"] = gin.H{
+		"max_open_connections": stats.MaxOpenConnections,
+		"open_connections":     stats.OpenConnections,
+		"in_use":               stats.InUse,
+		"idle":                 stats.Idle,
+		"wait_count":           stats.WaitCount,
+		"wait_duration":        stats.WaitDuration.String(),
+		"max_idle_closed":      stats.MaxIdleClosed,
+		"max_idle_time_closed": stats.MaxIdleTimeClosed,
+		"max_lifetime_closed":  stats.MaxLifetimeClosed,
+	}
+	c.JSON(http.StatusOK, body)
+}
+func (h *DebugHandler) Migrations(c *gin.Context) {
+	if h.migrations == nil {
+		RespondWithError(c, http.StatusServiceUnavailable, "Banco de dados indisponível; a API está usando repositórios simulados")
+		return
+	}
+	statuses, err := h.migrations.Status(c.Request.Context())
+	if err != nil {
+		respondInternalError(c, h.logger, "Falha ao consultar as migrações", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"migrations": statuses})
+}
+// Build reports the binary version and runtime. Only the vcs.* build
+// settings are included, since -ldflags may carry values that must stay
+// private.
+func (h *DebugHandler) Build(c *gin.Context) {
+	body := gin.H{
+		"go_version": runtime.Version(),
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+		"num_cpu":    runtime.NumCPU(),
+		"goroutines": runtime.NumGoroutine(),
+		"started_at": h.started.UTC().Format(time.RFC3339),
+		"uptime":     time.Since(h.started).Round(time.Second).String(),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		body["module"] = info.Main.Path
+		body["version"] = info.Main.Version
+		vcs := gin.H{}
+		for _, setting := range info.Settings {
+			if strings.HasPrefix(setting.Key, "vcs.") {
+				vcs[strings.TrimPrefix(setting.Key, "vcs.")] = setting.Value
+			}
+		}
+		body["vcs"] = vcs
+	}
+	c.JSON(http.StatusOK, body)
+}
+func (h *DebugHandler) Config(c *gin.Context) {
+	c.JSON(http.StatusOK, h.settings)
+}
+// Pprof serves net/http/pprof; it must be routed as /debug/pprof/*profile,
+// the path pprof.Index expects. /cmdline is left out since secrets can be
+// passed as flags.
+func (h *DebugHandler) Pprof(c *gin.Context) {
+	switch c.Param("profile") {
+	case "/cmdline":
+		RespondWithError(c, http.StatusNotFound, "Perfil não disponível")
+	case "/profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "/symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "/trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,102 @@
+package http
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"desafio-api/internal/adapters/database/migrate"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+type stubMigrationStatus struct {
+	statuses []migrate.MigrationStatus
+	err      error
+}
+func (s stubMigrationStatus) Status(ctx context.Context) ([]migrate.MigrationStatus, error) {
+	return s.statuses, s.err
+}
+func newDebugRouter(handler *DebugHandler, userService *MockUserServiceForAuth) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	debug := router.Group("/debug", DebugAuthMiddleware("0123456789abcdef0123456789abcdef", userService, logging.Nop()))
+	debug.GET("/database", handler.Database)
+	debug.GET("/migrations", handler.Migrations)
+	debug.GET("/build", handler.Build)
+	debug.GET("/config", handler.Config)
+	debug.GET("/pprof/*profile", handler.Pprof)
+	return router
+}
+func TestDebugAuthMiddleware(t *testing.T) {
+	userService := new(MockUserServiceForAuth)
+	router := newDebugRouter(NewDebugHandler(nil, nil, nil, logging.Nop()), userService)
+	get := func(header, value string) int {
+		req, _ := http.NewRequest("GET", "/debug/build", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusUnauthorized, get("", ""))
+	assert.Equal(t, http.StatusOK, get(DebugTokenHeader, "0123456789abcdef0123456789abcdef"))
+	assert.Equal(t, http.StatusUnauthorized, get(DebugTokenHeader, "wrong"))
+	userService.On("ValidateToken", "viewer-token").Return(&domain.JWTClaims{UserID: 2, Role: domain.RoleViewer}, nil).Once()
+	assert.Equal(t, http.StatusForbidden, get("Authorization", "Bearer viewer-token"))
+	userService.On("ValidateToken", "admin-token").Return(&domain.JWTClaims{UserID: 1, Role: domain.RoleAdmin}, nil).Once()
+	assert.Equal(t, http.StatusOK, get("Authorization", "Bearer admin-token"))
+	userService.AssertExpectations(t)
+}
+func TestDebugAuthMiddleware_NoTokenConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/debug/build", DebugAuthMiddleware("", new(MockUserServiceForAuth), logging.Nop()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	req, _ := http.NewRequest("GET", "/debug/build", nil)
+	req.Header.Set(DebugTokenHeader, "anything")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+func TestDebugHandler(t *testing.T) {
+	migrations := stubMigrationStatus{statuses: []migrate.MigrationStatus{{Version: 1, Name: "create_items", Applied: true}}}
+	settings := map[string]interface{}{"database": map[string]interface{}{"password": "[REDACTED]"}}
+	router := newDebugRouter(NewDebugHandler(nil, migrations, settings, logging.Nop()), new(MockUserServiceForAuth))
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set(DebugTokenHeader, "0123456789abcdef0123456789abcdef")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+	w, _ := get("/debug/database")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	w, body := get("/debug/migrations")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, body["migrations"], 1)
+	w, body = get("/debug/config")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, settings["database"], body["database"])
+	w, body = get("/debug/build")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, body["go_version"])
+	assert.NotContains(t, body, "settings")
+	w, _ = get("/debug/pprof/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine")
+	w, _ = get("/debug/pprof/cmdline")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), os.Args[0], "flags may carry secrets")
+	router = newDebugRouter(NewDebugHandler(nil, stubMigrationStatus{err: errors.New("boom")}, nil, logging.Nop()), new(MockUserServiceForAuth))
+	w, _ = get("/debug/migrations")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
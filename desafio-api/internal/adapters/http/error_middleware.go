@@ -1,21 +1,27 @@
 package http
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"runtime/debug"
 	"time"
+	"desafio-api/internal/domain"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
-func ErrorMiddleware() gin.HandlerFunc {
+// errorID identifies a failed request to support: the request id when
+// RequestIDMiddleware ran, so it matches the X-Request-ID header and the
+// request's log lines.
+func errorID(c *gin.Context) string {
+	if requestID := domain.RequestIDFromContext(c.Request.Context()); requestID != "" {
+		return requestID
+	}
+	return uuid.New().String()
+}
+func ErrorMiddleware(logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		errorID := uuid.New().String()
 		defer func() {
 			if r := recover(); r != nil {
-				stack := string(debug.Stack())
-				log.Printf("[CRITICAL] Panic recovered [ID: %s]:\nError: %v\nStack Trace:\n%s", 
-					errorID, r, stack)
 				var errorMessage string
 				switch v := r.(type) {
 				case error:
@@ -25,40 +31,35 @@ func ErrorMiddleware() gin.HandlerFunc {
 				default:
 					errorMessage = fmt.Sprintf("%v", r)
 				}
-				clientIP := c.ClientIP()
-				method := c.Request.Method
-				path := c.Request.URL.Path
-				userID, exists := c.Get("userID")
-				errorLog := map[string]interface{}{
-					"error_id":  errorID,
-					"message":   errorMessage,
-					"method":    method,
-					"path":      path,
-					"remote_ip": clientIP,
-					"status":    http.StatusInternalServerError,
-				}
-				if exists {
-					errorLog["user_id"] = userID
-				}
-				log.Printf("[CRITICAL] Erro do cliente [ID: %s]: %v", errorID, errorLog)
+				id := errorID(c)
+				logger.ErrorContext(c.Request.Context(), "panic recovered",
+					slog.String("error_id", id),
+					slog.String("panic", errorMessage),
+					slog.String("method", c.Request.Method),
+					slog.String("path", c.Request.URL.Path),
+					slog.String("client_ip", c.ClientIP()),
+					slog.String("stack", string(debug.Stack())),
+				)
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 					"error":      "Ocorreu um erro interno no servidor",
-					"error_id":   errorID,
+					"error_id":   id,
+					"trace_id":   traceID(c),
 					"status":     http.StatusInternalServerError,
 					"timestamp":  time.Now().Format(time.RFC3339),
-					"message":    errorMessage, 
+					"message":    errorMessage,
 				})
 			}
 		}()
 		c.Next()
 		if len(c.Errors) > 0 {
-			errorID := uuid.New().String()
+			id := errorID(c)
 			for _, err := range c.Errors {
-				log.Printf("[ERROR] Gin error [ID: %s]: %v", errorID, err)
+				logger.ErrorContext(c.Request.Context(), "unhandled gin error", slog.String("error_id", id), slog.Any("error", err.Err))
 				if !c.Writer.Written() {
 					c.JSON(http.StatusInternalServerError, gin.H{
 						"error":     "Ocorreu um erro não tratado",
-						"error_id":  errorID,
+						"error_id":  id,
+						"trace_id":  traceID(c),
 						"timestamp": time.Now().Format(time.RFC3339),
 					})
 					break
@@ -67,24 +68,27 @@ func ErrorMiddleware() gin.HandlerFunc {
 		}
 	}
 }
-func LoggingMiddleware() gin.HandlerFunc {
+// LoggingMiddleware writes one access log line per request, at warn level
+// for 4xx and error level for 5xx responses.
+func LoggingMiddleware(logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		startTime := time.Now()
-		requestPath := c.Request.URL.Path
-		requestMethod := c.Request.Method
-		clientIP := c.ClientIP()
-		log.Printf("[INFO] Request: %s %s from %s", requestMethod, requestPath, clientIP)
 		c.Next()
-		endTime := time.Now()
-		latency := endTime.Sub(startTime)
 		statusCode := c.Writer.Status()
-		logLevel := "[INFO]"
+		level := slog.LevelInfo
 		if statusCode >= 400 && statusCode < 500 {
-			logLevel = "[WARN]"
+			level = slog.LevelWarn
 		} else if statusCode >= 500 {
-			logLevel = "[ERROR]"
+			level = slog.LevelError
 		}
-		log.Printf("%s Response: %s %s | Status: %d | Latency: %v", 
-			logLevel, requestMethod, requestPath, statusCode, latency)
+		logger.LogAttrs(c.Request.Context(), level, "request completed",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", statusCode),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.Duration("latency", time.Since(startTime)),
+			slog.String("client_ip", c.ClientIP()),
+		)
 	}
 }
@@ -4,13 +4,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"desafio-api/internal/adapters/logging"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 func TestErrorMiddleware_NoPanic(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(ErrorMiddleware())
+	router.Use(ErrorMiddleware(logging.Nop()))
 	router.GET("/no-panic", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -23,7 +24,7 @@ func TestErrorMiddleware_NoPanic(t *testing.T) {
 func TestErrorMiddleware_WithPanic(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(ErrorMiddleware())
+	router.Use(ErrorMiddleware(logging.Nop()))
 	router.GET("/panic-error", func(c *gin.Context) {
 		panic(errors.New("test error"))
 	})
@@ -36,7 +37,7 @@ func TestErrorMiddleware_WithPanic(t *testing.T) {
 func TestErrorMiddleware_WithPanicString(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(ErrorMiddleware())
+	router.Use(ErrorMiddleware(logging.Nop()))
 	router.GET("/panic-string", func(c *gin.Context) {
 		panic("test panic string")
 	})
@@ -49,7 +50,7 @@ func TestErrorMiddleware_WithPanicString(t *testing.T) {
 func TestErrorMiddleware_WithPanicOther(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(ErrorMiddleware())
+	router.Use(ErrorMiddleware(logging.Nop()))
 	router.GET("/panic-other", func(c *gin.Context) {
 		panic(123) 
 	})
@@ -59,3 +60,18 @@ func TestErrorMiddleware_WithPanicOther(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "erro interno")
 }
+func TestErrorMiddleware_UsesRequestIDAsErrorID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.Use(ErrorMiddleware(logging.Nop()))
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"error_id":"req-123"`)
+}
@@ -0,0 +1,19 @@
+package http
+import (
+	"net/http"
+	"desafio-api/internal/adapters/health"
+	"github.com/gin-gonic/gin"
+)
+// HealthHandler answers 200 with the per-check report while registry is
+// healthy and 503 otherwise.
+func HealthHandler(registry *health.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := registry.Run(c.Request.Context())
+		status := http.StatusOK
+		if !report.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+		c.Header("Cache-Control", "no-store")
+		c.JSON(status, report)
+	}
+}
@@ -0,0 +1,45 @@
+package http
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"desafio-api/internal/adapters/health"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func TestHealthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	readiness := health.NewRegistry()
+	readiness.CacheTTL = 0
+	var dbErr error
+	readiness.Register("database", health.CheckerFunc(func(ctx context.Context) (health.Detail, error) {
+		return nil, dbErr
+	}))
+	router := gin.New()
+	router.GET("/readyz", HealthHandler(readiness))
+	get := func() (*httptest.ResponseRecorder, health.Report) {
+		req, _ := http.NewRequest("GET", "/readyz", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var report health.Report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		return w, report
+	}
+	w, report := get()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	dbErr = errors.New("connection refused")
+	w, report = get()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+	dbErr = nil
+	readiness.Shutdown()
+	w, report = get()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.True(t, report.ShuttingDown)
+}
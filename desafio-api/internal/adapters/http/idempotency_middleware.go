@@ -0,0 +1,149 @@
+package http
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/gin-gonic/gin"
+)
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+	DefaultIdempotencyTTL     = 24 * time.Hour
+	maxIdempotencyKeyLength   = 255
+	// idempotencyLease is how long a request may hold its key before a retry
+	// takes it over, which frees keys left behind by a crashed instance. It
+	// must outlast the server's write timeout.
+	idempotencyLease          = 2 * time.Minute
+	// maxIdempotentBodyBytes bounds the body buffered for the fingerprint; the
+	// largest legitimate body is an import file.
+	maxIdempotentBodyBytes    = maxImportBytes
+)
+// idempotencyStoredHeaders are the response headers replayed along with the
+// stored body.
+var idempotencyStoredHeaders = []string{"Content-Type", "Location", "ETag"}
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+func (w *idempotencyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+func (w *idempotencyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+// IdempotencyMiddleware makes mutating requests that carry an
+// Idempotency-Key safe to retry: the first response for each user and key is
+// stored for ttl and replayed to later requests with the same body. Server
+// errors are not stored, so the request can be retried with the same key.
+// It must run after AuthMiddleware.
+func IdempotencyMiddleware(store repoPort.IdempotencyRepository, ttl time.Duration, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || isReadOnlyMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			RespondWithError(c, http.StatusBadRequest, "O cabeçalho Idempotency-Key deve ter no máximo 255 caracteres")
+			c.Abort()
+			return
+		}
+		userID := c.GetInt("userID")
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			RespondWithError(c, http.StatusRequestEntityTooLarge, "O corpo da requisição deve ter no máximo 10 MB")
+			c.Abort()
+			return
+		}
+		if err != nil {
+			RespondWithError(c, http.StatusBadRequest, "Falha ao ler o corpo da requisição")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		ctx := c.Request.Context()
+		record := &domain.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: requestFingerprint(c.Request.Method, c.Request.URL.RequestURI(), c.ContentType(), body),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+		stored, reserved, err := store.Reserve(ctx, record, time.Now().Add(-idempotencyLease))
+		if err != nil {
+			respondInternalError(c, logger, "Falha ao processar a chave de idempotência", err)
+			c.Abort()
+			return
+		}
+		if !reserved {
+			replayIdempotentResponse(c, record, stored)
+			return
+		}
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		defer func() {
+			c.Writer = recorder.ResponseWriter
+			status := recorder.Status()
+			// The client may have gone away, which cancels ctx, but its
+			// retry still needs the key settled.
+			storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			defer cancel()
+			if r := recover(); r != nil || status >= http.StatusInternalServerError {
+				if err := store.Release(storeCtx, record); err != nil {
+					logger.ErrorContext(ctx, "releasing idempotency key failed", slog.Any("error", err))
+				}
+				if r != nil {
+					panic(r)
+				}
+				return
+			}
+			record.StatusCode = status
+			record.Body = recorder.body.Bytes()
+			record.Headers = make(map[string]string)
+			for _, name := range idempotencyStoredHeaders {
+				if value := recorder.Header().Get(name); value != "" {
+					record.Headers[name] = value
+				}
+			}
+			if err := store.Complete(storeCtx, record); err != nil {
+				logger.ErrorContext(ctx, "storing idempotent response failed", slog.Any("error", err))
+			}
+		}()
+		c.Next()
+	}
+}
+func replayIdempotentResponse(c *gin.Context, record, stored *domain.IdempotencyRecord) {
+	switch {
+	case stored.Fingerprint != record.Fingerprint:
+		RespondWithError(c, http.StatusUnprocessableEntity, "A chave de idempotência já foi usada com uma requisição diferente")
+	case !stored.Completed():
+		RespondWithError(c, http.StatusConflict, "Uma requisição com esta chave de idempotência ainda está em andamento")
+	default:
+		for name, value := range stored.Headers {
+			c.Header(name, value)
+		}
+		c.Header(IdempotencyReplayedHeader, "true")
+		c.Status(stored.StatusCode)
+		c.Writer.Write(stored.Body)
+	}
+	c.Abort()
+}
+// requestFingerprint identifies a request by method, URI with its query
+// string, content type and body, so a key reused for a different request is
+// detected.
+func requestFingerprint(method, uri, contentType string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + uri + "\n" + contentType + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,179 @@
+package http
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+// contextAwareIdempotencyStore fails like the SQL store once ctx is done.
+type contextAwareIdempotencyStore struct {
+	repoPort.IdempotencyRepository
+}
+func (s contextAwareIdempotencyStore) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.IdempotencyRepository.Complete(ctx, record)
+}
+func (s contextAwareIdempotencyStore) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.IdempotencyRepository.Release(ctx, record)
+}
+type idempotencyTest struct {
+	router     *gin.Engine
+	calls      int
+	status     int
+	userID     int
+	disconnect context.CancelFunc
+}
+func setupIdempotencyTest(ttl time.Duration) *idempotencyTest {
+	gin.SetMode(gin.TestMode)
+	test := &idempotencyTest{status: http.StatusCreated, userID: 1}
+	test.router = gin.New()
+	test.router.Use(func(c *gin.Context) {
+		c.Set("userID", test.userID)
+		c.Next()
+	})
+	test.router.Use(IdempotencyMiddleware(contextAwareIdempotencyStore{repository.NewMockIdempotencyRepository()}, ttl, logging.Nop()))
+	test.router.POST("/items", func(c *gin.Context) {
+		test.calls++
+		if test.disconnect != nil {
+			test.disconnect()
+		}
+		c.Header("Location", "/api/v1/items/7")
+		c.JSON(test.status, gin.H{"id": 7, "call": test.calls})
+	})
+	return test
+}
+func (i *idempotencyTest) post(key, body string) *httptest.ResponseRecorder {
+	return i.postTo("/items", key, body)
+}
+func (i *idempotencyTest) postTo(path, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	i.router.ServeHTTP(w, req)
+	return w
+}
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	test := setupIdempotencyTest(DefaultIdempotencyTTL)
+	first := test.post("abc", `{"code":"A"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(IdempotencyReplayedHeader))
+	retry := test.post("abc", `{"code":"A"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "/api/v1/items/7", retry.Header().Get("Location"))
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Equal(t, "true", retry.Header().Get(IdempotencyReplayedHeader))
+	assert.Equal(t, 1, test.calls)
+	test.post("", `{"code":"A"}`)
+	test.post("other", `{"code":"A"}`)
+	assert.Equal(t, 3, test.calls)
+}
+func TestIdempotency_KeysAreScopedPerUser(t *testing.T) {
+	test := setupIdempotencyTest(DefaultIdempotencyTTL)
+	test.post("abc", `{}`)
+	test.userID = 2
+	test.post("abc", `{}`)
+	assert.Equal(t, 2, test.calls)
+}
+func TestIdempotency_RejectsKeyReuseWithDifferentBody(t *testing.T) {
+	test := setupIdempotencyTest(DefaultIdempotencyTTL)
+	test.post("abc", `{"code":"A"}`)
+	w := test.post("abc", `{"code":"B"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, test.calls)
+	w = test.post(strings.Repeat("k", 256), `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	test := setupIdempotencyTest(DefaultIdempotencyTTL)
+	test.status = http.StatusInternalServerError
+	test.post("abc", `{}`)
+	test.status = http.StatusCreated
+	w := test.post("abc", `{}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 2, test.calls)
+}
+func TestIdempotency_ExpiredKeysRunAgain(t *testing.T) {
+	test := setupIdempotencyTest(-time.Second)
+	test.post("abc", `{}`)
+	w := test.post("abc", `{}`)
+	assert.Empty(t, w.Header().Get(IdempotencyReplayedHeader))
+	assert.Equal(t, 2, test.calls)
+}
+func TestIdempotency_FingerprintIncludesQueryAndContentType(t *testing.T) {
+	test := setupIdempotencyTest(DefaultIdempotencyTTL)
+	test.postTo("/items?dry_run=true", "abc", `{}`)
+	w := test.postTo("/items?dry_run=false", "abc", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	req, _ := http.NewRequest("POST", "/items?dry_run=true", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set(IdempotencyKeyHeader, "abc")
+	w = httptest.NewRecorder()
+	test.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, test.calls)
+}
+func TestIdempotency_ResponseIsStoredAfterClientDisconnects(t *testing.T) {
+	test := setupIdempotencyTest(DefaultIdempotencyTTL)
+	ctx, cancel := context.WithCancel(context.Background())
+	test.disconnect = cancel
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/items", bytes.NewBufferString(`{}`))
+	req.Header.Set(IdempotencyKeyHeader, "abc")
+	test.router.ServeHTTP(httptest.NewRecorder(), req)
+	test.disconnect = nil
+	req, _ = http.NewRequest("POST", "/items", bytes.NewBufferString(`{}`))
+	req.Header.Set(IdempotencyKeyHeader, "abc")
+	w := httptest.NewRecorder()
+	test.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "true", w.Header().Get(IdempotencyReplayedHeader))
+	assert.Equal(t, 1, test.calls)
+}
+func TestIdempotency_RejectsOversizedBody(t *testing.T) {
+	test := setupIdempotencyTest(DefaultIdempotencyTTL)
+	w := test.post("abc", strings.Repeat("a", maxIdempotentBodyBytes+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, 0, test.calls)
+}
+func TestIdempotency_AbandonedReservationIsTakenOver(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMockIdempotencyRepository()
+	newRecord := func() *domain.IdempotencyRecord {
+		return &domain.IdempotencyRecord{UserID: 1, Key: "abc", Fingerprint: "f", ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	abandoned := newRecord()
+	_, reserved, err := store.Reserve(ctx, abandoned, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.True(t, reserved)
+	_, reserved, err = store.Reserve(ctx, newRecord(), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.False(t, reserved, "a recent reservation is still in progress")
+	retry := newRecord()
+	_, reserved, err = store.Reserve(ctx, retry, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, reserved, "a reservation older than the lease is taken over")
+	abandoned.StatusCode = http.StatusCreated
+	require.NoError(t, store.Complete(ctx, abandoned))
+	stored, _, err := store.Reserve(ctx, newRecord(), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, retry.ID, stored.ID)
+	assert.False(t, stored.Completed(), "the abandoned request must not complete the new reservation")
+}
@@ -0,0 +1,161 @@
+package http
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"desafio-api/internal/domain"
+	"desafio-api/pkg/xlsx"
+	"github.com/gin-gonic/gin"
+)
+var exportColumns = []string{
+	"id", "code", "title", "description", "price", "stock", "reserved", "available",
+	"status", "created_at", "updated_at", "created_by", "updated_by", "version",
+}
+type itemExportWriter interface {
+	Write(item *domain.Item) error
+	Close() error
+}
+type itemExportFormat struct {
+	contentType string
+	newWriter   func(w io.Writer) (itemExportWriter, error)
+}
+var itemExportFormats = map[string]itemExportFormat{
+	"csv":    {"text/csv; charset=utf-8", newCSVItemWriter},
+	"ndjson": {"application/x-ndjson", newNDJSONItemWriter},
+	"xlsx":   {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", newXLSXItemWriter},
+}
+// Export streams every item matching the listing filters. Headers are only
+// sent once the first row is ready, so a failing query still gets a JSON
+// error; a failure after that can only cut the download short.
+func (h *ItemHandler) Export(c *gin.Context) {
+	filter, message := parseItemFilter(c)
+	if message != "" {
+		RespondWithError(c, http.StatusBadRequest, message)
+		return
+	}
+	formatName := c.DefaultQuery("format", "csv")
+	format, ok := itemExportFormats[formatName]
+	if !ok {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'format' deve ser csv, ndjson ou xlsx")
+		return
+	}
+	var writer itemExportWriter
+	start := func() error {
+		// Large catalogs take longer than the server's write timeout.
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+		filename := "itens-" + time.Now().UTC().Format("20060102-150405") + "." + formatName
+		c.Header("Content-Type", format.contentType)
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		c.Status(http.StatusOK)
+		var err error
+		writer, err = format.newWriter(c.Writer)
+		return err
+	}
+	rows := 0
+	err := h.itemService.Export(c.Request.Context(), filter, func(item *domain.Item) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		rows++
+		return writer.Write(item)
+	})
+	if err == nil && writer == nil {
+		err = start()
+	}
+	if err == nil && writer != nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		respondInternalError(c, h.logger, "Falha ao exportar os itens", err)
+		return
+	}
+	h.logger.ErrorContext(c.Request.Context(), "item export interrupted", slog.Int("rows", rows), slog.Any("error", err))
+}
+func exportRecord(item *domain.Item) []interface{} {
+	return []interface{}{
+		item.ID, item.Code, item.Title, item.Description, item.Price, item.Stock, item.Reserved, item.Available(),
+		item.Status, item.CreatedAt.Format(time.RFC3339), item.UpdatedAt.Format(time.RFC3339), item.CreatedBy, item.UpdatedBy, item.Version,
+	}
+}
+type csvItemWriter struct {
+	csv *csv.Writer
+}
+func newCSVItemWriter(w io.Writer) (itemExportWriter, error) {
+	writer := &csvItemWriter{csv: csv.NewWriter(w)}
+	return writer, writer.csv.Write(exportColumns)
+}
+func (w *csvItemWriter) Write(item *domain.Item) error {
+	values := exportRecord(item)
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case string:
+			record[i] = spreadsheetSafe(v)
+		case int:
+			record[i] = strconv.Itoa(v)
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		}
+	}
+	return w.csv.Write(record)
+}
+func (w *csvItemWriter) Close() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+// spreadsheetSafe keeps spreadsheet apps from evaluating user-provided text
+// that looks like a formula.
+func spreadsheetSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+type ndjsonItemWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+func newNDJSONItemWriter(w io.Writer) (itemExportWriter, error) {
+	buffer := bufio.NewWriter(w)
+	return &ndjsonItemWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}, nil
+}
+func (w *ndjsonItemWriter) Write(item *domain.Item) error {
+	return w.encoder.Encode(toItemResponse(item))
+}
+func (w *ndjsonItemWriter) Close() error {
+	return w.buffer.Flush()
+}
+type xlsxItemWriter struct {
+	sheet *xlsx.StreamWriter
+}
+func newXLSXItemWriter(w io.Writer) (itemExportWriter, error) {
+	sheet, err := xlsx.NewStreamWriter(w, "Itens")
+	if err != nil {
+		return nil, err
+	}
+	header := make([]interface{}, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column
+	}
+	return &xlsxItemWriter{sheet: sheet}, sheet.WriteRow(header...)
+}
+func (w *xlsxItemWriter) Write(item *domain.Item) error {
+	return w.sheet.WriteRow(exportRecord(item)...)
+}
+func (w *xlsxItemWriter) Close() error {
+	return w.sheet.Close()
+}
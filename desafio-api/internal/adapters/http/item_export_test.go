@@ -0,0 +1,68 @@
+package http
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+func TestExport_CSV(t *testing.T) {
+	router, mockService := setupItemTest()
+	item := createTestItem()
+	item.Title = "=HYPERLINK(\"http://example.com\")"
+	mockService.On("Export", mock.Anything, repoPort.ItemFilter{Status: "ACTIVE"}, mock.Anything).Return([]*domain.Item{item, createTestItem()}, nil)
+	req, _ := http.NewRequest("GET", "/items/export?status=ACTIVE", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename=itens-\d{8}-\d{6}\.csv$`, w.Header().Get("Content-Disposition"))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, exportColumns, records[0])
+	assert.Equal(t, "'=HYPERLINK(\"http://example.com\")", records[1][2])
+	mockService.AssertExpectations(t)
+}
+func TestExport_NDJSONAndXLSX(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("Export", mock.Anything, repoPort.ItemFilter{}, mock.Anything).Return([]*domain.Item{createTestItem()}, nil)
+	req, _ := http.NewRequest("GET", "/items/export?format=ndjson", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 1)
+	var item ItemResponse
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &item))
+	assert.Equal(t, int64(1), item.ID)
+	req, _ = http.NewRequest("GET", "/items/export?format=xlsx", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".xlsx")
+	_, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.NoError(t, err)
+}
+func TestExport_Errors(t *testing.T) {
+	router, mockService := setupItemTest()
+	req, _ := http.NewRequest("GET", "/items/export?format=pdf", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.On("Export", mock.Anything, repoPort.ItemFilter{}, mock.Anything).Return([]*domain.Item{createTestItem()}, errors.New("connection reset"))
+	req, _ = http.NewRequest("GET", "/items/export", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
@@ -0,0 +1,98 @@
+package http
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/gin-gonic/gin"
+)
+const dateLayout = "2006-01-02"
+// parseItemFilter reads the listing query parameters. It returns a message
+// suitable for a 400 response when a parameter is malformed.
+func parseItemFilter(c *gin.Context) (repoPort.ItemFilter, string) {
+	filter := repoPort.ItemFilter{
+		Status:     c.Query("status"),
+		Query:      strings.TrimSpace(c.Query("q")),
+		CodePrefix: strings.TrimSpace(c.Query("code_prefix")),
+	}
+	if filter.Status != "" && filter.Status != "ACTIVE" && filter.Status != "INACTIVE" {
+		return filter, "Status inválido. Use 'ACTIVE' ou 'INACTIVE'"
+	}
+	var err error
+	if filter.MinPrice, err = queryInt64(c, "min_price"); err != nil {
+		return filter, err.Error()
+	}
+	if filter.MaxPrice, err = queryInt64(c, "max_price"); err != nil {
+		return filter, err.Error()
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return filter, "O parâmetro 'min_price' não pode ser maior que 'max_price'"
+	}
+	if filter.MinStock, err = queryInt(c, "min_stock"); err != nil {
+		return filter, err.Error()
+	}
+	if filter.MaxStock, err = queryInt(c, "max_stock"); err != nil {
+		return filter, err.Error()
+	}
+	if filter.MinStock != nil && filter.MaxStock != nil && *filter.MinStock > *filter.MaxStock {
+		return filter, "O parâmetro 'min_stock' não pode ser maior que 'max_stock'"
+	}
+	if filter.CreatedBy, err = queryInt(c, "created_by"); err != nil {
+		return filter, err.Error()
+	}
+	if filter.CreatedFrom, err = queryTime(c, "created_from", false); err != nil {
+		return filter, err.Error()
+	}
+	if filter.CreatedTo, err = queryTime(c, "created_to", true); err != nil {
+		return filter, err.Error()
+	}
+	if filter.UpdatedFrom, err = queryTime(c, "updated_from", false); err != nil {
+		return filter, err.Error()
+	}
+	if filter.UpdatedTo, err = queryTime(c, "updated_to", true); err != nil {
+		return filter, err.Error()
+	}
+	if filter.Sort, err = repoPort.ParseSort(c.Query("sort")); err != nil {
+		return filter, "O parâmetro 'sort' aceita apenas id, code, title, price, stock, created_at e updated_at (prefixe com '-' para ordem decrescente)"
+	}
+	return filter, ""
+}
+func queryInt64(c *gin.Context, name string) (*int64, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("O parâmetro '%s' deve ser um número inteiro", name)
+	}
+	return &value, nil
+}
+func queryInt(c *gin.Context, name string) (*int, error) {
+	value, err := queryInt64(c, name)
+	if value == nil || err != nil {
+		return nil, err
+	}
+	converted := int(*value)
+	return &converted, nil
+}
+// queryTime accepts RFC 3339 timestamps or plain dates. A plain date used as
+// an upper bound covers the whole day, since "To" bounds are exclusive.
+func queryTime(c *gin.Context, name string, upperBound bool) (*time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	if value, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &value, nil
+	}
+	value, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		return nil, fmt.Errorf("O parâmetro '%s' deve estar no formato AAAA-MM-DD ou RFC 3339", name)
+	}
+	if upperBound {
+		value = value.AddDate(0, 0, 1)
+	}
+	return &value, nil
+}
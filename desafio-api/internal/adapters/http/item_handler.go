@@ -1,18 +1,24 @@
 package http
 import (
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"desafio-api/internal/application/service"
 	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
 	"github.com/gin-gonic/gin"
 )
 type ItemHandler struct {
 	itemService service.ItemServiceInterface
+	cursors     *CursorCodec
+	logger      *slog.Logger
 }
-func NewItemHandler(itemService service.ItemServiceInterface) *ItemHandler {
-	return &ItemHandler{itemService: itemService}
+func NewItemHandler(itemService service.ItemServiceInterface, cursors *CursorCodec, logger *slog.Logger) *ItemHandler {
+	return &ItemHandler{itemService: itemService, cursors: cursors, logger: logger}
 }
 type CreateRequest struct {
 	Code        string `json:"code" binding:"required"`
@@ -27,6 +33,7 @@ type UpdateRequest struct {
 	Description string `json:"description" binding:"required"`
 	Price       int64  `json:"price" binding:"required,gt=0"`
 	Stock       int    `json:"stock" binding:"gte=0"`
+	Version     int64  `json:"version" binding:"gte=0"`
 }
 type ItemResponse struct {
 	ID          int64  `json:"id"`
@@ -35,14 +42,20 @@ type ItemResponse struct {
 	Description string `json:"description"`
 	Price       int64  `json:"price"`
 	Stock       int    `json:"stock"`
+	Reserved    int    `json:"reserved"`
+	Available   int    `json:"available"`
 	Status      string `json:"status"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
 	CreatedBy   int    `json:"created_by"`
 	UpdatedBy   int    `json:"updated_by"`
+	Version     int64  `json:"version"`
+	DeletedAt   string `json:"deleted_at,omitempty"`
+	DeletedBy   int    `json:"deleted_by,omitempty"`
 }
 type ListResponse struct {
-	TotalPages int             `json:"totalPages"`
+	TotalPages *int            `json:"totalPages,omitempty"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 	Data       []*ItemResponse `json:"data"`
 }
 func (h *ItemHandler) Create(c *gin.Context) {
@@ -79,6 +92,7 @@ func (h *ItemHandler) Create(c *gin.Context) {
 		}
 		return
 	}
+	c.Header("ETag", itemETag(item))
 	c.JSON(http.StatusCreated, toItemResponse(item))
 }
 func (h *ItemHandler) Update(c *gin.Context) {
@@ -92,15 +106,9 @@ func (h *ItemHandler) Update(c *gin.Context) {
 		RespondWithError(c, http.StatusInternalServerError, "Falha ao identificar o usuário autenticado")
 		return
 	}
-	existingItem, err := h.itemService.GetByID(c.Request.Context(), id)
-	if err != nil {
-		switch err {
-		case domain.ErrItemNotFound:
-			RespondWithError(c, http.StatusNotFound, "Item não encontrado")
-		default:
-			log.Printf("Erro ao buscar item %d para atualização: %v", id, err)
-			RespondWithError(c, http.StatusInternalServerError, "Falha ao recuperar o item para atualização")
-		}
+	expectedVersion, hasIfMatch, ok := ifMatchVersion(c)
+	if !ok {
+		RespondWithError(c, http.StatusPreconditionFailed, "Cabeçalho If-Match inválido")
 		return
 	}
 	var req UpdateRequest
@@ -108,28 +116,95 @@ func (h *ItemHandler) Update(c *gin.Context) {
 		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
 		return
 	}
+	existingItem, ok := h.loadForUpdate(c, id, expectedVersion)
+	if !ok {
+		return
+	}
+	if !hasIfMatch && req.Version > 0 {
+		existingItem.Version = req.Version
+	}
 	existingItem.Code = req.Code
 	existingItem.Title = req.Title
 	existingItem.Description = req.Description
 	existingItem.Price = req.Price
 	existingItem.Stock = req.Stock
 	existingItem.UpdatedBy = userID.(int) 
+	h.saveUpdate(c, id, existingItem, hasIfMatch)
+}
+func (h *ItemHandler) Patch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de item inválido")
+		return
+	}
+	userID, exists := c.Get("userID")
+	if !exists {
+		RespondWithError(c, http.StatusInternalServerError, "Falha ao identificar o usuário autenticado")
+		return
+	}
+	expectedVersion, hasIfMatch, ok := ifMatchVersion(c)
+	if !ok {
+		RespondWithError(c, http.StatusPreconditionFailed, "Cabeçalho If-Match inválido")
+		return
+	}
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Falha ao ler o corpo da requisição")
+		return
+	}
+	existingItem, ok := h.loadForUpdate(c, id, expectedVersion)
+	if !ok {
+		return
+	}
+	if err := applyItemPatch(c.ContentType(), existingItem, patch); err != nil {
+		if err == errUnsupportedPatchType {
+			RespondWithError(c, http.StatusUnsupportedMediaType, "Use Content-Type application/merge-patch+json ou application/json-patch+json")
+			return
+		}
+		RespondWithError(c, http.StatusBadRequest, "Patch inválido: "+err.Error())
+		return
+	}
+	existingItem.UpdatedBy = userID.(int)
+	h.saveUpdate(c, id, existingItem, hasIfMatch)
+}
+func (h *ItemHandler) loadForUpdate(c *gin.Context, id, expectedVersion int64) (*domain.Item, bool) {
+	existingItem, err := h.itemService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		switch err {
+		case domain.ErrItemNotFound:
+			RespondWithError(c, http.StatusNotFound, "Item não encontrado")
+		default:
+			respondInternalError(c, h.logger, "Falha ao recuperar o item para atualização", err)
+		}
+		return nil, false
+	}
+	if expectedVersion > 0 && expectedVersion != existingItem.Version {
+		RespondWithError(c, http.StatusPreconditionFailed, "O item foi modificado por outra requisição")
+		return nil, false
+	}
+	return existingItem, true
+}
+func (h *ItemHandler) saveUpdate(c *gin.Context, id int64, existingItem *domain.Item, hasIfMatch bool) {
 	if err := h.itemService.Update(c.Request.Context(), id, existingItem); err != nil {
 		switch {
 		case err == domain.ErrItemNotFound:
 			RespondWithError(c, http.StatusNotFound, "Item não encontrado")
+		case err == domain.ErrVersionConflict:
+			respondVersionConflict(c, hasIfMatch)
 		case err == domain.ErrDuplicateCode:
 			RespondWithError(c, http.StatusConflict, "Já existe um item com este código")
+		case err == domain.ErrInsufficientStock:
+			RespondWithError(c, http.StatusConflict, "O estoque não pode ficar abaixo da quantidade reservada")
 		case err == domain.ErrCodeRequired || err == domain.ErrTitleRequired || 
 			err == domain.ErrDescriptionRequired || err == domain.ErrInvalidPrice || 
 			err == domain.ErrInvalidStock:
 			RespondWithError(c, http.StatusBadRequest, err.Error())
 		default:
-			log.Printf("Erro ao atualizar item %d: %v", id, err)
-			RespondWithError(c, http.StatusInternalServerError, "Falha ao atualizar o item")
+			respondInternalError(c, h.logger, "Falha ao atualizar o item", err)
 		}
 		return
 	}
+	c.Header("ETag", itemETag(existingItem))
 	c.JSON(http.StatusOK, toItemResponse(existingItem))
 }
 func (h *ItemHandler) GetByID(c *gin.Context) {
@@ -138,23 +213,40 @@ func (h *ItemHandler) GetByID(c *gin.Context) {
 		RespondWithError(c, http.StatusBadRequest, "ID de item inválido")
 		return
 	}
-	item, err := h.itemService.GetByID(c.Request.Context(), id)
+	withDeleted, ok := includeDeleted(c)
+	if !ok {
+		return
+	}
+	find := h.itemService.GetByID
+	if withDeleted {
+		find = h.itemService.GetByIDIncludingDeleted
+	}
+	item, err := find(c.Request.Context(), id)
 	if err != nil {
 		switch err {
 		case domain.ErrItemNotFound:
 			RespondWithError(c, http.StatusNotFound, "Item não encontrado")
 		default:
-			log.Printf("Erro ao buscar item %d: %v", id, err)
-			RespondWithError(c, http.StatusInternalServerError, "Falha ao buscar o item")
+			respondInternalError(c, h.logger, "Falha ao buscar o item", err)
 		}
 		return
 	}
+	etag := itemETag(item)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, toItemResponse(item))
 }
 func (h *ItemHandler) List(c *gin.Context) {
-	status := c.Query("status")
-	if status != "" && status != "ACTIVE" && status != "INACTIVE" {
-		RespondWithError(c, http.StatusBadRequest, "Status inválido. Use 'ACTIVE' ou 'INACTIVE'")
+	filter, message := parseItemFilter(c)
+	if message != "" {
+		RespondWithError(c, http.StatusBadRequest, message)
+		return
+	}
+	var ok bool
+	if filter.IncludeDeleted, ok = includeDeleted(c); !ok {
 		return
 	}
 	limitStr := c.DefaultQuery("limit", "10")
@@ -163,17 +255,19 @@ func (h *ItemHandler) List(c *gin.Context) {
 		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'limit' deve ser um número entre 1 e 100")
 		return
 	}
+	if cursor, cursorMode := c.GetQuery("cursor"); cursorMode {
+		h.listByCursor(c, filter, cursor, limit)
+		return
+	}
 	pageStr := c.DefaultQuery("page", "1")
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
 		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'page' deve ser um número maior que zero")
 		return
 	}
-	offset := (page - 1) * limit
-	items, total, err := h.itemService.List(c.Request.Context(), status, limit, offset)
+	items, total, err := h.itemService.List(c.Request.Context(), filter, page, limit)
 	if err != nil {
-		log.Printf("Erro ao listar itens: %v", err)
-		RespondWithError(c, http.StatusInternalServerError, "Falha ao recuperar a lista de itens")
+		respondInternalError(c, h.logger, "Falha ao recuperar a lista de itens", err)
 		return
 	}
 	totalPages := 0
@@ -181,36 +275,138 @@ func (h *ItemHandler) List(c *gin.Context) {
 		totalPages = (total + limit - 1) / limit
 	}
 	response := ListResponse{
-		TotalPages: totalPages,
-		Data:       make([]*ItemResponse, 0, len(items)),
+		TotalPages: &totalPages,
+		Data:       toItemResponses(items),
 	}
-	for _, item := range items {
-		response.Data = append(response.Data, toItemResponse(item))
+	links := []string{pageLink(c, "first", "page", "1")}
+	if page > 1 {
+		links = append(links, pageLink(c, "prev", "page", strconv.Itoa(page-1)))
+	}
+	if page < totalPages {
+		links = append(links, pageLink(c, "next", "page", strconv.Itoa(page+1)))
 	}
+	if totalPages > 0 {
+		links = append(links, pageLink(c, "last", "page", strconv.Itoa(totalPages)))
+	}
+	c.Header("Link", strings.Join(links, ", "))
 	c.Header("X-Total-Count", strconv.Itoa(total))
 	c.Header("X-Page", strconv.Itoa(page))
 	c.Header("X-Per-Page", strconv.Itoa(limit))
 	c.Header("X-Total-Pages", strconv.Itoa(totalPages))
 	c.JSON(http.StatusOK, response)
 }
+// listByCursor serves keyset pages: no COUNT is run and an empty cursor
+// starts from the most recently updated item.
+func (h *ItemHandler) listByCursor(c *gin.Context, filter repoPort.ItemFilter, cursor string, limit int) {
+	if len(filter.Sort) > 0 {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'sort' não pode ser combinado com 'cursor'")
+		return
+	}
+	var after *repoPort.ItemKey
+	if cursor != "" {
+		key, err := h.cursors.Decode(cursor)
+		if err != nil {
+			RespondWithError(c, http.StatusBadRequest, "Cursor inválido ou expirado")
+			return
+		}
+		after = key
+	}
+	items, next, err := h.itemService.ListAfter(c.Request.Context(), filter, after, limit)
+	if err != nil {
+		respondInternalError(c, h.logger, "Falha ao recuperar a lista de itens", err)
+		return
+	}
+	response := ListResponse{Data: toItemResponses(items)}
+	if next != nil {
+		response.NextCursor = h.cursors.Encode(*next)
+		c.Header("Link", pageLink(c, "next", "cursor", response.NextCursor))
+	}
+	c.Header("X-Per-Page", strconv.Itoa(limit))
+	c.JSON(http.StatusOK, response)
+}
+func toItemResponses(items []*domain.Item) []*ItemResponse {
+	responses := make([]*ItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, toItemResponse(item))
+	}
+	return responses
+}
+// pageLink formats an RFC 8288 link to the current request with one query
+// parameter replaced; "page" and "cursor" never appear together.
+func pageLink(c *gin.Context, rel, param, value string) string {
+	target := *c.Request.URL
+	query := target.Query()
+	query.Del("page")
+	query.Del("cursor")
+	query.Set(param, value)
+	target.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=\"%s\"", target.RequestURI(), rel)
+}
 func (h *ItemHandler) Delete(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		RespondWithError(c, http.StatusBadRequest, "ID de item inválido")
 		return
 	}
-	if err := h.itemService.Delete(c.Request.Context(), id); err != nil {
+	expectedVersion, hasIfMatch, ok := ifMatchVersion(c)
+	if !ok {
+		RespondWithError(c, http.StatusPreconditionFailed, "Cabeçalho If-Match inválido")
+		return
+	}
+	if err := h.itemService.Delete(c.Request.Context(), id, expectedVersion); err != nil {
 		switch err {
 		case domain.ErrItemNotFound:
 			RespondWithError(c, http.StatusNotFound, "Item não encontrado")
+		case domain.ErrVersionConflict:
+			respondVersionConflict(c, hasIfMatch)
+		case domain.ErrItemHasReservations:
+			RespondWithError(c, http.StatusConflict, "O item possui reservas de estoque pendentes; confirme-as ou libere-as antes de excluí-lo")
 		default:
-			log.Printf("Erro ao remover item %d: %v", id, err)
-			RespondWithError(c, http.StatusInternalServerError, "Falha ao remover o item")
+			respondInternalError(c, h.logger, "Falha ao remover o item", err)
 		}
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
+func (h *ItemHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de item inválido")
+		return
+	}
+	item, err := h.itemService.Restore(c.Request.Context(), id)
+	if err != nil {
+		switch err {
+		case domain.ErrItemNotFound:
+			RespondWithError(c, http.StatusNotFound, "Item não encontrado")
+		case domain.ErrItemNotDeleted:
+			RespondWithError(c, http.StatusConflict, "O item não está excluído")
+		default:
+			respondInternalError(c, h.logger, "Falha ao restaurar o item", err)
+		}
+		return
+	}
+	c.Header("ETag", itemETag(item))
+	c.JSON(http.StatusOK, toItemResponse(item))
+}
+// includeDeleted reads ?include_deleted, which only admins may set. It
+// responds and returns false when the parameter is invalid or not allowed.
+func includeDeleted(c *gin.Context) (bool, bool) {
+	value := c.Query("include_deleted")
+	if value == "" {
+		return false, true
+	}
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'include_deleted' deve ser true ou false")
+		return false, false
+	}
+	if include && c.GetString("role") != domain.RoleAdmin {
+		RespondWithError(c, http.StatusForbidden, "Apenas administradores podem consultar itens excluídos")
+		return false, false
+	}
+	return include, true
+}
 func toItemResponse(item *domain.Item) *ItemResponse {
 	if item == nil {
 		return nil
@@ -222,9 +418,12 @@ func toItemResponse(item *domain.Item) *ItemResponse {
 		Description: item.Description,
 		Price:       item.Price,
 		Stock:       item.Stock,
+		Reserved:    item.Reserved,
+		Available:   item.Available(),
 		Status:      item.Status,
 		CreatedBy:   item.CreatedBy,
 		UpdatedBy:   item.UpdatedBy,
+		Version:     item.Version,
 	}
 	if !item.CreatedAt.IsZero() {
 		response.CreatedAt = item.CreatedAt.Format(time.RFC3339)
@@ -232,5 +431,38 @@ func toItemResponse(item *domain.Item) *ItemResponse {
 	if !item.UpdatedAt.IsZero() {
 		response.UpdatedAt = item.UpdatedAt.Format(time.RFC3339)
 	}
+	if item.DeletedAt != nil {
+		response.DeletedAt = item.DeletedAt.Format(time.RFC3339)
+		response.DeletedBy = item.DeletedBy
+	}
 	return response
 }
+func itemETag(item *domain.Item) string {
+	return fmt.Sprintf("\"%d\"", item.Version)
+}
+// ifMatchVersion reads the expected item version from If-Match. "*" and an
+// absent header impose no version; weak or malformed tags never match.
+func ifMatchVersion(c *gin.Context) (int64, bool, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, false, true
+	}
+	if header == "*" {
+		return 0, true, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, true, false
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, true, false
+	}
+	return version, true, true
+}
+func respondVersionConflict(c *gin.Context, hasIfMatch bool) {
+	if hasIfMatch {
+		RespondWithError(c, http.StatusPreconditionFailed, "O item foi modificado por outra requisição")
+		return
+	}
+	RespondWithError(c, http.StatusConflict, "O item foi modificado por outra requisição")
+}
@@ -8,7 +8,10 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/application/service"
 	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -16,9 +19,15 @@ import (
 type ItemServiceInterface interface {
 	Create(ctx context.Context, item *domain.Item) error
 	GetByID(ctx context.Context, id int64) (*domain.Item, error)
+	GetByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Item, error)
 	Update(ctx context.Context, id int64, item *domain.Item) error
-	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, status string, page, perPage int) ([]*domain.Item, int, error)
+	Delete(ctx context.Context, id int64, expectedVersion int64) error
+	Restore(ctx context.Context, id int64) (*domain.Item, error)
+	List(ctx context.Context, filter repoPort.ItemFilter, page, perPage int) ([]*domain.Item, int, error)
+	ListAfter(ctx context.Context, filter repoPort.ItemFilter, after *repoPort.ItemKey, limit int) ([]*domain.Item, *repoPort.ItemKey, error)
+	Export(ctx context.Context, filter repoPort.ItemFilter, fn func(*domain.Item) error) error
+	Import(ctx context.Context, rows []service.ImportRow, opts service.ImportOptions) (*service.ImportReport, error)
+	History(ctx context.Context, itemID int64, page, limit int) ([]*domain.ItemRevision, int, error)
 }
 type MockItemService struct {
 	mock.Mock
@@ -38,19 +47,67 @@ func (m *MockItemService) Update(ctx context.Context, id int64, item *domain.Ite
 	args := m.Called(ctx, id, item)
 	return args.Error(0)
 }
-func (m *MockItemService) Delete(ctx context.Context, id int64) error {
-	args := m.Called(ctx, id)
+func (m *MockItemService) Delete(ctx context.Context, id int64, expectedVersion int64) error {
+	args := m.Called(ctx, id, expectedVersion)
 	return args.Error(0)
 }
-func (m *MockItemService) List(ctx context.Context, status string, page, perPage int) ([]*domain.Item, int, error) {
-	args := m.Called(ctx, status, page, perPage)
+func (m *MockItemService) List(ctx context.Context, filter repoPort.ItemFilter, page, perPage int) ([]*domain.Item, int, error) {
+	args := m.Called(ctx, filter, page, perPage)
 	if args.Get(0) == nil {
 		return nil, 0, args.Error(2)
 	}
 	return args.Get(0).([]*domain.Item), args.Int(1), args.Error(2)
 }
+func (m *MockItemService) ListAfter(ctx context.Context, filter repoPort.ItemFilter, after *repoPort.ItemKey, limit int) ([]*domain.Item, *repoPort.ItemKey, error) {
+	args := m.Called(ctx, filter, after, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	next, _ := args.Get(1).(*repoPort.ItemKey)
+	return args.Get(0).([]*domain.Item), next, args.Error(2)
+}
+func (m *MockItemService) Import(ctx context.Context, rows []service.ImportRow, opts service.ImportOptions) (*service.ImportReport, error) {
+	args := m.Called(ctx, rows, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ImportReport), args.Error(1)
+}
+func (m *MockItemService) Export(ctx context.Context, filter repoPort.ItemFilter, fn func(*domain.Item) error) error {
+	args := m.Called(ctx, filter, fn)
+	if items, ok := args.Get(0).([]*domain.Item); ok {
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+func (m *MockItemService) GetByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Item, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Item), args.Error(1)
+}
+func (m *MockItemService) Restore(ctx context.Context, id int64) (*domain.Item, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Item), args.Error(1)
+}
+func (m *MockItemService) History(ctx context.Context, itemID int64, page, limit int) ([]*domain.ItemRevision, int, error) {
+	args := m.Called(ctx, itemID, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.ItemRevision), args.Int(1), args.Error(2)
+}
+var testCursors = NewCursorCodec([]byte("test-cursor-secret"))
 func NewItemHandlerWithInterface(service ItemServiceInterface) *ItemHandler {
-	return &ItemHandler{itemService: service}
+	return NewItemHandler(service, testCursors, logging.Nop())
 }
 func setupItemTest() (*gin.Engine, *MockItemService) {
 	gin.SetMode(gin.TestMode)
@@ -63,10 +120,15 @@ func setupItemTest() (*gin.Engine, *MockItemService) {
 		c.Next()
 	})
 	router.POST("/items", handler.Create)
+	router.POST("/items/import", handler.Import)
 	router.GET("/items", handler.List)
+	router.GET("/items/export", handler.Export)
 	router.GET("/items/:id", handler.GetByID)
 	router.PUT("/items/:id", handler.Update)
+	router.PATCH("/items/:id", handler.Patch)
 	router.DELETE("/items/:id", handler.Delete)
+	router.GET("/items/:id/history", handler.History)
+	router.POST("/items/:id/restore", handler.Restore)
 	return router, mockService
 }
 func createTestItem() *domain.Item {
@@ -252,7 +314,7 @@ func TestList_Success(t *testing.T) {
 	router, mockService := setupItemTest()
 	items := []*domain.Item{createTestItem()}
 	totalPages := 1
-	mockService.On("List", mock.Anything, "", 1, 10).Return(items, totalPages, nil)
+	mockService.On("List", mock.Anything, repoPort.ItemFilter{}, 1, 10).Return(items, totalPages, nil)
 	req, _ := http.NewRequest("GET", "/items?page=1&per_page=10", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -270,7 +332,7 @@ func TestList_Success(t *testing.T) {
 }
 func TestDelete_Success(t *testing.T) {
 	router, mockService := setupItemTest()
-	mockService.On("Delete", mock.Anything, int64(1)).Return(nil)
+	mockService.On("Delete", mock.Anything, int64(1), int64(0)).Return(nil)
 	req, _ := http.NewRequest("DELETE", "/items/1", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -279,10 +341,370 @@ func TestDelete_Success(t *testing.T) {
 }
 func TestDelete_NotFound(t *testing.T) {
 	router, mockService := setupItemTest()
-	mockService.On("Delete", mock.Anything, int64(999)).Return(domain.ErrItemNotFound)
+	mockService.On("Delete", mock.Anything, int64(999), int64(0)).Return(domain.ErrItemNotFound)
 	req, _ := http.NewRequest("DELETE", "/items/999", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	mockService.AssertExpectations(t)
 }
+func TestGetByID_SetsETag(t *testing.T) {
+	router, mockService := setupItemTest()
+	item := createTestItem()
+	item.Version = 3
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(item, nil)
+	req, _ := http.NewRequest("GET", "/items/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	req, _ = http.NewRequest("GET", "/items/1", nil)
+	req.Header.Set("If-None-Match", `"3"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+func TestUpdate_IfMatchMismatch(t *testing.T) {
+	router, mockService := setupItemTest()
+	item := createTestItem()
+	item.Version = 3
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(item, nil)
+	reqBody := map[string]interface{}{
+		"code":        "TEST001",
+		"title":       "Item Atualizado",
+		"description": "Descrição atualizada",
+		"price":       2000,
+		"stock":       15,
+	}
+	jsonData, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("PUT", "/items/1", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+func TestUpdate_IfMatchSuccess(t *testing.T) {
+	router, mockService := setupItemTest()
+	item := createTestItem()
+	item.Version = 3
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(item, nil)
+	mockService.On("Update", mock.Anything, int64(1), mock.MatchedBy(func(item *domain.Item) bool {
+		return item.Version == 3
+	})).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Item).Version = 4
+	}).Return(nil)
+	reqBody := map[string]interface{}{
+		"code":        "TEST001",
+		"title":       "Item Atualizado",
+		"description": "Descrição atualizada",
+		"price":       2000,
+		"stock":       15,
+	}
+	jsonData, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("PUT", "/items/1", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"3"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
+func TestUpdate_ConcurrentModification(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(createTestItem(), nil)
+	mockService.On("Update", mock.Anything, int64(1), mock.Anything).Return(domain.ErrVersionConflict)
+	reqBody := map[string]interface{}{
+		"code":        "TEST001",
+		"title":       "Item Atualizado",
+		"description": "Descrição atualizada",
+		"price":       2000,
+		"stock":       15,
+	}
+	jsonData, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest("PUT", "/items/1", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+func TestDelete_IfMatch(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("Delete", mock.Anything, int64(1), int64(2)).Return(domain.ErrVersionConflict)
+	req, _ := http.NewRequest("DELETE", "/items/1", nil)
+	req.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	req, _ = http.NewRequest("DELETE", "/items/1", nil)
+	req.Header.Set("If-Match", `W/"2"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockService.AssertNumberOfCalls(t, "Delete", 1)
+}
+func TestPatch_MergePatch(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(createTestItem(), nil)
+	mockService.On("Update", mock.Anything, int64(1), mock.MatchedBy(func(item *domain.Item) bool {
+		return item.Stock == 3 && item.Code == "TEST001" && item.Title == "Item de Teste" && item.Price == 1500
+	})).Return(nil)
+	req, _ := http.NewRequest("PATCH", "/items/1", bytes.NewBufferString(`{"stock": 3}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(3), response["stock"])
+	mockService.AssertExpectations(t)
+}
+func TestPatch_JSONPatch(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(createTestItem(), nil)
+	mockService.On("Update", mock.Anything, int64(1), mock.MatchedBy(func(item *domain.Item) bool {
+		return item.Price == 2500 && item.Title == "Item de Teste"
+	})).Return(nil)
+	body := `[{"op": "test", "path": "/price", "value": 1500}, {"op": "replace", "path": "/price", "value": 2500}]`
+	req, _ := http.NewRequest("PATCH", "/items/1", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+func TestPatch_RejectsReadOnlyField(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(createTestItem(), nil)
+	req, _ := http.NewRequest("PATCH", "/items/1", bytes.NewBufferString(`{"status": "ACTIVE"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+func TestPatch_ValidationError(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(createTestItem(), nil)
+	mockService.On("Update", mock.Anything, int64(1), mock.Anything).Return(domain.ErrCodeRequired)
+	req, _ := http.NewRequest("PATCH", "/items/1", bytes.NewBufferString(`{"code": ""}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+func TestPatch_RejectsNullFields(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(createTestItem(), nil)
+	patches := map[string]string{
+		`{"stock": null}`:                                      "application/merge-patch+json",
+		`{"price": null, "title": "Outro"}`:                    "application/merge-patch+json",
+		`[{"op": "remove", "path": "/stock"}]`:                 "application/json-patch+json",
+		`[{"op": "replace", "path": "/price", "value": null}]`: "application/json-patch+json",
+	}
+	for body, contentType := range patches {
+		req, _ := http.NewRequest("PATCH", "/items/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+func TestPatch_UnsupportedMediaType(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(createTestItem(), nil)
+	req, _ := http.NewRequest("PATCH", "/items/1", bytes.NewBufferString(`stock=3`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+func TestList_Filters(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("List", mock.Anything, mock.MatchedBy(func(filter repoPort.ItemFilter) bool {
+		return filter.Query == "caneta azul" &&
+			*filter.MinPrice == 100 && *filter.MaxPrice == 500 &&
+			filter.CodePrefix == "CAN-" &&
+			*filter.CreatedBy == 3 &&
+			filter.CreatedTo.Equal(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)) &&
+			len(filter.Sort) == 2 && filter.Sort[0] == repoPort.SortField{Field: "price", Desc: true} &&
+			filter.Sort[1] == repoPort.SortField{Field: "title"}
+	}), 1, 10).Return([]*domain.Item{}, 0, nil)
+	req, _ := http.NewRequest("GET", "/items?q=caneta+azul&min_price=100&max_price=500&code_prefix=CAN-&created_by=3&created_to=2024-01-30&sort=-price,title", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+func TestList_InvalidFilters(t *testing.T) {
+	router, _ := setupItemTest()
+	for _, query := range []string{"sort=password", "min_price=abc", "min_stock=5&max_stock=1", "updated_from=ontem"} {
+		req, _ := http.NewRequest("GET", "/items?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+func TestList_PageLinks(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("List", mock.Anything, repoPort.ItemFilter{Status: "ACTIVE"}, 2, 10).Return([]*domain.Item{createTestItem()}, 35, nil)
+	req, _ := http.NewRequest("GET", "/items?status=ACTIVE&page=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `</items?page=1&status=ACTIVE>; rel="first", </items?page=1&status=ACTIVE>; rel="prev", </items?page=3&status=ACTIVE>; rel="next", </items?page=4&status=ACTIVE>; rel="last"`, w.Header().Get("Link"))
+	mockService.AssertExpectations(t)
+}
+func TestList_CursorMode(t *testing.T) {
+	router, mockService := setupItemTest()
+	item := createTestItem()
+	next := repoPort.KeyOf(item)
+	mockService.On("ListAfter", mock.Anything, repoPort.ItemFilter{}, (*repoPort.ItemKey)(nil), 1).Return([]*domain.Item{item}, &next, nil)
+	req, _ := http.NewRequest("GET", "/items?cursor=&limit=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response ListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Nil(t, response.TotalPages)
+	assert.Len(t, response.Data, 1)
+	assert.NotEmpty(t, response.NextCursor)
+	assert.Equal(t, `</items?cursor=`+response.NextCursor+`&limit=1>; rel="next"`, w.Header().Get("Link"))
+	assert.Empty(t, w.Header().Get("X-Total-Count"))
+	decoded, err := testCursors.Decode(response.NextCursor)
+	assert.NoError(t, err)
+	mockService.On("ListAfter", mock.Anything, repoPort.ItemFilter{}, decoded, 1).Return([]*domain.Item{}, nil, nil)
+	req, _ = http.NewRequest("GET", "/items?limit=1&cursor="+response.NextCursor, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Link"))
+	assert.NotContains(t, w.Body.String(), "next_cursor")
+	mockService.AssertExpectations(t)
+}
+func TestList_CursorRejected(t *testing.T) {
+	router, mockService := setupItemTest()
+	forged := NewCursorCodec([]byte("other-secret")).Encode(repoPort.ItemKey{UpdatedAt: time.Now(), ID: 1})
+	for _, query := range []string{"cursor=" + forged, "cursor=not-a-cursor", "cursor=&sort=price"} {
+		req, _ := http.NewRequest("GET", "/items?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockService.AssertNotCalled(t, "ListAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+func TestHistory_Success(t *testing.T) {
+	router, mockService := setupItemTest()
+	revision, err := domain.NewItemRevision(domain.RevisionUpdate, createTestItem(), &domain.Item{ID: 1, Code: "TEST001", Title: "Novo título", Description: "Descrição do item de teste", Price: 1500, Stock: 10, Status: "ACTIVE"})
+	assert.NoError(t, err)
+	revision.ID = 3
+	revision.ActorID = 1
+	revision.RequestID = "req-1"
+	revision.CreatedAt = time.Now()
+	mockService.On("History", mock.Anything, int64(1), 2, 1).Return([]*domain.ItemRevision{revision}, 3, nil)
+	req, _ := http.NewRequest("GET", "/items/1/history?page=2&limit=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "3", w.Header().Get("X-Total-Count"))
+	var response struct {
+		TotalPages int `json:"totalPages"`
+		Data       []struct {
+			Action    string                        `json:"action"`
+			ActorID   int                           `json:"actor_id"`
+			RequestID string                        `json:"request_id"`
+			Snapshot  map[string]interface{}        `json:"snapshot"`
+			Changes   map[string]domain.FieldChange `json:"changes"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.TotalPages)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, domain.RevisionUpdate, response.Data[0].Action)
+	assert.Equal(t, "req-1", response.Data[0].RequestID)
+	assert.Equal(t, "Novo título", response.Data[0].Snapshot["title"])
+	assert.Equal(t, domain.FieldChange{From: "Item de Teste", To: "Novo título"}, response.Data[0].Changes["title"])
+	assert.Len(t, response.Data[0].Changes, 1)
+	mockService.AssertExpectations(t)
+}
+func TestHistory_NotFoundAndInvalidParams(t *testing.T) {
+	router, mockService := setupItemTest()
+	mockService.On("History", mock.Anything, int64(99), 1, 20).Return(nil, 0, domain.ErrItemNotFound)
+	for path, status := range map[string]int{
+		"/items/99/history":         http.StatusNotFound,
+		"/items/abc/history":        http.StatusBadRequest,
+		"/items/1/history?limit=0":  http.StatusBadRequest,
+		"/items/1/history?page=abc": http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+	mockService.AssertExpectations(t)
+}
+func TestRestore(t *testing.T) {
+	router, mockService := setupItemTest()
+	item := createTestItem()
+	item.Version = 3
+	mockService.On("Restore", mock.Anything, int64(1)).Return(item, nil)
+	mockService.On("Restore", mock.Anything, int64(2)).Return(nil, domain.ErrItemNotDeleted)
+	mockService.On("Restore", mock.Anything, int64(3)).Return(nil, domain.ErrItemNotFound)
+	for path, status := range map[string]int{
+		"/items/1/restore":   http.StatusOK,
+		"/items/2/restore":   http.StatusConflict,
+		"/items/3/restore":   http.StatusNotFound,
+		"/items/abc/restore": http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("POST", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+		if status == http.StatusOK {
+			assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+		}
+	}
+	mockService.AssertExpectations(t)
+}
+func TestIncludeDeleted_OnlyForAdmins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockItemService)
+	handler := NewItemHandlerWithInterface(mockService)
+	role := domain.RoleEditor
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("role", role)
+		c.Next()
+	})
+	router.GET("/items", handler.List)
+	router.GET("/items/:id", handler.GetByID)
+	deleted := createTestItem()
+	deletedAt := time.Now()
+	deleted.DeletedAt = &deletedAt
+	deleted.DeletedBy = 1
+	mockService.On("GetByIDIncludingDeleted", mock.Anything, int64(1)).Return(deleted, nil)
+	mockService.On("List", mock.Anything, repoPort.ItemFilter{IncludeDeleted: true}, 1, 10).Return([]*domain.Item{deleted}, 1, nil)
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusForbidden, get("/items/1?include_deleted=true").Code)
+	assert.Equal(t, http.StatusForbidden, get("/items?include_deleted=true").Code)
+	role = domain.RoleAdmin
+	assert.Equal(t, http.StatusBadRequest, get("/items?include_deleted=talvez").Code)
+	w := get("/items/1?include_deleted=true")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response ItemResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.DeletedAt)
+	assert.Equal(t, 1, response.DeletedBy)
+	assert.Equal(t, http.StatusOK, get("/items?include_deleted=true").Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package http
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+type RevisionResponse struct {
+	ID        int64           `json:"id"`
+	Action    string          `json:"action"`
+	ActorID   int             `json:"actor_id"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt string          `json:"created_at"`
+	Snapshot  json.RawMessage `json:"snapshot"`
+	Changes   json.RawMessage `json:"changes"`
+}
+type HistoryResponse struct {
+	TotalPages int                 `json:"totalPages"`
+	Data       []*RevisionResponse `json:"data"`
+}
+// History lists the revisions of an item, newest first. It keeps answering
+// after the item is deleted so the audit trail stays reachable.
+func (h *ItemHandler) History(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de item inválido")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'limit' deve ser um número entre 1 e 100")
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'page' deve ser um número maior que zero")
+		return
+	}
+	revisions, total, err := h.itemService.History(c.Request.Context(), id, page, limit)
+	if err != nil {
+		switch err {
+		case domain.ErrItemNotFound:
+			RespondWithError(c, http.StatusNotFound, "Item não encontrado")
+		default:
+			respondInternalError(c, h.logger, "Falha ao buscar o histórico do item", err)
+		}
+		return
+	}
+	response := HistoryResponse{TotalPages: (total + limit - 1) / limit, Data: make([]*RevisionResponse, 0, len(revisions))}
+	for _, revision := range revisions {
+		response.Data = append(response.Data, &RevisionResponse{
+			ID:        revision.ID,
+			Action:    revision.Action,
+			ActorID:   revision.ActorID,
+			RequestID: revision.RequestID,
+			CreatedAt: revision.CreatedAt.Format(time.RFC3339),
+			Snapshot:  json.RawMessage(revision.Snapshot),
+			Changes:   json.RawMessage(revision.Changes),
+		})
+	}
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.JSON(http.StatusOK, response)
+}
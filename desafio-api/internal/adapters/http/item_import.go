@@ -0,0 +1,255 @@
+package http
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 10000
+)
+var (
+	errUnsupportedImportType = errors.New("unsupported import media type")
+	errTooManyImportRows     = errors.New("too many import rows")
+	importColumns            = []string{"code", "title", "description", "price", "stock"}
+)
+// importRowError is a parse failure already worded for the client.
+type importRowError struct {
+	message string
+}
+func (e *importRowError) Error() string {
+	return e.message
+}
+type ImportRowResponse struct {
+	Line   int    `json:"line"`
+	Code   string `json:"code,omitempty"`
+	Action string `json:"action,omitempty"`
+	ItemID int64  `json:"item_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+type ImportResponse struct {
+	DryRun  bool                 `json:"dry_run"`
+	Upsert  bool                 `json:"upsert"`
+	Atomic  bool                 `json:"atomic"`
+	Applied bool                 `json:"applied"`
+	Total   int                  `json:"total"`
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Failed  int                  `json:"failed"`
+	Rows    []*ImportRowResponse `json:"rows"`
+}
+func (h *ItemHandler) Import(c *gin.Context) {
+	opts, rows, ok := parseImportRequest(c)
+	if !ok {
+		return
+	}
+	report, err := h.itemService.Import(c.Request.Context(), rows, opts)
+	if err != nil {
+		respondInternalError(c, h.logger, "Falha ao importar os itens", err)
+		return
+	}
+	status := http.StatusOK
+	if opts.Atomic && report.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, newImportResponse(opts, report))
+}
+// parseImportRequest reads the import options and file, responding with the
+// appropriate error when either is invalid.
+func parseImportRequest(c *gin.Context) (service.ImportOptions, []service.ImportRow, bool) {
+	var opts service.ImportOptions
+	for name, target := range map[string]*bool{"dry_run": &opts.DryRun, "upsert": &opts.Upsert, "atomic": &opts.Atomic} {
+		value, err := strconv.ParseBool(c.DefaultQuery(name, "false"))
+		if err != nil {
+			RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("O parâmetro '%s' deve ser true ou false", name))
+			return opts, nil, false
+		}
+		*target = value
+	}
+	rows, err := parseImport(c.ContentType(), http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case err == errUnsupportedImportType:
+			RespondWithError(c, http.StatusUnsupportedMediaType, "Use Content-Type text/csv ou application/x-ndjson")
+		case err == errTooManyImportRows, errors.As(err, &maxBytesErr):
+			RespondWithError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("O arquivo deve ter no máximo %d linhas e 10 MB", maxImportRows))
+		default:
+			RespondWithError(c, http.StatusBadRequest, "Arquivo inválido: "+err.Error())
+		}
+		return opts, nil, false
+	}
+	if len(rows) == 0 {
+		RespondWithError(c, http.StatusBadRequest, "O arquivo não contém itens")
+		return opts, nil, false
+	}
+	return opts, rows, true
+}
+func newImportResponse(opts service.ImportOptions, report *service.ImportReport) *ImportResponse {
+	response := &ImportResponse{
+		DryRun:  opts.DryRun,
+		Upsert:  opts.Upsert,
+		Atomic:  opts.Atomic,
+		Applied: report.Applied,
+		Total:   report.Total,
+		Created: report.Created,
+		Updated: report.Updated,
+		Failed:  report.Failed,
+		Rows:    make([]*ImportRowResponse, 0, len(report.Rows)),
+	}
+	for _, row := range report.Rows {
+		response.Rows = append(response.Rows, &ImportRowResponse{
+			Line:   row.Line,
+			Code:   row.Code,
+			Action: string(row.Action),
+			ItemID: row.ItemID,
+			Error:  importErrorMessage(row.Err),
+		})
+	}
+	return response
+}
+func importErrorMessage(err error) string {
+	var rowErr *importRowError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &rowErr):
+		return rowErr.message
+	case err == domain.ErrDuplicateCode:
+		return "Já existe um item com este código"
+	case err == domain.ErrDuplicateImportCode:
+		return "Código repetido no arquivo"
+	case err == domain.ErrInsufficientStock:
+		return "O estoque não pode ficar abaixo da quantidade reservada"
+	case err == domain.ErrVersionConflict:
+		return "O item foi modificado por outra requisição"
+	default:
+		return err.Error()
+	}
+}
+func parseImport(contentType string, body io.Reader) ([]service.ImportRow, error) {
+	switch contentType {
+	case "text/csv":
+		return parseCSVImport(body)
+	case "application/x-ndjson", "application/jsonl":
+		return parseNDJSONImport(body)
+	default:
+		return nil, errUnsupportedImportType
+	}
+}
+// parseCSVImport expects a header naming the columns; "stock" may be
+// omitted and defaults to zero.
+func parseCSVImport(body io.Reader) ([]service.ImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !containsString(importColumns, name) {
+			return nil, fmt.Errorf("coluna desconhecida %q", name)
+		}
+		if _, duplicated := positions[name]; duplicated {
+			return nil, fmt.Errorf("coluna %q repetida", name)
+		}
+		positions[name] = i
+	}
+	for _, name := range importColumns[:4] {
+		if _, ok := positions[name]; !ok {
+			return nil, fmt.Errorf("coluna obrigatória %q ausente", name)
+		}
+	}
+	var rows []service.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if len(rows) == maxImportRows {
+			return nil, errTooManyImportRows
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, service.ImportRow{Line: parseErr.StartLine, Err: &importRowError{"CSV inválido: " + parseErr.Err.Error()}})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			rows = append(rows, service.ImportRow{Line: line, Err: &importRowError{fmt.Sprintf("esperadas %d colunas, encontradas %d", len(header), len(record))}})
+			continue
+		}
+		field := func(name string) string {
+			if i, ok := positions[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		item := &domain.Item{Code: field("code"), Title: field("title"), Description: field("description")}
+		row := service.ImportRow{Line: line, Item: item}
+		if item.Price, err = strconv.ParseInt(field("price"), 10, 64); err != nil {
+			row.Err = &importRowError{"preço deve ser um número inteiro em centavos"}
+		} else if stock := field("stock"); stock != "" {
+			if item.Stock, err = strconv.Atoi(stock); err != nil {
+				row.Err = &importRowError{"estoque deve ser um número inteiro"}
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+func parseNDJSONImport(body io.Reader) ([]service.ImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	var rows []service.ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, errTooManyImportRows
+		}
+		var document itemPatchDocument
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&document); err != nil {
+			rows = append(rows, service.ImportRow{Line: line, Err: &importRowError{"JSON inválido: " + err.Error()}})
+			continue
+		}
+		rows = append(rows, service.ImportRow{Line: line, Item: &domain.Item{
+			Code:        strings.TrimSpace(document.Code),
+			Title:       document.Title,
+			Description: document.Description,
+			Price:       document.Price,
+			Stock:       document.Stock,
+		}})
+	}
+	return rows, scanner.Err()
+}
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package http
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+func TestParseCSVImport(t *testing.T) {
+	body := "\ufeffCode,title,description,price,stock\n" +
+		"CAN-001,Caneta,Caneta azul,150,10\n" +
+		"CAN-002,Lápis,\"Lápis, preto\",abc,1\n" +
+		"CAN-003,Borracha\n" +
+		"CAN-004,Régua,Régua 30cm,300,\n"
+	rows, err := parseCSVImport(strings.NewReader(body))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, service.ImportRow{Line: 2, Item: &domain.Item{Code: "CAN-001", Title: "Caneta", Description: "Caneta azul", Price: 150, Stock: 10}}, rows[0])
+	assert.Equal(t, 3, rows[1].Line)
+	assert.EqualError(t, rows[1].Err, "preço deve ser um número inteiro em centavos")
+	assert.EqualError(t, rows[2].Err, "esperadas 5 colunas, encontradas 2")
+	assert.NoError(t, rows[3].Err)
+	assert.Equal(t, 0, rows[3].Item.Stock)
+	_, err = parseCSVImport(strings.NewReader("code,title,price\n"))
+	assert.EqualError(t, err, `coluna obrigatória "description" ausente`)
+	_, err = parseCSVImport(strings.NewReader("code,title,description,price,color\n"))
+	assert.EqualError(t, err, `coluna desconhecida "color"`)
+}
+func TestParseNDJSONImport(t *testing.T) {
+	body := `{"code":"CAN-001","title":"Caneta","description":"Caneta azul","price":150,"stock":10}` + "\n\n" +
+		`{"code":"CAN-002","color":"azul"}` + "\n" +
+		`not json` + "\n"
+	rows, err := parseNDJSONImport(strings.NewReader(body))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "CAN-001", rows[0].Item.Code)
+	assert.Equal(t, 3, rows[1].Line)
+	assert.Error(t, rows[1].Err)
+	assert.Equal(t, 4, rows[2].Line)
+	assert.Error(t, rows[2].Err)
+}
+func TestImport_Report(t *testing.T) {
+	router, mockService := setupItemTest()
+	report := &service.ImportReport{
+		Total:   2,
+		Created: 1,
+		Failed:  1,
+		Rows: []service.ImportRowResult{
+			{Line: 2, Code: "CAN-001", Action: service.ImportActionCreate, ItemID: 7},
+			{Line: 3, Code: "CAN-002", Err: domain.ErrDuplicateCode},
+		},
+	}
+	mockService.On("Import", mock.Anything, mock.AnythingOfType("[]service.ImportRow"), service.ImportOptions{Atomic: true}).Return(report, nil)
+	body := "code,title,description,price,stock\nCAN-001,Caneta,Caneta azul,150,10\nCAN-002,Lápis,Lápis preto,90,1\n"
+	req, _ := http.NewRequest("POST", "/items/import?atomic=true", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response ImportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Applied)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, "create", response.Rows[0].Action)
+	assert.Equal(t, "Já existe um item com este código", response.Rows[1].Error)
+	rows := mockService.Calls[0].Arguments.Get(1).([]service.ImportRow)
+	assert.Len(t, rows, 2)
+	mockService.AssertExpectations(t)
+}
+func TestImport_RejectedRequests(t *testing.T) {
+	router, mockService := setupItemTest()
+	cases := []struct {
+		query       string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"", "application/json", `[]`, http.StatusUnsupportedMediaType},
+		{"?dry_run=talvez", "text/csv", "code,title,description,price\n", http.StatusBadRequest},
+		{"", "text/csv", "code,title,description,price\n", http.StatusBadRequest},
+		{"", "text/csv", "code,title\n", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest("POST", "/items/import"+tc.query, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.status, w.Code, tc.body)
+	}
+	mockService.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+}
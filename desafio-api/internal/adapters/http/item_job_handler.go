@@ -0,0 +1,214 @@
+package http
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/gin-gonic/gin"
+)
+const (
+	JobTypeItemImport = "items.import"
+	JobTypeItemExport = "items.export"
+)
+// JobFileStore holds the files produced by export jobs until they are
+// downloaded.
+type JobFileStore interface {
+	Create(name string) (io.WriteCloser, error)
+	Open(name string) (io.ReadCloser, error)
+	Remove(name string) error
+}
+// ItemJobHandler runs item imports and exports as background jobs, for
+// files too large to handle within a single request.
+type ItemJobHandler struct {
+	itemService service.ItemServiceInterface
+	jobService  service.JobServiceInterface
+	files       JobFileStore
+	logger      *slog.Logger
+}
+func NewItemJobHandler(itemService service.ItemServiceInterface, jobService service.JobServiceInterface, files JobFileStore, logger *slog.Logger) *ItemJobHandler {
+	return &ItemJobHandler{itemService: itemService, jobService: jobService, files: files, logger: logger}
+}
+type importJobPayload struct {
+	DryRun bool           `json:"dry_run"`
+	Upsert bool           `json:"upsert"`
+	Atomic bool           `json:"atomic"`
+	Rows   []importJobRow `json:"rows"`
+}
+type importJobRow struct {
+	Line  int                `json:"line"`
+	Item  *itemPatchDocument `json:"item,omitempty"`
+	Error string             `json:"error,omitempty"`
+}
+type exportJobPayload struct {
+	Format string              `json:"format"`
+	Filter repoPort.ItemFilter `json:"filter"`
+}
+type exportJobResult struct {
+	Format      string `json:"format"`
+	Rows        int    `json:"rows"`
+	File        string `json:"file"`
+	DownloadURL string `json:"download_url"`
+}
+func (h *ItemJobHandler) RegisterRunners(jobs *service.JobService) {
+	jobs.Register(JobTypeItemImport, h.runImport)
+	jobs.Register(JobTypeItemExport, h.runExport)
+}
+func (h *ItemJobHandler) Import(c *gin.Context) {
+	opts, rows, ok := parseImportRequest(c)
+	if !ok {
+		return
+	}
+	payload := importJobPayload{DryRun: opts.DryRun, Upsert: opts.Upsert, Atomic: opts.Atomic, Rows: make([]importJobRow, 0, len(rows))}
+	for _, row := range rows {
+		jobRow := importJobRow{Line: row.Line}
+		if row.Err != nil {
+			jobRow.Error = row.Err.Error()
+		} else {
+			jobRow.Item = &itemPatchDocument{
+				Code:        row.Item.Code,
+				Title:       row.Item.Title,
+				Description: row.Item.Description,
+				Price:       row.Item.Price,
+				Stock:       row.Item.Stock,
+			}
+		}
+		payload.Rows = append(payload.Rows, jobRow)
+	}
+	h.enqueue(c, JobTypeItemImport, payload)
+}
+func (h *ItemJobHandler) Export(c *gin.Context) {
+	filter, message := parseItemFilter(c)
+	if message != "" {
+		RespondWithError(c, http.StatusBadRequest, message)
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if _, ok := itemExportFormats[format]; !ok {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'format' deve ser csv, ndjson ou xlsx")
+		return
+	}
+	h.enqueue(c, JobTypeItemExport, exportJobPayload{Format: format, Filter: filter})
+}
+func (h *ItemJobHandler) Download(c *gin.Context) {
+	job, ok := findJobForUser(c, h.jobService, h.logger)
+	if !ok {
+		return
+	}
+	var result exportJobResult
+	if job.Type != JobTypeItemExport || job.Status != domain.JobSucceeded || json.Unmarshal(job.Result, &result) != nil {
+		RespondWithError(c, http.StatusConflict, "O job não possui arquivo para download")
+		return
+	}
+	file, err := h.files.Open(result.File)
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "job file unavailable", slog.Int64("job_id", job.ID), slog.Any("error", err))
+		RespondWithError(c, http.StatusGone, "O arquivo da exportação não está mais disponível")
+		return
+	}
+	defer file.Close()
+	c.Header("Content-Type", itemExportFormats[result.Format].contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": result.File}))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, file); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "job download interrupted", slog.Int64("job_id", job.ID), slog.Any("error", err))
+	}
+}
+func (h *ItemJobHandler) enqueue(c *gin.Context, jobType string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		RespondWithError(c, http.StatusInternalServerError, "Falha ao criar o job")
+		return
+	}
+	job, err := h.jobService.Enqueue(c.Request.Context(), jobType, data)
+	if err != nil {
+		respondInternalError(c, h.logger, "Falha ao criar o job", err)
+		return
+	}
+	respondAccepted(c, job)
+}
+func (h *ItemJobHandler) runImport(ctx context.Context, job *domain.Job, progress service.JobProgress) ([]byte, error) {
+	var payload importJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid import payload: %w", err)
+	}
+	rows := make([]service.ImportRow, 0, len(payload.Rows))
+	for _, jobRow := range payload.Rows {
+		row := service.ImportRow{Line: jobRow.Line}
+		if jobRow.Item == nil {
+			row.Err = &importRowError{jobRow.Error}
+		} else {
+			row.Item = &domain.Item{
+				Code:        jobRow.Item.Code,
+				Title:       jobRow.Item.Title,
+				Description: jobRow.Item.Description,
+				Price:       jobRow.Item.Price,
+				Stock:       jobRow.Item.Stock,
+			}
+		}
+		rows = append(rows, row)
+	}
+	opts := service.ImportOptions{DryRun: payload.DryRun, Upsert: payload.Upsert, Atomic: payload.Atomic, Progress: progress}
+	report, err := h.itemService.Import(ctx, rows, opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(newImportResponse(opts, report))
+}
+func (h *ItemJobHandler) runExport(ctx context.Context, job *domain.Job, progress service.JobProgress) (result []byte, err error) {
+	var payload exportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid export payload: %w", err)
+	}
+	format, ok := itemExportFormats[payload.Format]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format %q", payload.Format)
+	}
+	_, total, err := h.itemService.List(ctx, payload.Filter, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("itens-job-%d.%s", job.ID, payload.Format)
+	file, err := h.files.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			h.files.Remove(name)
+		}
+	}()
+	writer, err := format.newWriter(file)
+	if err != nil {
+		return nil, err
+	}
+	rows := 0
+	err = h.itemService.Export(ctx, payload.Filter, func(item *domain.Item) error {
+		rows++
+		if rows > total {
+			total = rows
+		}
+		progress(rows, total)
+		return writer.Write(item)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(exportJobResult{
+		Format:      payload.Format,
+		Rows:        rows,
+		File:        name,
+		DownloadURL: fmt.Sprintf("/api/v1/jobs/%d/download", job.ID),
+	})
+}
@@ -0,0 +1,76 @@
+package http
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"desafio-api/internal/domain"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+var errUnsupportedPatchType = errors.New("unsupported patch media type")
+// itemPatchDocument lists the fields a client may change through PATCH;
+// anything else in the patched document is rejected, and so is a field that
+// was removed or set to null.
+type itemPatchDocument struct {
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Price       int64  `json:"price"`
+	Stock       int    `json:"stock"`
+}
+func applyItemPatch(contentType string, item *domain.Item, patch []byte) error {
+	original, err := json.Marshal(itemPatchDocument{
+		Code:        item.Code,
+		Title:       item.Title,
+		Description: item.Description,
+		Price:       item.Price,
+		Stock:       item.Stock,
+	})
+	if err != nil {
+		return err
+	}
+	var patched []byte
+	switch contentType {
+	case mergePatchContentType, "application/json":
+		if !json.Valid(patch) || bytes.TrimSpace(patch)[0] != '{' {
+			return errors.New("merge patch must be a JSON object")
+		}
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case jsonPatchContentType:
+		var operations jsonpatch.Patch
+		operations, err = jsonpatch.DecodePatch(patch)
+		if err == nil {
+			patched, err = operations.Apply(original)
+		}
+	default:
+		return errUnsupportedPatchType
+	}
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patched, &fields); err != nil {
+		return err
+	}
+	for _, name := range []string{"code", "title", "description", "price", "stock"} {
+		if value, ok := fields[name]; !ok || string(value) == "null" {
+			return fmt.Errorf("%s cannot be null or removed", name)
+		}
+	}
+	var document itemPatchDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+	item.Code = document.Code
+	item.Title = document.Title
+	item.Description = document.Description
+	item.Price = document.Price
+	item.Stock = document.Stock
+	return nil
+}
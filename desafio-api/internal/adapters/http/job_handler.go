@@ -0,0 +1,138 @@
+package http
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+type JobHandler struct {
+	jobService service.JobServiceInterface
+	logger     *slog.Logger
+}
+func NewJobHandler(jobService service.JobServiceInterface, logger *slog.Logger) *JobHandler {
+	return &JobHandler{jobService: jobService, logger: logger}
+}
+type JobResponse struct {
+	ID              int64           `json:"id"`
+	Type            string          `json:"type"`
+	Status          string          `json:"status"`
+	Progress        int             `json:"progress"`
+	Processed       int             `json:"processed"`
+	Total           int             `json:"total"`
+	Attempts        int             `json:"attempts"`
+	MaxAttempts     int             `json:"max_attempts"`
+	CancelRequested bool            `json:"cancel_requested"`
+	Error           string          `json:"error,omitempty"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	RunAt           string          `json:"run_at"`
+	CreatedBy       int             `json:"created_by"`
+	CreatedAt       string          `json:"created_at"`
+	StartedAt       string          `json:"started_at,omitempty"`
+	FinishedAt      string          `json:"finished_at,omitempty"`
+}
+func toJobResponse(job *domain.Job) *JobResponse {
+	response := &JobResponse{
+		ID:              job.ID,
+		Type:            job.Type,
+		Status:          job.Status,
+		Progress:        job.Percent(),
+		Processed:       job.Processed,
+		Total:           job.Total,
+		Attempts:        job.Attempts,
+		MaxAttempts:     job.MaxAttempts,
+		CancelRequested: job.CancelRequested,
+		Error:           job.LastError,
+		RunAt:           job.RunAt.Format(time.RFC3339),
+		CreatedBy:       job.CreatedBy,
+		CreatedAt:       job.CreatedAt.Format(time.RFC3339),
+	}
+	if len(job.Result) > 0 {
+		response.Result = json.RawMessage(job.Result)
+	}
+	if job.StartedAt != nil {
+		response.StartedAt = job.StartedAt.Format(time.RFC3339)
+	}
+	if job.FinishedAt != nil {
+		response.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+	return response
+}
+func (h *JobHandler) Get(c *gin.Context) {
+	job, ok := findJobForUser(c, h.jobService, h.logger)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, toJobResponse(job))
+}
+func (h *JobHandler) Cancel(c *gin.Context) {
+	job, ok := findJobForUser(c, h.jobService, h.logger)
+	if !ok {
+		return
+	}
+	job, err := h.jobService.Cancel(c.Request.Context(), job.ID)
+	if err != nil {
+		respondJobError(c, h.logger, err)
+		return
+	}
+	status := http.StatusOK
+	if job.Status == domain.JobRunning {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, toJobResponse(job))
+}
+func (h *JobHandler) Retry(c *gin.Context) {
+	job, ok := findJobForUser(c, h.jobService, h.logger)
+	if !ok {
+		return
+	}
+	// An import writes items, and the creator may have lost that permission
+	// since enqueueing it.
+	if job.Type == JobTypeItemImport && !domain.HasPermission(c.GetString("role"), domain.PermissionItemsWrite) {
+		RespondWithError(c, http.StatusForbidden, "Acesso negado para o perfil do usuário")
+		return
+	}
+	job, err := h.jobService.Retry(c.Request.Context(), job.ID)
+	if err != nil {
+		respondJobError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, toJobResponse(job))
+}
+// findJobForUser loads the job in the :id parameter. Only its creator and
+// admins may see it; anyone else gets the same 404 as a missing job.
+func findJobForUser(c *gin.Context, jobs service.JobServiceInterface, logger *slog.Logger) (*domain.Job, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de job inválido")
+		return nil, false
+	}
+	job, err := jobs.Get(c.Request.Context(), id)
+	if err == nil && job.CreatedBy != c.GetInt("userID") && c.GetString("role") != domain.RoleAdmin {
+		err = domain.ErrJobNotFound
+	}
+	if err != nil {
+		respondJobError(c, logger, err)
+		return nil, false
+	}
+	return job, true
+}
+func respondAccepted(c *gin.Context, job *domain.Job) {
+	c.Header("Location", "/api/v1/jobs/"+strconv.FormatInt(job.ID, 10))
+	c.JSON(http.StatusAccepted, toJobResponse(job))
+}
+func respondJobError(c *gin.Context, logger *slog.Logger, err error) {
+	switch err {
+	case domain.ErrJobNotFound:
+		RespondWithError(c, http.StatusNotFound, "Job não encontrado")
+	case domain.ErrJobNotCancelable:
+		RespondWithError(c, http.StatusConflict, "O job já foi finalizado")
+	case domain.ErrJobNotRetryable:
+		RespondWithError(c, http.StatusConflict, "Apenas jobs com falha ou cancelados podem ser executados novamente")
+	default:
+		respondInternalError(c, logger, "Falha ao processar o job", err)
+	}
+}
@@ -0,0 +1,171 @@
+package http
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/adapters/storage"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+type jobTest struct {
+	router *gin.Engine
+	items  *MockItemService
+	jobs   *service.JobService
+	userID int
+	role   string
+}
+func setupJobTest(t *testing.T) *jobTest {
+	gin.SetMode(gin.TestMode)
+	files, err := storage.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	test := &jobTest{
+		items:  new(MockItemService),
+		jobs:   service.NewJobService(repository.NewMockJobRepository(), logging.Nop()),
+		userID: 1,
+		role:   domain.RoleEditor,
+	}
+	itemJobs := NewItemJobHandler(test.items, test.jobs, files, logging.Nop())
+	itemJobs.RegisterRunners(test.jobs)
+	jobHandler := NewJobHandler(test.jobs, logging.Nop())
+	test.router = gin.New()
+	test.router.Use(func(c *gin.Context) {
+		c.Set("userID", test.userID)
+		c.Set("role", test.role)
+		c.Request = c.Request.WithContext(domain.ContextWithUser(c.Request.Context(), test.userID, test.role))
+		c.Next()
+	})
+	test.router.POST("/items/import/async", itemJobs.Import)
+	test.router.POST("/items/export/async", itemJobs.Export)
+	test.router.GET("/jobs/:id", jobHandler.Get)
+	test.router.POST("/jobs/:id/cancel", jobHandler.Cancel)
+	test.router.POST("/jobs/:id/retry", jobHandler.Retry)
+	test.router.GET("/jobs/:id/download", itemJobs.Download)
+	return test
+}
+func (j *jobTest) do(method, path, contentType string, body []byte) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	j.router.ServeHTTP(w, req)
+	return w
+}
+func (j *jobTest) runNext(t *testing.T) {
+	ran, err := j.jobs.RunNext(context.Background(), "test-worker")
+	require.NoError(t, err)
+	require.True(t, ran)
+}
+func decodeJob(t *testing.T, w *httptest.ResponseRecorder) JobResponse {
+	var response JobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+func TestItemJobs_AsyncImport(t *testing.T) {
+	test := setupJobTest(t)
+	body := []byte("code,title,description,price,stock\nSKU-1,Item um,Primeiro item,1050,3\nSKU-2,,Sem titulo,1,1\n")
+	w := test.do("POST", "/items/import/async?upsert=true", "text/csv", body)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	accepted := decodeJob(t, w)
+	assert.Equal(t, domain.JobQueued, accepted.Status)
+	assert.Equal(t, fmt.Sprintf("/api/v1/jobs/%d", accepted.ID), w.Header().Get("Location"))
+	test.items.On("Import", mock.Anything, mock.MatchedBy(func(rows []service.ImportRow) bool {
+		return len(rows) == 2 && rows[0].Item != nil && rows[0].Item.Code == "SKU-1" && rows[0].Item.Price == 1050
+	}), mock.MatchedBy(func(opts service.ImportOptions) bool {
+		return opts.Upsert && !opts.DryRun && opts.Progress != nil
+	})).Return(&service.ImportReport{Total: 2, Created: 1, Failed: 1, Applied: true}, nil)
+	test.runNext(t)
+	w = test.do("GET", fmt.Sprintf("/jobs/%d", accepted.ID), "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	job := decodeJob(t, w)
+	assert.Equal(t, domain.JobSucceeded, job.Status)
+	var report ImportResponse
+	require.NoError(t, json.Unmarshal(job.Result, &report))
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	test.items.AssertExpectations(t)
+}
+func TestItemJobs_AsyncExportAndDownload(t *testing.T) {
+	test := setupJobTest(t)
+	filter := repoPort.ItemFilter{Status: "ACTIVE"}
+	test.items.On("List", mock.Anything, filter, 1, 1).Return([]*domain.Item{createTestItem()}, 2, nil)
+	test.items.On("Export", mock.Anything, filter, mock.Anything).Return([]*domain.Item{createTestItem(), createTestItem()}, nil)
+	w := test.do("POST", "/items/export/async?status=ACTIVE", "", nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	id := decodeJob(t, w).ID
+	w = test.do("GET", fmt.Sprintf("/jobs/%d/download", id), "", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	test.runNext(t)
+	w = test.do("GET", fmt.Sprintf("/jobs/%d", id), "", nil)
+	job := decodeJob(t, w)
+	assert.Equal(t, domain.JobSucceeded, job.Status)
+	assert.Equal(t, 100, job.Progress)
+	assert.Equal(t, 2, job.Processed)
+	w = test.do("GET", fmt.Sprintf("/jobs/%d/download", id), "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, fmt.Sprintf("attachment; filename=itens-job-%d.csv", id), w.Header().Get("Content-Disposition"))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+}
+func TestItemJobs_ExportRejectsUnknownFormat(t *testing.T) {
+	test := setupJobTest(t)
+	w := test.do("POST", "/items/export/async?format=pdf", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+func TestJobs_CancelAndRetry(t *testing.T) {
+	test := setupJobTest(t)
+	w := test.do("POST", "/items/export/async", "", nil)
+	id := decodeJob(t, w).ID
+	w = test.do("POST", fmt.Sprintf("/jobs/%d/cancel", id), "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, domain.JobCanceled, decodeJob(t, w).Status)
+	w = test.do("POST", fmt.Sprintf("/jobs/%d/cancel", id), "", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = test.do("POST", fmt.Sprintf("/jobs/%d/retry", id), "", nil)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, domain.JobQueued, decodeJob(t, w).Status)
+	w = test.do("POST", fmt.Sprintf("/jobs/%d/retry", id), "", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+func TestJobs_RetryImportRequiresWritePermission(t *testing.T) {
+	test := setupJobTest(t)
+	w := test.do("POST", "/items/import/async", "text/csv", []byte("code,title,description,price,stock\nSKU-1,Item um,Primeiro item,1050,3\n"))
+	id := decodeJob(t, w).ID
+	w = test.do("POST", fmt.Sprintf("/jobs/%d/cancel", id), "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	test.role = domain.RoleViewer
+	w = test.do("POST", fmt.Sprintf("/jobs/%d/retry", id), "", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	test.role = domain.RoleEditor
+	w = test.do("POST", fmt.Sprintf("/jobs/%d/retry", id), "", nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+func TestJobs_VisibleOnlyToCreatorAndAdmins(t *testing.T) {
+	test := setupJobTest(t)
+	w := test.do("POST", "/items/export/async", "", nil)
+	path := fmt.Sprintf("/jobs/%d", decodeJob(t, w).ID)
+	test.userID = 2
+	w = test.do("GET", path, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	test.role = domain.RoleAdmin
+	w = test.do("GET", path, "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = test.do("GET", "/jobs/999", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = test.do("GET", "/jobs/abc", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
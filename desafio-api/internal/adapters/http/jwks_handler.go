@@ -0,0 +1,15 @@
+package http
+import (
+	"net/http"
+	"desafio-api/internal/adapters/auth"
+	"github.com/gin-gonic/gin"
+)
+type JWKSProvider interface {
+	JWKS() auth.JWKS
+}
+func JWKSHandler(provider JWKSProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, provider.JWKS())
+	}
+}
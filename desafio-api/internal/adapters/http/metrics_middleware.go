@@ -0,0 +1,19 @@
+package http
+import (
+	"desafio-api/internal/adapters/metrics"
+	"github.com/gin-gonic/gin"
+)
+// MetricsMiddleware records every request under its route template (e.g.
+// /api/v1/items/:id) so item ids don't explode the label cardinality.
+// Requests that match no route share the "unmatched" label.
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := m.RequestStarted(c.Request.Method)
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		done(route, c.Writer.Status())
+	}
+}
@@ -0,0 +1,37 @@
+package http
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"desafio-api/internal/adapters/metrics"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+func TestMetricsMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New()
+	m.SetItemStats([]domain.ItemStatusStats{{Status: "ACTIVE", Items: 2, Stock: 15}})
+	router := gin.New()
+	router.Use(MetricsMiddleware(m))
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+	router.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+	for _, path := range []string{"/items/1", "/items/2", "/missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `desafio_http_requests_total{method="GET",route="/items/:id",status="404"} 2`)
+	assert.Contains(t, body, `desafio_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, `route="/items/1"`)
+	assert.Contains(t, body, `desafio_items{status="ACTIVE"} 2`)
+	assert.Contains(t, body, `desafio_items_stock{status="ACTIVE"} 15`)
+	assert.Contains(t, body, `desafio_auth_logins_total{result="failure"} 0`)
+	assert.Contains(t, body, "go_goroutines")
+}
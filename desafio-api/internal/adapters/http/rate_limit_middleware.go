@@ -0,0 +1,61 @@
+package http
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"desafio-api/internal/adapters/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+// RateLimitMiddleware limits the requests of a route group per client IP
+// and, when it runs after AuthMiddleware, per user. The headers describe
+// the most restrictive of the two buckets. If the store fails the request is
+// let through.
+func RateLimitMiddleware(store ratelimit.Store, group string, perIP, perUser ratelimit.Limit, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var results []ratelimit.Result
+		take := func(key string, limit ratelimit.Limit) {
+			if !limit.Enabled() {
+				return
+			}
+			result, err := store.Take(c.Request.Context(), key, limit)
+			if err != nil {
+				logger.ErrorContext(c.Request.Context(), "rate limit check failed", slog.String("bucket", key), slog.Any("error", err))
+				return
+			}
+			results = append(results, result)
+		}
+		take(fmt.Sprintf("%s:ip:%s", group, c.ClientIP()), perIP)
+		if userID, ok := c.Get("userID"); ok {
+			take(fmt.Sprintf("%s:user:%v", group, userID), perUser)
+		}
+		if len(results) == 0 {
+			c.Next()
+			return
+		}
+		result := results[0]
+		for _, other := range results[1:] {
+			if !other.Allowed && result.Allowed || other.Allowed == result.Allowed && other.Remaining < result.Remaining {
+				result = other
+			}
+		}
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+		if !result.Allowed {
+			respondTooManyRequests(c, result.RetryAfter, "Limite de requisições excedido. Tente novamente mais tarde")
+			return
+		}
+		c.Next()
+	}
+}
+func respondTooManyRequests(c *gin.Context, retryAfter time.Duration, message string) {
+	c.Header("Retry-After", ceilSeconds(retryAfter))
+	RespondWithError(c, http.StatusTooManyRequests, message)
+	c.Abort()
+}
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,45 @@
+package http
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := 1
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	router.Use(RateLimitMiddleware(ratelimit.NewMemoryStore(), "api", ratelimit.Limit{Requests: 4, Window: time.Minute}, ratelimit.Limit{Requests: 2, Window: time.Minute}, logging.Nop()))
+	router.GET("/items", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	get := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/items", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	w := get()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"), "the per-user bucket is the most restrictive")
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, http.StatusOK, get().Code)
+	w = get()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	userID = 2
+	assert.Equal(t, http.StatusOK, get().Code, "another user still has tokens")
+	w = get()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "but the IP bucket is empty")
+	assert.Equal(t, "4", w.Header().Get("RateLimit-Limit"))
+}
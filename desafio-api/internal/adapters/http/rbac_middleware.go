@@ -0,0 +1,29 @@
+package http
+import (
+	"net/http"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		RespondWithError(c, http.StatusForbidden, "Acesso negado para o perfil do usuário")
+		c.Abort()
+	}
+}
+func RequirePermission(permission domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !domain.HasPermission(c.GetString("role"), permission) {
+			RespondWithError(c, http.StatusForbidden, "Acesso negado para o perfil do usuário")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package http
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+func setupRBACTest(role string, guard gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", 1)
+		c.Set("role", role)
+		c.Next()
+	})
+	router.DELETE("/items/:id", guard, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		role     string
+		expected int
+	}{
+		{domain.RoleAdmin, http.StatusNoContent},
+		{domain.RoleEditor, http.StatusForbidden},
+		{domain.RoleViewer, http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			router := setupRBACTest(tt.role, RequirePermission(domain.PermissionItemsDelete))
+			req, _ := http.NewRequest("DELETE", "/items/1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+func TestRequireRole(t *testing.T) {
+	router := setupRBACTest(domain.RoleEditor, RequireRole(domain.RoleAdmin, domain.RoleEditor))
+	req, _ := http.NewRequest("DELETE", "/items/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	router = setupRBACTest(domain.RoleViewer, RequireRole(domain.RoleAdmin))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
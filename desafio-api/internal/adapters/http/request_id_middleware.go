@@ -0,0 +1,23 @@
+package http
+import (
+	"regexp"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+const RequestIDHeader = "X-Request-ID"
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+// RequestIDMiddleware keeps the caller's X-Request-ID, or creates one, and
+// makes it available to handlers and services and in the response.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(domain.ContextWithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package http
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, domain.RequestIDFromContext(c.Request.Context()))
+	})
+	for header, kept := range map[string]bool{
+		"abc-123.x_Y":            true,
+		"":                       false,
+		"com espaço":             false,
+		strings.Repeat("a", 101): false,
+	} {
+		req, _ := http.NewRequest("GET", "/", nil)
+		if header != "" {
+			req.Header.Set(RequestIDHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		requestID := w.Header().Get(RequestIDHeader)
+		assert.Equal(t, requestID, w.Body.String(), header)
+		if kept {
+			assert.Equal(t, header, requestID)
+		} else {
+			assert.NotEqual(t, header, requestID)
+			assert.Len(t, requestID, 36)
+		}
+	}
+}
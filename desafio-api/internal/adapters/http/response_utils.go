@@ -1,14 +1,24 @@
 package http
 import (
-	"log"
+	"log/slog"
+	"net/http"
 	"time"
 	"github.com/gin-gonic/gin"
 )
 func RespondWithError(c *gin.Context, status int, message string) {
-	log.Printf("[DEBUG] Respondendo com erro HTTP %d: %s", status, message)
-	c.JSON(status, gin.H{
+	body := gin.H{
 		"error": message,
 		"status": status,
 		"timestamp": time.Now().Format(time.RFC3339),
-	})
+	}
+	if id := traceID(c); id != "" {
+		body["trace_id"] = id
+	}
+	c.JSON(status, body)
+}
+// respondInternalError logs err, which the client never sees, and answers
+// 500 with message.
+func respondInternalError(c *gin.Context, logger *slog.Logger, message string, err error) {
+	logger.ErrorContext(c.Request.Context(), "request failed", slog.String("response", message), slog.Any("error", err))
+	RespondWithError(c, http.StatusInternalServerError, message)
 }
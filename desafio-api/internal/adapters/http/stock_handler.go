@@ -0,0 +1,164 @@
+package http
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+type StockHandler struct {
+	stockService service.StockServiceInterface
+	logger       *slog.Logger
+}
+func NewStockHandler(stockService service.StockServiceInterface, logger *slog.Logger) *StockHandler {
+	return &StockHandler{stockService: stockService, logger: logger}
+}
+type StockAdjustmentRequest struct {
+	Quantity int    `json:"quantity" binding:"required,gt=0"`
+	Reason   string `json:"reason" binding:"required"`
+}
+type ReserveStockRequest struct {
+	Quantity   int    `json:"quantity" binding:"required,gt=0"`
+	Reason     string `json:"reason" binding:"required"`
+	TTLSeconds int    `json:"ttl_seconds" binding:"gte=0"`
+}
+type SettleReservationRequest struct {
+	Reason string `json:"reason"`
+}
+type MovementListResponse struct {
+	TotalPages int                     `json:"totalPages"`
+	Data       []*domain.StockMovement `json:"data"`
+}
+func (h *StockHandler) Increment(c *gin.Context) {
+	h.adjust(c, h.stockService.Increment)
+}
+func (h *StockHandler) Decrement(c *gin.Context) {
+	h.adjust(c, h.stockService.Decrement)
+}
+func (h *StockHandler) adjust(c *gin.Context, apply func(ctx context.Context, itemID int64, quantity int, reason string) (*domain.Item, error)) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de item inválido")
+		return
+	}
+	var req StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
+		return
+	}
+	item, err := apply(c.Request.Context(), id, req.Quantity, req.Reason)
+	if err != nil {
+		respondStockError(c, h.logger, err, "movimentar o estoque do item")
+		return
+	}
+	c.Header("ETag", itemETag(item))
+	c.JSON(http.StatusOK, toItemResponse(item))
+}
+func (h *StockHandler) ListMovements(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de item inválido")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'limit' deve ser um número entre 1 e 100")
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'page' deve ser um número maior que zero")
+		return
+	}
+	movements, total, err := h.stockService.ListMovements(c.Request.Context(), id, page, limit)
+	if err != nil {
+		respondStockError(c, h.logger, err, "listar as movimentações do item")
+		return
+	}
+	totalPages := (total + limit - 1) / limit
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.JSON(http.StatusOK, MovementListResponse{TotalPages: totalPages, Data: movements})
+}
+func (h *StockHandler) Reserve(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de item inválido")
+		return
+	}
+	var req ReserveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
+		return
+	}
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	reservation, err := h.stockService.Reserve(c.Request.Context(), id, req.Quantity, ttl, req.Reason)
+	if err != nil {
+		respondStockError(c, h.logger, err, "reservar o estoque do item")
+		return
+	}
+	c.JSON(http.StatusCreated, reservation)
+}
+func (h *StockHandler) GetReservation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de reserva inválido")
+		return
+	}
+	reservation, err := h.stockService.GetReservation(c.Request.Context(), id)
+	if err != nil {
+		respondStockError(c, h.logger, err, "buscar a reserva")
+		return
+	}
+	c.JSON(http.StatusOK, reservation)
+}
+func (h *StockHandler) Commit(c *gin.Context) {
+	h.settle(c, h.stockService.CommitReservation)
+}
+func (h *StockHandler) Release(c *gin.Context) {
+	h.settle(c, h.stockService.ReleaseReservation)
+}
+func (h *StockHandler) settle(c *gin.Context, apply func(ctx context.Context, id int64, reason string) (*domain.StockReservation, error)) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID de reserva inválido")
+		return
+	}
+	var req SettleReservationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
+			return
+		}
+	}
+	reservation, err := apply(c.Request.Context(), id, req.Reason)
+	if err != nil {
+		respondStockError(c, h.logger, err, "finalizar a reserva")
+		return
+	}
+	c.JSON(http.StatusOK, reservation)
+}
+func respondStockError(c *gin.Context, logger *slog.Logger, err error, action string) {
+	switch err {
+	case domain.ErrItemNotFound:
+		RespondWithError(c, http.StatusNotFound, "Item não encontrado")
+	case domain.ErrReservationNotFound:
+		RespondWithError(c, http.StatusNotFound, "Reserva não encontrada")
+	case domain.ErrInvalidQuantity:
+		RespondWithError(c, http.StatusBadRequest, "A quantidade deve ser maior que zero")
+	case domain.ErrReasonRequired:
+		RespondWithError(c, http.StatusBadRequest, "Informe o motivo da movimentação")
+	case domain.ErrInvalidReservationTTL:
+		RespondWithError(c, http.StatusBadRequest, "O prazo da reserva deve ser de no máximo 24 horas")
+	case domain.ErrInsufficientStock:
+		RespondWithError(c, http.StatusConflict, "Estoque disponível insuficiente")
+	case domain.ErrReservationNotPending:
+		RespondWithError(c, http.StatusConflict, "A reserva já foi finalizada")
+	case domain.ErrReservationExpired:
+		RespondWithError(c, http.StatusConflict, "A reserva expirou")
+	default:
+		respondInternalError(c, logger, "Falha ao "+action, err)
+	}
+}
@@ -0,0 +1,144 @@
+package http
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+type MockStockService struct {
+	mock.Mock
+}
+func (m *MockStockService) Increment(ctx context.Context, itemID int64, quantity int, reason string) (*domain.Item, error) {
+	args := m.Called(ctx, itemID, quantity, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Item), args.Error(1)
+}
+func (m *MockStockService) Decrement(ctx context.Context, itemID int64, quantity int, reason string) (*domain.Item, error) {
+	args := m.Called(ctx, itemID, quantity, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Item), args.Error(1)
+}
+func (m *MockStockService) Reserve(ctx context.Context, itemID int64, quantity int, ttl time.Duration, reason string) (*domain.StockReservation, error) {
+	args := m.Called(ctx, itemID, quantity, ttl, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockReservation), args.Error(1)
+}
+func (m *MockStockService) GetReservation(ctx context.Context, id int64) (*domain.StockReservation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockReservation), args.Error(1)
+}
+func (m *MockStockService) CommitReservation(ctx context.Context, id int64, reason string) (*domain.StockReservation, error) {
+	args := m.Called(ctx, id, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockReservation), args.Error(1)
+}
+func (m *MockStockService) ReleaseReservation(ctx context.Context, id int64, reason string) (*domain.StockReservation, error) {
+	args := m.Called(ctx, id, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StockReservation), args.Error(1)
+}
+func (m *MockStockService) ListMovements(ctx context.Context, itemID int64, page, limit int) ([]*domain.StockMovement, int, error) {
+	args := m.Called(ctx, itemID, page, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.StockMovement), args.Int(1), args.Error(2)
+}
+func setupStockTest() (*gin.Engine, *MockStockService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockStockService)
+	handler := NewStockHandler(mockService, logging.Nop())
+	router := gin.New()
+	router.POST("/items/:id/stock/increment", handler.Increment)
+	router.POST("/items/:id/stock/decrement", handler.Decrement)
+	router.POST("/items/:id/reservations", handler.Reserve)
+	router.POST("/reservations/:id/commit", handler.Commit)
+	router.POST("/reservations/:id/release", handler.Release)
+	return router, mockService
+}
+func TestStockHandler_Decrement(t *testing.T) {
+	router, mockService := setupStockTest()
+	item := createTestItem()
+	item.Stock = 7
+	item.Version = 2
+	mockService.On("Decrement", mock.Anything, int64(1), 3, "venda").Return(item, nil)
+	body, _ := json.Marshal(map[string]interface{}{"quantity": 3, "reason": "venda"})
+	req, _ := http.NewRequest("POST", "/items/1/stock/decrement", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(7), response["stock"])
+	mockService.AssertExpectations(t)
+}
+func TestStockHandler_InsufficientStock(t *testing.T) {
+	router, mockService := setupStockTest()
+	mockService.On("Decrement", mock.Anything, int64(1), 30, "venda").Return(nil, domain.ErrInsufficientStock)
+	body, _ := json.Marshal(map[string]interface{}{"quantity": 30, "reason": "venda"})
+	req, _ := http.NewRequest("POST", "/items/1/stock/decrement", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+func TestStockHandler_RequiresReason(t *testing.T) {
+	router, _ := setupStockTest()
+	body, _ := json.Marshal(map[string]interface{}{"quantity": 3})
+	req, _ := http.NewRequest("POST", "/items/1/stock/increment", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+func TestStockHandler_ReserveAndCommit(t *testing.T) {
+	router, mockService := setupStockTest()
+	reservation := &domain.StockReservation{ID: 9, ItemID: 1, Quantity: 2, Status: domain.ReservationPending, Reason: "pedido 42"}
+	mockService.On("Reserve", mock.Anything, int64(1), 2, 5*time.Minute, "pedido 42").Return(reservation, nil)
+	body, _ := json.Marshal(map[string]interface{}{"quantity": 2, "reason": "pedido 42", "ttl_seconds": 300})
+	req, _ := http.NewRequest("POST", "/items/1/reservations", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	committed := *reservation
+	committed.Status = domain.ReservationCommitted
+	mockService.On("CommitReservation", mock.Anything, int64(9), "").Return(&committed, nil)
+	req, _ = http.NewRequest("POST", "/reservations/9/commit", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, domain.ReservationCommitted, response["status"])
+	mockService.On("ReleaseReservation", mock.Anything, int64(9), "cancelado").Return(nil, domain.ErrReservationNotPending)
+	req, _ = http.NewRequest("POST", "/reservations/9/release", bytes.NewBufferString(`{"reason": "cancelado"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
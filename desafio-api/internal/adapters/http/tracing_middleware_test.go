@@ -0,0 +1,40 @@
+package http
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"desafio-api/internal/adapters/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+func TestTracingMiddleware_ContinuesIncomingTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spans := tracing.InMemory()
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.Use(TracingMiddleware())
+	router.GET("/items/:id", func(c *gin.Context) {
+		RespondWithError(c, http.StatusNotFound, "Item não encontrado")
+	})
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req, _ := http.NewRequest("GET", "/items/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("traceparent"), "00-"+traceID+"-"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, traceID, body["trace_id"])
+	ended := spans.GetSpans()
+	require.Len(t, ended, 1)
+	span := ended[0]
+	assert.Equal(t, "GET /items/:id", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, traceID, span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+}
@@ -0,0 +1,165 @@
+package http
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+type WebhookHandler struct {
+	webhookService service.WebhookServiceInterface
+	logger         *slog.Logger
+}
+func NewWebhookHandler(webhookService service.WebhookServiceInterface, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService, logger: logger}
+}
+type WebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types" binding:"required"`
+	Active     *bool    `json:"active"`
+}
+// CreateWebhookResponse is the only response that carries the secret.
+type CreateWebhookResponse struct {
+	*domain.WebhookSubscription
+	Secret string `json:"secret"`
+}
+type DeliveryListResponse struct {
+	TotalPages int                       `json:"totalPages"`
+	Data       []*domain.WebhookDelivery `json:"data"`
+}
+func (req *WebhookRequest) subscription() *domain.WebhookSubscription {
+	subscription := &domain.WebhookSubscription{URL: req.URL, Secret: req.Secret, EventTypes: req.EventTypes, Active: true}
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+	return subscription
+}
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
+		return
+	}
+	subscription := req.subscription()
+	if err := h.webhookService.Create(c.Request.Context(), subscription); err != nil {
+		respondWebhookError(c, h.logger, err, "criar o webhook")
+		return
+	}
+	c.JSON(http.StatusCreated, CreateWebhookResponse{WebhookSubscription: subscription, Secret: subscription.Secret})
+}
+func (h *WebhookHandler) List(c *gin.Context) {
+	subscriptions, err := h.webhookService.List(c.Request.Context())
+	if err != nil {
+		respondWebhookError(c, h.logger, err, "listar os webhooks")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": subscriptions})
+}
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+	subscription, err := h.webhookService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondWebhookError(c, h.logger, err, "buscar o webhook")
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, http.StatusBadRequest, "Dados inválidos: "+err.Error())
+		return
+	}
+	subscription, err := h.webhookService.Update(c.Request.Context(), id, req.subscription())
+	if err != nil {
+		respondWebhookError(c, h.logger, err, "atualizar o webhook")
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.webhookService.Delete(c.Request.Context(), id); err != nil {
+		respondWebhookError(c, h.logger, err, "excluir o webhook")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'limit' deve ser um número entre 1 e 100")
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'page' deve ser um número maior que zero")
+		return
+	}
+	status := c.Query("status")
+	if status != "" && status != domain.DeliveryPending && status != domain.DeliverySucceeded && status != domain.DeliveryDead {
+		RespondWithError(c, http.StatusBadRequest, "O parâmetro 'status' deve ser PENDING, SUCCEEDED ou DEAD")
+		return
+	}
+	deliveries, total, err := h.webhookService.ListDeliveries(c.Request.Context(), id, status, page, limit)
+	if err != nil {
+		respondWebhookError(c, h.logger, err, "listar as entregas do webhook")
+		return
+	}
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.JSON(http.StatusOK, DeliveryListResponse{TotalPages: (total + limit - 1) / limit, Data: deliveries})
+}
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := webhookID(c, "deliveryId")
+	if !ok {
+		return
+	}
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		respondWebhookError(c, h.logger, err, "reenviar a entrega")
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+func webhookID(c *gin.Context, param string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, "ID inválido")
+		return 0, false
+	}
+	return id, true
+}
+func respondWebhookError(c *gin.Context, logger *slog.Logger, err error, action string) {
+	switch err {
+	case domain.ErrWebhookNotFound:
+		RespondWithError(c, http.StatusNotFound, "Webhook não encontrado")
+	case domain.ErrDeliveryNotFound:
+		RespondWithError(c, http.StatusNotFound, "Entrega não encontrada")
+	case domain.ErrInvalidWebhookURL:
+		RespondWithError(c, http.StatusBadRequest, "A URL do webhook deve ser absoluta e usar http ou https")
+	case domain.ErrInvalidWebhookEvents:
+		RespondWithError(c, http.StatusBadRequest, "Informe ao menos um evento entre ItemCreated, ItemUpdated, ItemStockChanged, ItemDeleted e ItemRestored")
+	default:
+		respondInternalError(c, logger, "Falha ao "+action, err)
+	}
+}
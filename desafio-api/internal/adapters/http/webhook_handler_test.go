@@ -0,0 +1,87 @@
+package http
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/events"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/application/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func setupWebhookTest() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	webhookService := service.NewWebhookService(repository.NewMockWebhookRepository(), events.NewWebhookSender(time.Second), 3, logging.Nop())
+	handler := NewWebhookHandler(webhookService, logging.Nop())
+	router := gin.New()
+	router.POST("/webhooks", handler.Create)
+	router.GET("/webhooks", handler.List)
+	router.GET("/webhooks/:id", handler.Get)
+	router.PUT("/webhooks/:id", handler.Update)
+	router.DELETE("/webhooks/:id", handler.Delete)
+	router.GET("/webhooks/:id/deliveries", handler.ListDeliveries)
+	router.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", handler.Redeliver)
+	return router
+}
+func doWebhookRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+func TestWebhooks_SecretIsOnlyReturnedOnCreate(t *testing.T) {
+	router := setupWebhookTest()
+	w := doWebhookRequest(router, "POST", "/webhooks", `{"url":"https://example.com/hook","event_types":["ItemCreated","ItemDeleted"]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created["secret"])
+	assert.Equal(t, true, created["active"])
+	assert.Equal(t, float64(1), created["id"])
+	w = doWebhookRequest(router, "GET", "/webhooks/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+	w = doWebhookRequest(router, "GET", "/webhooks", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created["secret"].(string))
+}
+func TestWebhooks_InvalidRequests(t *testing.T) {
+	router := setupWebhookTest()
+	w := doWebhookRequest(router, "POST", "/webhooks", `{"url":"https://example.com/hook"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doWebhookRequest(router, "POST", "/webhooks", `{"url":"example.com","event_types":["ItemCreated"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doWebhookRequest(router, "POST", "/webhooks", `{"url":"https://example.com","event_types":["Unknown"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doWebhookRequest(router, "GET", "/webhooks/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doWebhookRequest(router, "GET", "/webhooks/9", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doWebhookRequest(router, "GET", "/webhooks/9/deliveries", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doWebhookRequest(router, "POST", "/webhooks/9/deliveries/1/redeliver", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+func TestWebhooks_UpdateListAndDelete(t *testing.T) {
+	router := setupWebhookTest()
+	doWebhookRequest(router, "POST", "/webhooks", `{"url":"https://example.com/hook","event_types":["ItemCreated"]}`)
+	w := doWebhookRequest(router, "PUT", "/webhooks/1", `{"url":"https://example.com/v2","event_types":["ItemUpdated"],"active":false}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"active":false`)
+	assert.Contains(t, w.Body.String(), `"event_types":["ItemUpdated"]`)
+	w = doWebhookRequest(router, "GET", "/webhooks/1/deliveries?status=DEAD", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Total-Count"))
+	w = doWebhookRequest(router, "GET", "/webhooks/1/deliveries?status=LOST", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doWebhookRequest(router, "DELETE", "/webhooks/1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doWebhookRequest(router, "DELETE", "/webhooks/1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,72 @@
+package logging
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"desafio-api/internal/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+type Config struct {
+	Level  string
+	Format string
+}
+// New builds a logger that adds the request id, user id and trace id found
+// in the context of every *Context call, and redacts secrets (see Redact).
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	level := slog.LevelInfo
+	if cfg.Level != "" {
+		_ = level.UnmarshalText([]byte(cfg.Level))
+	}
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: replaceAttr}
+	var handler slog.Handler = slog.NewJSONHandler(w, opts)
+	if strings.EqualFold(cfg.Format, FormatText) {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+func (c Config) Validate() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); c.Level != "" && err != nil {
+		return fmt.Errorf("invalid log level %q (use debug, info, warn or error)", c.Level)
+	}
+	switch strings.ToLower(c.Format) {
+	case "", FormatJSON, FormatText:
+		return nil
+	default:
+		return fmt.Errorf("invalid log format %q (use json or text)", c.Format)
+	}
+}
+// Nop discards everything; handy for tests.
+func Nop() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+type contextHandler struct {
+	slog.Handler
+}
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := domain.RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if userID, ok := domain.UserIDFromContext(ctx); ok {
+		record.AddAttrs(slog.Int("user_id", userID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,76 @@
+package logging
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"desafio-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line), buf.String())
+	return line
+}
+func TestNew_AddsRequestContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Format: FormatJSON})
+	require.NoError(t, err)
+	ctx := domain.ContextWithRequestID(context.Background(), "req-123")
+	ctx = domain.ContextWithUser(ctx, 42, domain.RoleViewer)
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	logger.InfoContext(ctx, "item created", slog.Int64("item_id", 7))
+	line := decodeLine(t, &buf)
+	assert.Equal(t, "item created", line["msg"])
+	assert.Equal(t, "req-123", line["request_id"])
+	assert.Equal(t, float64(42), line["user_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", line["span_id"])
+	assert.Equal(t, float64(7), line["item_id"])
+}
+func TestNew_RedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{})
+	require.NoError(t, err)
+	header := http.Header{"Authorization": {"Bearer abc.def.ghi"}, "Content-Type": {"application/json"}}
+	logger.Info("login",
+		slog.String("password", "hunter2"),
+		slog.String("refresh_token", "r-secret"),
+		slog.String("detail", "sent Bearer abc.def.ghi upstream"),
+		slog.Any("headers", header),
+		slog.Group("request", slog.String("X-Api-Key", "k-secret"), slog.String("username", "alice")),
+	)
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "r-secret", "abc.def.ghi", "k-secret"} {
+		assert.NotContains(t, out, secret)
+	}
+	line := decodeLine(t, &buf)
+	assert.Equal(t, redacted, line["password"])
+	assert.Equal(t, redacted, line["refresh_token"])
+	assert.Equal(t, "sent Bearer "+redacted+" upstream", line["detail"])
+	assert.Equal(t, map[string]interface{}{"Authorization": []interface{}{redacted}, "Content-Type": []interface{}{"application/json"}}, line["headers"])
+	assert.Equal(t, "alice", line["request"].(map[string]interface{})["username"])
+}
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Level: "warn", Format: FormatText})
+	require.NoError(t, err)
+	logger.Info("hidden")
+	logger.Warn("shown")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.True(t, strings.Contains(buf.String(), "msg=shown"), buf.String())
+}
+func TestNew_InvalidConfig(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, Config{Level: "verbose"})
+	assert.Error(t, err)
+	_, err = New(&bytes.Buffer{}, Config{Format: "xml"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package logging
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+const redacted = "[REDACTED]"
+var sensitiveKeySuffixes = []string{"password", "token", "secret", "authorization", "cookie", "api_key"}
+var bearerValue = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`)
+// IsSensitive reports whether values logged under key must be hidden, e.g.
+// password, refresh_token or Authorization.
+func IsSensitive(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, suffix := range sensitiveKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+// Redact hides the value of sensitive keys, the secret part of
+// "Bearer ..." credentials inside strings and the sensitive entries of
+// logged HTTP headers.
+func Redact(attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) && attr.Value.Kind() != slog.KindGroup {
+		return slog.String(attr.Key, redacted)
+	}
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		if value := attr.Value.String(); bearerValue.MatchString(value) {
+			return slog.String(attr.Key, bearerValue.ReplaceAllString(value, "$1 "+redacted))
+		}
+	case slog.KindAny:
+		if header, ok := attr.Value.Any().(http.Header); ok {
+			return slog.Any(attr.Key, redactHeader(header))
+		}
+	}
+	return attr
+}
+func redactHeader(header http.Header) http.Header {
+	clean := make(http.Header, len(header))
+	for name, values := range header {
+		if IsSensitive(name) {
+			values = []string{redacted}
+		}
+		clean[name] = values
+	}
+	return clean
+}
+func replaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	return Redact(attr)
+}
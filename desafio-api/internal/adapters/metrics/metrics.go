@@ -0,0 +1,113 @@
+package metrics
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+	"desafio-api/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+const namespace = "desafio"
+// Login outcomes recorded by ObserveLogin.
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+	LoginLocked  = "locked"
+)
+// Metrics owns a Prometheus registry with the Go runtime and process
+// collectors plus the application metrics. Each instance has its own
+// registry so tests can create as many as they need.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	inFlight        prometheus.Gauge
+	logins          *prometheus.CounterVec
+	items           *prometheus.GaugeVec
+	itemsStock      *prometheus.GaugeVec
+}
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests handled, by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency, by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests currently being served.",
+		}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_logins_total",
+			Help:      "Login attempts, by result (success, failure or locked).",
+		}, []string{"result"}),
+		items: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "items",
+			Help:      "Items not deleted, by status.",
+		}, []string{"status"}),
+		itemsStock: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "items_stock",
+			Help:      "Total stock of the items not deleted, by status.",
+		}, []string{"status"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.inFlight,
+		m.logins,
+		m.items,
+		m.itemsStock,
+	)
+	for _, result := range []string{LoginSuccess, LoginFailure, LoginLocked} {
+		m.logins.WithLabelValues(result)
+	}
+	return m
+}
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+// RegisterDB exports the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+// RequestStarted marks a request as in flight and returns the function that
+// records it once the response status is known.
+func (m *Metrics) RequestStarted(method string) func(route string, status int) {
+	start := time.Now()
+	m.inFlight.Inc()
+	return func(route string, status int) {
+		m.inFlight.Dec()
+		code := strconv.Itoa(status)
+		m.requests.WithLabelValues(method, route, code).Inc()
+		m.requestDuration.WithLabelValues(method, route, code).Observe(time.Since(start).Seconds())
+	}
+}
+func (m *Metrics) ObserveLogin(result string) {
+	m.logins.WithLabelValues(result).Inc()
+}
+// SetItemStats replaces the item gauges, dropping statuses that no longer
+// have items.
+func (m *Metrics) SetItemStats(stats []domain.ItemStatusStats) {
+	m.items.Reset()
+	m.itemsStock.Reset()
+	for _, s := range stats {
+		m.items.WithLabelValues(s.Status).Set(float64(s.Items))
+		m.itemsStock.WithLabelValues(s.Status).Set(float64(s.Stock))
+	}
+}
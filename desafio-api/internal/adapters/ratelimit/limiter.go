@@ -0,0 +1,94 @@
+package ratelimit
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+// Limit is a token bucket holding up to Requests tokens that refills
+// completely every Window. A zero Limit disables limiting.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Window > 0
+}
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Window.Seconds()
+}
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+// ParseLimit reads limits such as "100/1m" or "5/30s"; "off" and "0"
+// disable the limit.
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "off" || value == "0" {
+		return Limit{}, nil
+	}
+	requests, window, found := strings.Cut(value, "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<window>", value)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: bad request count", value)
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: bad window", value)
+	}
+	return Limit{Requests: n, Window: d}, nil
+}
+func (l *Limit) UnmarshalText(text []byte) error {
+	limit, err := ParseLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*l = limit
+	return nil
+}
+func (l Limit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+// Result describes a bucket after a request took a token from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again and RetryAfter how
+	// long until the next token, when the request was not allowed.
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+// Store keeps the buckets. MemoryStore serves a single instance; a shared
+// store (such as Redis) is needed to enforce limits across instances.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+// take applies the token bucket algorithm to a bucket that held tokens at
+// last and returns the tokens left.
+func take(limit Limit, tokens float64, last, now time.Time) (float64, Result) {
+	capacity := float64(limit.Requests)
+	rate := limit.rate()
+	tokens = math.Min(capacity, tokens+now.Sub(last).Seconds()*rate)
+	result := Result{Limit: limit.Requests}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = seconds((capacity - tokens) / rate)
+	return tokens, result
+}
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,94 @@
+package ratelimit
+import (
+	"context"
+	"time"
+)
+// LockState counts the consecutive failures of a key and how long it is
+// locked for.
+type LockState struct {
+	Failures    int
+	LockedUntil time.Time
+}
+type LockoutStore interface {
+	GetLock(ctx context.Context, key string) (LockState, error)
+	SetLock(ctx context.Context, key string, state LockState, ttl time.Duration) error
+	DeleteLock(ctx context.Context, key string) error
+}
+// LockoutPolicy locks a key once it reaches Threshold consecutive failures,
+// for Base, doubling with every further failure up to Max. Failures are
+// forgotten after Memory without new ones.
+type LockoutPolicy struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	Memory    time.Duration
+}
+var DefaultLockoutPolicy = LockoutPolicy{Threshold: 5, Base: time.Minute, Max: time.Hour, Memory: 24 * time.Hour}
+type Lockout struct {
+	store  LockoutStore
+	policy LockoutPolicy
+	now    func() time.Time
+}
+func NewLockout(store LockoutStore, policy LockoutPolicy) *Lockout {
+	return &Lockout{store: store, policy: policy, now: time.Now}
+}
+// Locked returns how long the longest locked of keys remains locked, or zero.
+func (l *Lockout) Locked(ctx context.Context, keys ...string) (time.Duration, error) {
+	var longest time.Duration
+	for _, key := range keys {
+		state, err := l.store.GetLock(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if remaining := state.LockedUntil.Sub(l.now()); remaining > longest {
+			longest = remaining
+		}
+	}
+	return longest, nil
+}
+// Fail records a failure against each of keys and returns how long the
+// longest locked of them is now locked, or zero.
+func (l *Lockout) Fail(ctx context.Context, keys ...string) (time.Duration, error) {
+	var longest time.Duration
+	for _, key := range keys {
+		lockedFor, err := l.fail(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if lockedFor > longest {
+			longest = lockedFor
+		}
+	}
+	return longest, nil
+}
+func (l *Lockout) fail(ctx context.Context, key string) (time.Duration, error) {
+	state, err := l.store.GetLock(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	state.Failures++
+	var lockedFor time.Duration
+	if l.policy.Threshold > 0 && state.Failures >= l.policy.Threshold {
+		lockedFor = l.policy.Base
+		for i := l.policy.Threshold; i < state.Failures && lockedFor < l.policy.Max; i++ {
+			lockedFor *= 2
+		}
+		if lockedFor > l.policy.Max {
+			lockedFor = l.policy.Max
+		}
+		state.LockedUntil = l.now().Add(lockedFor)
+	}
+	ttl := l.policy.Memory
+	if lockedFor > ttl {
+		ttl = lockedFor
+	}
+	return lockedFor, l.store.SetLock(ctx, key, state, ttl)
+}
+func (l *Lockout) Reset(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := l.store.DeleteLock(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package ratelimit
+import (
+	"context"
+	"sync"
+	"time"
+)
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+type lockEntry struct {
+	state   LockState
+	expires time.Time
+}
+// MemoryStore keeps buckets and lockouts in process memory. Prune should run
+// periodically to drop idle entries.
+type MemoryStore struct {
+	buckets map[string]*bucket
+	locks   map[string]*lockEntry
+	now     func() time.Time
+	mu      sync.Mutex
+}
+var _ Store = (*MemoryStore)(nil)
+var _ LockoutStore = (*MemoryStore)(nil)
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), locks: make(map[string]*lockEntry), now: time.Now}
+}
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), last: now}
+		s.buckets[key] = b
+	}
+	tokens, result := take(limit, b.tokens, b.last, now)
+	b.tokens = tokens
+	b.last = now
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+func (s *MemoryStore) GetLock(ctx context.Context, key string) (LockState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.locks[key]
+	if !ok || !entry.expires.After(s.now()) {
+		return LockState{}, nil
+	}
+	return entry.state, nil
+}
+func (s *MemoryStore) SetLock(ctx context.Context, key string, state LockState, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locks[key] = &lockEntry{state: state, expires: s.now().Add(ttl)}
+	return nil
+}
+func (s *MemoryStore) DeleteLock(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.locks, key)
+	return nil
+}
+// Prune drops full buckets and expired lockouts, which behave exactly like
+// missing ones.
+func (s *MemoryStore) Prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for key, b := range s.buckets {
+		if !b.full.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, entry := range s.locks {
+		if !entry.expires.After(now) {
+			delete(s.locks, key)
+		}
+	}
+}
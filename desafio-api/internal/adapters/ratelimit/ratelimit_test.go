@@ -0,0 +1,102 @@
+package ratelimit
+import (
+	"context"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+type fakeClock struct {
+	now time.Time
+}
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("100/1m")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 100, Window: time.Minute}, limit)
+	for _, disabled := range []string{"", "off", "0"} {
+		limit, err := ParseLimit(disabled)
+		require.NoError(t, err)
+		assert.False(t, limit.Enabled())
+	}
+	for _, invalid := range []string{"100", "abc/1m", "10/abc", "10/-1s", "-1/1m"} {
+		_, err := ParseLimit(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+func TestMemoryStore_TokenBucketRefills(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Window: 10 * time.Second}
+	for i := 1; i >= 0; i-- {
+		result, err := store.Take(ctx, "k", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+	result, _ := store.Take(ctx, "k", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 5*time.Second, result.RetryAfter)
+	assert.Equal(t, 10*time.Second, result.Reset)
+	other, _ := store.Take(ctx, "other", limit)
+	assert.True(t, other.Allowed, "buckets are independent")
+	clock.now = clock.now.Add(5 * time.Second)
+	result, _ = store.Take(ctx, "k", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	clock.now = clock.now.Add(time.Hour)
+	store.Prune()
+	assert.Empty(t, store.buckets)
+}
+func TestLockout_IsProgressive(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	lockout := NewLockout(store, LockoutPolicy{Threshold: 3, Base: time.Minute, Max: 3 * time.Minute, Memory: time.Hour})
+	lockout.now = clock.Now
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		lockedFor, err := lockout.Fail(ctx, "user")
+		require.NoError(t, err)
+		assert.Zero(t, lockedFor)
+	}
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		lockedFor, err := lockout.Fail(ctx, "user")
+		require.NoError(t, err)
+		assert.Equal(t, expected, lockedFor)
+		remaining, err := lockout.Locked(ctx, "user")
+		require.NoError(t, err)
+		assert.Equal(t, expected, remaining)
+		clock.now = clock.now.Add(expected)
+		remaining, _ = lockout.Locked(ctx, "user")
+		assert.Zero(t, remaining)
+	}
+	require.NoError(t, lockout.Reset(ctx, "user"))
+	lockedFor, _ := lockout.Fail(ctx, "user")
+	assert.Zero(t, lockedFor, "a successful login clears the failures")
+}
+func TestLockout_SeveralKeysLockOnTheFirstToReachTheThreshold(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	lockout := NewLockout(store, LockoutPolicy{Threshold: 2, Base: time.Minute, Max: time.Hour, Memory: time.Hour})
+	lockout.now = clock.Now
+	ctx := context.Background()
+	_, err := lockout.Fail(ctx, "ip")
+	require.NoError(t, err)
+	lockedFor, err := lockout.Fail(ctx, "user", "ip")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, lockedFor)
+	remaining, err := lockout.Locked(ctx, "other", "ip")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, remaining)
+	remaining, _ = lockout.Locked(ctx, "user")
+	assert.Zero(t, remaining)
+	require.NoError(t, lockout.Reset(ctx, "user", "ip"))
+	remaining, _ = lockout.Locked(ctx, "user", "ip")
+	assert.Zero(t, remaining)
+}
@@ -0,0 +1,106 @@
+package repository
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.IdempotencyRepository = (*idempotencyRepository)(nil)
+const idempotencyColumns = "id, user_id, idempotency_key, fingerprint, status_code, COALESCE(response_headers, '') AS response_headers, response_body, created_at, expires_at"
+type idempotencyRepository struct {
+	db *sqlx.DB
+}
+// idempotencyRow stores the response headers as a JSON object.
+type idempotencyRow struct {
+	ID          int64     `db:"id"`
+	UserID      int       `db:"user_id"`
+	Key         string    `db:"idempotency_key"`
+	Fingerprint string    `db:"fingerprint"`
+	StatusCode  int       `db:"status_code"`
+	Headers     string    `db:"response_headers"`
+	Body        []byte    `db:"response_body"`
+	CreatedAt   time.Time `db:"created_at"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
+func NewIdempotencyRepository(db *sqlx.DB) *idempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) (*domain.IdempotencyRecord, bool, error) {
+	existing, err := r.find(ctx, record.UserID, record.Key)
+	switch {
+	case err == nil && !existing.Replaceable(time.Now(), staleBefore):
+		return existing, false, nil
+	case err == nil:
+		if _, err := database.Conn(ctx, r.db).ExecContext(ctx, r.db.Rebind("DELETE FROM idempotency_keys WHERE id = ?"), existing.ID); err != nil {
+			return nil, false, err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, false, err
+	}
+	query := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, fingerprint, status_code, created_at, expires_at)
+		VALUES (?, ?, ?, 0, ?, ?)`
+	record.CreatedAt = time.Now()
+	id, err := insertReturningID(ctx, database.Conn(ctx, r.db), query, record.UserID, record.Key, record.Fingerprint, record.CreatedAt, record.ExpiresAt)
+	if isUniqueViolation(err) {
+		existing, err := r.find(ctx, record.UserID, record.Key)
+		if err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	record.ID = id
+	return record, true, nil
+}
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+	query := r.db.Rebind("UPDATE idempotency_keys SET status_code = ?, response_headers = ?, response_body = ? WHERE id = ? AND status_code = 0")
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query, record.StatusCode, string(headers), record.Body, record.ID)
+	return err
+}
+func (r *idempotencyRepository) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	query := r.db.Rebind("DELETE FROM idempotency_keys WHERE id = ? AND status_code = 0")
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, record.ID)
+	return err
+}
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, r.db.Rebind("DELETE FROM idempotency_keys WHERE expires_at <= ?"), now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+func (r *idempotencyRepository) find(ctx context.Context, userID int, key string) (*domain.IdempotencyRecord, error) {
+	var row idempotencyRow
+	query := r.db.Rebind("SELECT " + idempotencyColumns + " FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?")
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &row, query, userID, key); err != nil {
+		return nil, err
+	}
+	record := &domain.IdempotencyRecord{
+		ID:          row.ID,
+		UserID:      row.UserID,
+		Key:         row.Key,
+		Fingerprint: row.Fingerprint,
+		StatusCode:  row.StatusCode,
+		Body:        row.Body,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+	}
+	if row.Headers != "" {
+		if err := json.Unmarshal([]byte(row.Headers), &record.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
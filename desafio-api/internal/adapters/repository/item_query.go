@@ -0,0 +1,115 @@
+package repository
+import (
+	"strings"
+	"desafio-api/internal/adapters/database"
+	repoPort "desafio-api/internal/ports/repository"
+)
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+// buildItemQuery translates an ItemFilter into WHERE and ORDER BY clauses
+// written with "?" placeholders; callers rebind them for their dialect.
+func buildItemQuery(dialect string, filter repoPort.ItemFilter) (string, []interface{}, string, []interface{}) {
+	var conditions []string
+	var whereArgs []interface{}
+	add := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		whereArgs = append(whereArgs, arg)
+	}
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if filter.Status != "" {
+		add("status = ?", filter.Status)
+	}
+	if filter.CodePrefix != "" {
+		add("code LIKE ?", likeEscaper.Replace(filter.CodePrefix)+"%")
+	}
+	if filter.MinPrice != nil {
+		add("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		add("price <= ?", *filter.MaxPrice)
+	}
+	if filter.MinStock != nil {
+		add("stock >= ?", *filter.MinStock)
+	}
+	if filter.MaxStock != nil {
+		add("stock <= ?", *filter.MaxStock)
+	}
+	if filter.CreatedBy != nil {
+		add("created_by = ?", *filter.CreatedBy)
+	}
+	if filter.CreatedFrom != nil {
+		add("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		add("updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		add("updated_at < ?", *filter.UpdatedTo)
+	}
+	var orderBy []string
+	var orderArgs []interface{}
+	if terms := repoPort.SearchTerms(filter.Query); len(terms) > 0 {
+		match, query := searchExpression(dialect, terms)
+		add(match, query)
+		if len(filter.Sort) == 0 {
+			orderBy = append(orderBy, relevanceExpression(dialect)+" DESC")
+			orderArgs = append(orderArgs, query)
+		}
+	}
+	sortFields := filter.Sort
+	if len(sortFields) == 0 {
+		sortFields = []repoPort.SortField{{Field: "updated_at", Desc: true}}
+	}
+	hasID := false
+	for _, field := range sortFields {
+		if !repoPort.ItemSortFields[field.Field] {
+			continue
+		}
+		direction := " ASC"
+		if field.Desc {
+			direction = " DESC"
+		}
+		orderBy = append(orderBy, field.Field+direction)
+		hasID = hasID || field.Field == "id"
+	}
+	if !hasID {
+		orderBy = append(orderBy, "id DESC")
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return where, whereArgs, " ORDER BY " + strings.Join(orderBy, ", "), orderArgs
+}
+// buildKeysetQuery is buildItemQuery fixed to the keyset ordering, with the
+// position predicate expanded so the (updated_at, id) index can seek to it.
+func buildKeysetQuery(dialect string, filter repoPort.ItemFilter, after *repoPort.ItemKey) (string, []interface{}, string) {
+	filter.Sort = repoPort.KeysetSort
+	where, args, orderBy, _ := buildItemQuery(dialect, filter)
+	if after != nil {
+		condition := "(updated_at < ? OR (updated_at = ? AND id < ?))"
+		if where == "" {
+			where = " WHERE " + condition
+		} else {
+			where += " AND " + condition
+		}
+		args = append(args, after.UpdatedAt, after.UpdatedAt, after.ID)
+	}
+	return where, args, orderBy
+}
+func searchExpression(dialect string, terms []string) (string, string) {
+	if dialect == database.DriverPostgres {
+		return "search_vector @@ to_tsquery('simple', ?)", strings.Join(terms, ":* & ") + ":*"
+	}
+	return "MATCH(title, description) AGAINST (? IN BOOLEAN MODE)", "+" + strings.Join(terms, "* +") + "*"
+}
+func relevanceExpression(dialect string) string {
+	if dialect == database.DriverPostgres {
+		return "ts_rank(search_vector, to_tsquery('simple', ?))"
+	}
+	return "MATCH(title, description) AGAINST (? IN BOOLEAN MODE)"
+}
@@ -0,0 +1,53 @@
+package repository
+import (
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/database"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/stretchr/testify/assert"
+)
+func TestBuildItemQuery_Defaults(t *testing.T) {
+	where, whereArgs, orderBy, orderArgs := buildItemQuery(database.DriverMySQL, repoPort.ItemFilter{})
+	assert.Equal(t, " WHERE deleted_at IS NULL", where)
+	assert.Empty(t, whereArgs)
+	assert.Equal(t, " ORDER BY updated_at DESC, id DESC", orderBy)
+	assert.Empty(t, orderArgs)
+	where, _, _, _ = buildItemQuery(database.DriverMySQL, repoPort.ItemFilter{IncludeDeleted: true})
+	assert.Equal(t, "", where)
+}
+func TestBuildItemQuery_SearchAndFilters(t *testing.T) {
+	minPrice := int64(100)
+	filter := repoPort.ItemFilter{Status: "ACTIVE", CodePrefix: "A_1%", MinPrice: &minPrice, Query: "Caneta, azul!"}
+	where, whereArgs, orderBy, orderArgs := buildItemQuery(database.DriverMySQL, filter)
+	assert.Equal(t, " WHERE deleted_at IS NULL AND status = ? AND code LIKE ? AND price >= ? AND MATCH(title, description) AGAINST (? IN BOOLEAN MODE)", where)
+	assert.Equal(t, []interface{}{"ACTIVE", `A\_1\%%`, int64(100), "+caneta* +azul*"}, whereArgs)
+	assert.Equal(t, " ORDER BY MATCH(title, description) AGAINST (? IN BOOLEAN MODE) DESC, updated_at DESC, id DESC", orderBy)
+	assert.Equal(t, []interface{}{"+caneta* +azul*"}, orderArgs)
+	where, whereArgs, _, _ = buildItemQuery(database.DriverPostgres, repoPort.ItemFilter{Query: "caneta azul"})
+	assert.Equal(t, " WHERE deleted_at IS NULL AND search_vector @@ to_tsquery('simple', ?)", where)
+	assert.Equal(t, []interface{}{"caneta:* & azul:*"}, whereArgs)
+}
+func TestBuildItemQuery_ExplicitSort(t *testing.T) {
+	sortFields, err := repoPort.ParseSort("-price,title,id")
+	assert.NoError(t, err)
+	_, _, orderBy, orderArgs := buildItemQuery(database.DriverPostgres, repoPort.ItemFilter{Query: "caneta", Sort: sortFields})
+	assert.Equal(t, " ORDER BY price DESC, title ASC, id ASC", orderBy)
+	assert.Empty(t, orderArgs)
+	_, err = repoPort.ParseSort("price;DROP TABLE items")
+	assert.Error(t, err)
+}
+func TestBuildKeysetQuery(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sortFields, _ := repoPort.ParseSort("price")
+	filter := repoPort.ItemFilter{Status: "ACTIVE", Query: "caneta", Sort: sortFields}
+	where, args, orderBy := buildKeysetQuery(database.DriverMySQL, filter, &repoPort.ItemKey{UpdatedAt: at, ID: 42})
+	assert.Equal(t, " WHERE deleted_at IS NULL AND status = ? AND MATCH(title, description) AGAINST (? IN BOOLEAN MODE) AND (updated_at < ? OR (updated_at = ? AND id < ?))", where)
+	assert.Equal(t, []interface{}{"ACTIVE", "+caneta*", at, at, int64(42)}, args)
+	assert.Equal(t, " ORDER BY updated_at DESC, id DESC", orderBy)
+	where, args, _ = buildKeysetQuery(database.DriverPostgres, repoPort.ItemFilter{IncludeDeleted: true}, &repoPort.ItemKey{UpdatedAt: at, ID: 42})
+	assert.Equal(t, " WHERE (updated_at < ? OR (updated_at = ? AND id < ?))", where)
+	assert.Len(t, args, 3)
+	where, args, _ = buildKeysetQuery(database.DriverPostgres, repoPort.ItemFilter{IncludeDeleted: true}, nil)
+	assert.Equal(t, "", where)
+	assert.Empty(t, args)
+}
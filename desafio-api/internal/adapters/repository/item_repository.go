@@ -4,22 +4,27 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+	"desafio-api/internal/adapters/database"
 	"desafio-api/internal/domain"
 	repoPort "desafio-api/internal/ports/repository"
 	"github.com/jmoiron/sqlx"
 )
 var _ repoPort.ItemRepository = (*itemRepository)(nil)
+const mysqlItemColumns = "id, code, title, description, price, stock, status, created_at, updated_at, COALESCE(created_by, 0) AS created_by, COALESCE(updated_by, 0) AS updated_by, version, reserved, deleted_at, COALESCE(deleted_by, 0) AS deleted_by"
 type itemRepository struct {
 	db *sqlx.DB
 }
 func NewItemRepository(db *sqlx.DB) *itemRepository {
 	return &itemRepository{db: db}
 }
+func (r *itemRepository) conn(ctx context.Context) database.Querier {
+	return database.Conn(ctx, r.db)
+}
 func (r *itemRepository) Save(ctx context.Context, item *domain.Item) error {
 	query := `
         INSERT INTO items (code, title, description, price, stock, status, created_at, updated_at, created_by, updated_by)
         VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW(), ?, ?)`
-    result, err := r.db.ExecContext(
+    result, err := r.conn(ctx).ExecContext(
         ctx,
         query,
         item.Code,
@@ -41,14 +46,15 @@ func (r *itemRepository) Save(ctx context.Context, item *domain.Item) error {
     item.ID = id
     item.CreatedAt = time.Now()
     item.UpdatedAt = time.Now()
+    item.Version = 1
     return nil
 }
 func (r *itemRepository) Update(ctx context.Context, item *domain.Item) error {
 	query := `
         UPDATE items 
-        SET code = ?, title = ?, description = ?, price = ?, stock = ?, status = ?, updated_at = NOW(), updated_by = ?
-        WHERE id = ?`
-    _, err := r.db.ExecContext(
+        SET code = ?, title = ?, description = ?, price = ?, stock = ?, status = ?, updated_at = NOW(), updated_by = ?, version = version + 1
+        WHERE id = ? AND version = ? AND deleted_at IS NULL`
+    result, err := r.conn(ctx).ExecContext(
         ctx,
         query,
         item.Code,
@@ -59,49 +65,85 @@ func (r *itemRepository) Update(ctx context.Context, item *domain.Item) error {
         item.Status,
         item.UpdatedBy,
         item.ID,
+        item.Version,
     )
     if err != nil {
         return err
     }
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rowsAffected == 0 {
+        return r.missingOrConflict(ctx, item.ID)
+    }
     item.UpdatedAt = time.Now()
+    item.Version++
     return nil
 }
 func (r *itemRepository) FindByID(ctx context.Context, id int64) (*domain.Item, error) {
 	var item domain.Item
-	query := "SELECT * FROM items WHERE id = ?"
-    err := r.db.GetContext(ctx, &item, query, id)
+	query := "SELECT " + mysqlItemColumns + " FROM items WHERE id = ? AND deleted_at IS NULL"
+    err := r.conn(ctx).GetContext(ctx, &item, query, id)
     if err == sql.ErrNoRows {
         return nil, domain.ErrItemNotFound
     }
     return &item, err
 }
-func (r *itemRepository) FindAll(ctx context.Context, status string, limit, offset int) ([]*domain.Item, int, error) {
+func (r *itemRepository) FindByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Item, error) {
+	var item domain.Item
+	err := r.conn(ctx).GetContext(ctx, &item, "SELECT "+mysqlItemColumns+" FROM items WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrItemNotFound
+	}
+	return &item, err
+}
+func (r *itemRepository) FindByCode(ctx context.Context, code string) (*domain.Item, error) {
+	var item domain.Item
+	err := r.conn(ctx).GetContext(ctx, &item, "SELECT "+mysqlItemColumns+" FROM items WHERE code = ?", code)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrItemNotFound
+	}
+	return &item, err
+}
+func (r *itemRepository) FindAll(ctx context.Context, filter repoPort.ItemFilter, limit, offset int) ([]*domain.Item, int, error) {
 	var items []*domain.Item
 	var count int
-    whereClause := ""
-    var args []interface{}
-    if status != "" {
-        whereClause = " WHERE status = ?"
-        args = append(args, status)
-    }
-    countQuery := "SELECT COUNT(*) FROM items" + whereClause
-    err := r.db.GetContext(ctx, &count, countQuery, args...)
-    if err != nil {
-        return nil, 0, fmt.Errorf("failed to count items: %w", err)
-    }
-    if count == 0 {
-        return []*domain.Item{}, 0, nil
-    }
-    query := "SELECT * FROM items" + whereClause + " ORDER BY updated_at DESC LIMIT ? OFFSET ?"
-    args = append(args, limit, offset)
-    if err := r.db.SelectContext(ctx, &items, query, args...); err != nil {
-        return nil, 0, fmt.Errorf("failed to fetch items: %w", err)
-    }
-    return items, count, nil
+	where, whereArgs, orderBy, orderArgs := buildItemQuery(database.DriverMySQL, filter)
+	if err := r.conn(ctx).GetContext(ctx, &count, "SELECT COUNT(*) FROM items"+where, whereArgs...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count items: %w", err)
+	}
+	if count == 0 {
+		return []*domain.Item{}, 0, nil
+	}
+	query := "SELECT " + mysqlItemColumns + " FROM items" + where + orderBy + " LIMIT ? OFFSET ?"
+	args := append(append(whereArgs, orderArgs...), limit, offset)
+	if err := r.conn(ctx).SelectContext(ctx, &items, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch items: %w", err)
+	}
+	return items, count, nil
+}
+func (r *itemRepository) FindAfter(ctx context.Context, filter repoPort.ItemFilter, after *repoPort.ItemKey, limit int) ([]*domain.Item, error) {
+	items := []*domain.Item{}
+	where, args, orderBy := buildKeysetQuery(database.DriverMySQL, filter, after)
+	query := "SELECT " + mysqlItemColumns + " FROM items" + where + orderBy + " LIMIT ?"
+	if err := r.conn(ctx).SelectContext(ctx, &items, query, append(args, limit)...); err != nil {
+		return nil, fmt.Errorf("failed to fetch items: %w", err)
+	}
+	return items, nil
+}
+func (r *itemRepository) Iterate(ctx context.Context, filter repoPort.ItemFilter, fn func(*domain.Item) error) error {
+	where, whereArgs, orderBy, orderArgs := buildItemQuery(database.DriverMySQL, filter)
+	return iterateItems(ctx, r.conn(ctx), "SELECT "+mysqlItemColumns+" FROM items"+where+orderBy, append(whereArgs, orderArgs...), fn)
 }
-func (r *itemRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM items WHERE id = ?"
-	result, err := r.db.ExecContext(ctx, query, id)
+func (r *itemRepository) Delete(ctx context.Context, id int64, expectedVersion int64, deletedBy int) error {
+	query := "UPDATE items SET deleted_at = NOW(), deleted_by = NULLIF(?, 0), version = version + 1 WHERE id = ? AND deleted_at IS NULL AND reserved = 0"
+	args := []interface{}{deletedBy, id}
+	if expectedVersion > 0 {
+		query += " AND version = ?"
+		args = append(args, expectedVersion)
+	}
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
     if err != nil {
         return err
     }
@@ -110,13 +152,101 @@ func (r *itemRepository) Delete(ctx context.Context, id int64) error {
         return err
     }
     if rowsAffected == 0 {
-        return domain.ErrItemNotFound
+        return r.deleteRefused(ctx, id)
     }
     return nil
 }
+// deleteRefused tells why Delete matched no row.
+func (r *itemRepository) deleteRefused(ctx context.Context, id int64) error {
+	var reserved int
+	err := r.conn(ctx).GetContext(ctx, &reserved, "SELECT reserved FROM items WHERE id = ? AND deleted_at IS NULL", id)
+	switch {
+	case err == sql.ErrNoRows:
+		return domain.ErrItemNotFound
+	case err != nil:
+		return err
+	case reserved > 0:
+		return domain.ErrItemHasReservations
+	}
+	return domain.ErrVersionConflict
+}
+func (r *itemRepository) Restore(ctx context.Context, id int64, restoredBy int) (*domain.Item, error) {
+	query := `
+		UPDATE items
+		SET deleted_at = NULL, deleted_by = NULL, version = version + 1, updated_at = NOW(), updated_by = NULLIF(?, 0)
+		WHERE id = ? AND deleted_at IS NOT NULL`
+	result, err := r.conn(ctx).ExecContext(ctx, query, restoredBy, id)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		if _, err := r.FindByIDIncludingDeleted(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrItemNotDeleted
+	}
+	return r.FindByID(ctx, id)
+}
+func (r *itemRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM items WHERE deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+func (r *itemRepository) StatsByStatus(ctx context.Context) ([]domain.ItemStatusStats, error) {
+	query := `
+		SELECT status, COUNT(*) AS items, COALESCE(SUM(stock), 0) AS stock
+		FROM items
+		WHERE deleted_at IS NULL
+		GROUP BY status
+		ORDER BY status`
+	var stats []domain.ItemStatusStats
+	if err := r.conn(ctx).SelectContext(ctx, &stats, query); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+func (r *itemRepository) AdjustStock(ctx context.Context, id int64, stockDelta, reservedDelta int, updatedBy int) (*domain.Item, error) {
+	// MySQL assigns left to right, so status must read stock before it changes.
+	query := `
+		UPDATE items
+		SET status = CASE WHEN stock + ? > 0 THEN 'ACTIVE' ELSE 'INACTIVE' END,
+			stock = stock + ?, reserved = reserved + ?, version = version + 1, updated_at = NOW(), updated_by = NULLIF(?, 0)
+		WHERE id = ? AND deleted_at IS NULL AND reserved + ? >= 0 AND stock + ? >= reserved + ?`
+	result, err := r.conn(ctx).ExecContext(ctx, query, stockDelta, stockDelta, reservedDelta, updatedBy, id, reservedDelta, stockDelta, reservedDelta)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInsufficientStock
+	}
+	return r.FindByID(ctx, id)
+}
+func (r *itemRepository) missingOrConflict(ctx context.Context, id int64) error {
+	var exists bool
+	if err := r.conn(ctx).GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM items WHERE id = ? AND deleted_at IS NULL)", id); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrVersionConflict
+	}
+	return domain.ErrItemNotFound
+}
 func (r *itemRepository) ExistsByCode(ctx context.Context, code string, excludeID int64) (bool, error) {
 	var exists bool
 	query := "SELECT EXISTS(SELECT 1 FROM items WHERE code = ? AND id != ?)"
-    err := r.db.GetContext(ctx, &exists, query, code, excludeID)
+    err := r.conn(ctx).GetContext(ctx, &exists, query, code, excludeID)
     return exists, err
 }
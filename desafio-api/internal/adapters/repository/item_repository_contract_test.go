@@ -0,0 +1,62 @@
+package repository
+import (
+	"context"
+	"testing"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+// TestItemRepository_Contract checks that the mock, which the service tests
+// rely on, behaves like the SQL repositories.
+func TestItemRepository_Contract(t *testing.T) {
+	t.Run("mock", func(t *testing.T) {
+		testItemRepositoryContract(t, NewMockItemRepository(), 1)
+	})
+	forEachDatabase(t, func(t *testing.T, driver string, db *sqlx.DB) {
+		var repo repoPort.ItemRepository = NewItemRepository(db)
+		if driver == database.DriverPostgres {
+			repo = NewPostgresItemRepository(db)
+		}
+		testItemRepositoryContract(t, repo, createTestUser(t, driver, db))
+	})
+}
+func testItemRepositoryContract(t *testing.T, repo repoPort.ItemRepository, userID int) {
+	t.Run("AdjustStockKeepsStatusInStep", func(t *testing.T) {
+		ctx := context.Background()
+		item := &domain.Item{Code: uniqueName("adjust"), Title: "Item", Price: 100, Stock: 2, Status: "ACTIVE", CreatedBy: userID, UpdatedBy: userID}
+		require.NoError(t, repo.Save(ctx, item))
+		adjusted, err := repo.AdjustStock(ctx, item.ID, 0, 1, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "ACTIVE", adjusted.Status)
+		adjusted, err = repo.AdjustStock(ctx, item.ID, -1, -1, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, adjusted.Stock)
+		assert.Equal(t, "ACTIVE", adjusted.Status)
+		adjusted, err = repo.AdjustStock(ctx, item.ID, -1, 0, userID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, adjusted.Stock)
+		assert.Equal(t, "INACTIVE", adjusted.Status)
+		adjusted, err = repo.AdjustStock(ctx, item.ID, 3, 0, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "ACTIVE", adjusted.Status)
+		assert.Equal(t, item.Version+4, adjusted.Version)
+		_, err = repo.AdjustStock(ctx, item.ID, -4, 0, userID)
+		assert.ErrorIs(t, err, domain.ErrInsufficientStock)
+	})
+	t.Run("DeleteRefusesReservedStock", func(t *testing.T) {
+		ctx := context.Background()
+		item := &domain.Item{Code: uniqueName("delete"), Title: "Item", Price: 100, Stock: 2, Status: "ACTIVE", CreatedBy: userID, UpdatedBy: userID}
+		require.NoError(t, repo.Save(ctx, item))
+		reserved, err := repo.AdjustStock(ctx, item.ID, 0, 1, userID)
+		require.NoError(t, err)
+		assert.ErrorIs(t, repo.Delete(ctx, item.ID, reserved.Version, userID), domain.ErrItemHasReservations)
+		released, err := repo.AdjustStock(ctx, item.ID, 0, -1, userID)
+		require.NoError(t, err)
+		assert.ErrorIs(t, repo.Delete(ctx, item.ID, reserved.Version, userID), domain.ErrVersionConflict)
+		require.NoError(t, repo.Delete(ctx, item.ID, released.Version, userID))
+		assert.ErrorIs(t, repo.Delete(ctx, item.ID, 0, userID), domain.ErrItemNotFound)
+	})
+}
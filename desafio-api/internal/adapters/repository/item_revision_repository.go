@@ -0,0 +1,56 @@
+package repository
+import (
+	"context"
+	"fmt"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.ItemRevisionRepository = (*itemRevisionRepository)(nil)
+const itemRevisionColumns = "id, item_id, action, snapshot, changes, COALESCE(actor_id, 0) AS actor_id, COALESCE(request_id, '') AS request_id, created_at"
+type itemRevisionRepository struct {
+	db *sqlx.DB
+}
+func NewItemRevisionRepository(db *sqlx.DB) *itemRevisionRepository {
+	return &itemRevisionRepository{db: db}
+}
+func (r *itemRevisionRepository) Save(ctx context.Context, revision *domain.ItemRevision) error {
+	query := `
+		INSERT INTO item_revisions (item_id, action, snapshot, changes, actor_id, request_id, created_at)
+		VALUES (?, ?, ?, ?, NULLIF(?, 0), NULLIF(?, ''), ?)`
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = time.Now()
+	}
+	id, err := insertReturningID(ctx, database.Conn(ctx, r.db), query,
+		revision.ItemID,
+		revision.Action,
+		string(revision.Snapshot),
+		string(revision.Changes),
+		revision.ActorID,
+		revision.RequestID,
+		revision.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	revision.ID = id
+	return nil
+}
+func (r *itemRevisionRepository) FindByItem(ctx context.Context, itemID int64, limit, offset int) ([]*domain.ItemRevision, int, error) {
+	conn := database.Conn(ctx, r.db)
+	var count int
+	if err := conn.GetContext(ctx, &count, r.db.Rebind("SELECT COUNT(*) FROM item_revisions WHERE item_id = ?"), itemID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count item revisions: %w", err)
+	}
+	revisions := []*domain.ItemRevision{}
+	if count == 0 {
+		return revisions, 0, nil
+	}
+	query := r.db.Rebind("SELECT " + itemRevisionColumns + " FROM item_revisions WHERE item_id = ? ORDER BY id DESC LIMIT ? OFFSET ?")
+	if err := conn.SelectContext(ctx, &revisions, query, itemID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch item revisions: %w", err)
+	}
+	return revisions, count, nil
+}
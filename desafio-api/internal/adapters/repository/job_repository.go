@@ -0,0 +1,196 @@
+package repository
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.JobRepository = (*jobRepository)(nil)
+const jobColumns = "id, type, status, payload, result, COALESCE(last_error, '') AS last_error, processed, total, attempts, max_attempts, cancel_requested, run_at, COALESCE(locked_by, '') AS locked_by, locked_until, COALESCE(created_by, 0) AS created_by, created_at, started_at, finished_at, updated_at"
+type jobRepository struct {
+	db *sqlx.DB
+}
+func NewJobRepository(db *sqlx.DB) *jobRepository {
+	return &jobRepository{db: db}
+}
+func (r *jobRepository) Save(ctx context.Context, job *domain.Job) error {
+	query := `
+		INSERT INTO jobs (type, status, payload, max_attempts, run_at, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, 0), ?, ?)`
+	now := time.Now()
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = job.CreatedAt
+	}
+	job.UpdatedAt = job.CreatedAt
+	id, err := insertReturningID(ctx, database.Conn(ctx, r.db), query,
+		job.Type,
+		job.Status,
+		string(job.Payload),
+		job.MaxAttempts,
+		job.RunAt,
+		job.CreatedBy,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	job.ID = id
+	return nil
+}
+func (r *jobRepository) FindByID(ctx context.Context, id int64) (*domain.Job, error) {
+	var job domain.Job
+	query := r.db.Rebind("SELECT " + jobColumns + " FROM jobs WHERE id = ?")
+	err := database.Conn(ctx, r.db).GetContext(ctx, &job, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+// Claim uses SKIP LOCKED so concurrent workers, in this or other
+// instances, never pick the same job.
+func (r *jobRepository) Claim(ctx context.Context, workerID string, now, lockedUntil time.Time) (*domain.Job, error) {
+	var claimed *domain.Job
+	err := database.NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+		var id int64
+		query := r.db.Rebind("SELECT id FROM jobs WHERE status = ? AND run_at <= ? ORDER BY run_at, id LIMIT 1 FOR UPDATE SKIP LOCKED")
+		err := conn.GetContext(ctx, &id, query, domain.JobQueued, now)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		update := r.db.Rebind(`
+			UPDATE jobs
+			SET status = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, started_at = COALESCE(started_at, ?), updated_at = ?
+			WHERE id = ?`)
+		if _, err := conn.ExecContext(ctx, update, domain.JobRunning, workerID, lockedUntil, now, now, id); err != nil {
+			return err
+		}
+		claimed, err = r.FindByID(ctx, id)
+		return err
+	})
+	return claimed, err
+}
+func (r *jobRepository) Heartbeat(ctx context.Context, id int64, workerID string, processed, total int, lockedUntil time.Time) (bool, error) {
+	query := r.db.Rebind("UPDATE jobs SET processed = ?, total = ?, locked_until = ?, updated_at = ? WHERE id = ? AND status = ? AND locked_by = ?")
+	if _, err := database.Conn(ctx, r.db).ExecContext(ctx, query, processed, total, lockedUntil, time.Now(), id, domain.JobRunning, workerID); err != nil {
+		return false, err
+	}
+	// Ownership is read back rather than taken from RowsAffected, which a
+	// MySQL connection without clientFoundRows reports as 0 when nothing
+	// changed.
+	var cancelRequested bool
+	err := database.Conn(ctx, r.db).GetContext(ctx, &cancelRequested, r.db.Rebind("SELECT cancel_requested FROM jobs WHERE id = ? AND status = ? AND locked_by = ?"), id, domain.JobRunning, workerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, domain.ErrJobLockLost
+	}
+	return cancelRequested, err
+}
+func (r *jobRepository) Finish(ctx context.Context, job *domain.Job, workerID string) error {
+	query := r.db.Rebind(`
+		UPDATE jobs
+		SET status = ?, attempts = ?, processed = ?, total = ?, result = ?, last_error = NULLIF(?, ''), run_at = ?,
+			finished_at = ?, locked_by = NULL, locked_until = NULL, cancel_requested = FALSE, updated_at = ?
+		WHERE id = ? AND status = ? AND locked_by = ?`)
+	var result interface{}
+	if job.Result != nil {
+		result = string(job.Result)
+	}
+	outcome, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		job.Status, job.Attempts, job.Processed, job.Total, result, job.LastError, job.RunAt,
+		job.FinishedAt, time.Now(), job.ID, domain.JobRunning, workerID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := outcome.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrJobLockLost
+	}
+	return nil
+}
+func (r *jobRepository) RequestCancel(ctx context.Context, id int64, now time.Time) error {
+	conn := database.Conn(ctx, r.db)
+	result, err := conn.ExecContext(ctx, r.db.Rebind("UPDATE jobs SET status = ?, finished_at = ?, updated_at = ? WHERE id = ? AND status = ?"),
+		domain.JobCanceled, now, now, id, domain.JobQueued)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected > 0 {
+		return err
+	}
+	result, err = conn.ExecContext(ctx, r.db.Rebind("UPDATE jobs SET cancel_requested = TRUE, updated_at = ? WHERE id = ? AND status = ?"),
+		now, id, domain.JobRunning)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected > 0 {
+		return err
+	}
+	return r.missingOr(ctx, id, domain.ErrJobNotCancelable)
+}
+func (r *jobRepository) Requeue(ctx context.Context, id int64, now time.Time) error {
+	query := r.db.Rebind(`
+		UPDATE jobs
+		SET status = ?, attempts = 0, processed = 0, result = NULL, last_error = NULL, run_at = ?, finished_at = NULL, updated_at = ?
+		WHERE id = ? AND status IN (?, ?)`)
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, domain.JobQueued, now, now, id, domain.JobFailed, domain.JobCanceled)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected > 0 {
+		return err
+	}
+	return r.missingOr(ctx, id, domain.ErrJobNotRetryable)
+}
+func (r *jobRepository) RequeueStale(ctx context.Context, now time.Time, lastError string) (requeued, failed int, err error) {
+	err = database.NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+		fail := r.db.Rebind(`
+			UPDATE jobs
+			SET status = ?, last_error = ?, finished_at = ?, locked_by = NULL, locked_until = NULL, cancel_requested = FALSE, updated_at = ?
+			WHERE status = ? AND locked_until < ? AND attempts >= max_attempts`)
+		result, err := conn.ExecContext(ctx, fail, domain.JobFailed, lastError, now, now, domain.JobRunning, now)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		failed = int(rowsAffected)
+		requeue := r.db.Rebind(`
+			UPDATE jobs
+			SET status = ?, locked_by = NULL, locked_until = NULL, run_at = ?, updated_at = ?
+			WHERE status = ? AND locked_until < ?`)
+		result, err = conn.ExecContext(ctx, requeue, domain.JobQueued, now, now, domain.JobRunning, now)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = result.RowsAffected()
+		requeued = int(rowsAffected)
+		return err
+	})
+	return requeued, failed, err
+}
+func (r *jobRepository) missingOr(ctx context.Context, id int64, err error) error {
+	if _, findErr := r.FindByID(ctx, id); findErr != nil {
+		return findErr
+	}
+	return err
+}
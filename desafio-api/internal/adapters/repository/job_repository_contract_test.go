@@ -0,0 +1,71 @@
+package repository
+import (
+	"context"
+	"testing"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func TestJobRepository_Contract(t *testing.T) {
+	t.Run("mock", func(t *testing.T) {
+		testJobRepositoryContract(t, NewMockJobRepository())
+	})
+	forEachDatabase(t, func(t *testing.T, driver string, db *sqlx.DB) {
+		testJobRepositoryContract(t, NewJobRepository(db))
+	})
+}
+func testJobRepositoryContract(t *testing.T, repo repoPort.JobRepository) {
+	t.Run("HeartbeatWithoutChanges", func(t *testing.T) {
+		ctx := context.Background()
+		job := claimTestJob(t, repo, "worker-a", 3)
+		lockedUntil := time.Now().Add(time.Minute).Truncate(time.Second)
+		for i := 0; i < 2; i++ {
+			canceled, err := repo.Heartbeat(ctx, job.ID, "worker-a", 1, 2, lockedUntil)
+			require.NoError(t, err, "a repeated heartbeat keeps the lock")
+			assert.False(t, canceled)
+		}
+		_, err := repo.Heartbeat(ctx, job.ID, "worker-b", 1, 2, lockedUntil)
+		assert.ErrorIs(t, err, domain.ErrJobLockLost)
+	})
+	t.Run("RequeueStaleFailsJobsOutOfAttempts", func(t *testing.T) {
+		ctx := context.Background()
+		last := claimTestJob(t, repo, "worker-a", 1)
+		retried := claimTestJob(t, repo, "worker-a", 2)
+		expired := time.Now().Add(-time.Minute)
+		for _, job := range []*domain.Job{last, retried} {
+			_, err := repo.Heartbeat(ctx, job.ID, "worker-a", 0, 0, expired)
+			require.NoError(t, err)
+		}
+		requeued, failed, err := repo.RequeueStale(ctx, time.Now(), "interrompido")
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, requeued, 1)
+		assert.GreaterOrEqual(t, failed, 1)
+		stored, err := repo.FindByID(ctx, last.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.JobFailed, stored.Status)
+		assert.Equal(t, "interrompido", stored.LastError)
+		assert.NotNil(t, stored.FinishedAt)
+		stored, err = repo.FindByID(ctx, retried.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.JobQueued, stored.Status)
+		assert.Empty(t, stored.LockedBy)
+	})
+}
+// claimTestJob queues a job and claims it for workerID, claiming whatever
+// older jobs are still queued on the way.
+func claimTestJob(t *testing.T, repo repoPort.JobRepository, workerID string, maxAttempts int) *domain.Job {
+	ctx := context.Background()
+	job := &domain.Job{Type: "items.export", Status: domain.JobQueued, Payload: []byte(`{}`), MaxAttempts: maxAttempts, RunAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, repo.Save(ctx, job))
+	for {
+		claimed, err := repo.Claim(ctx, workerID, time.Now(), time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.NotNil(t, claimed, "job %d was not claimed", job.ID)
+		if claimed.ID == job.ID {
+			return claimed
+		}
+	}
+}
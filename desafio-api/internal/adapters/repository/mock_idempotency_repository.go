@@ -0,0 +1,69 @@
+package repository
+import (
+	"context"
+	"sync"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+)
+type idempotencyKey struct {
+	userID int
+	key    string
+}
+// MockIdempotencyRepository keeps idempotency records in memory. It is also
+// the store used when the API runs without a database.
+type MockIdempotencyRepository struct {
+	records map[idempotencyKey]*domain.IdempotencyRecord
+	nextID  int64
+	mu      sync.Mutex
+}
+var _ repoPort.IdempotencyRepository = (*MockIdempotencyRepository)(nil)
+func NewMockIdempotencyRepository() *MockIdempotencyRepository {
+	return &MockIdempotencyRepository{records: make(map[idempotencyKey]*domain.IdempotencyRecord), nextID: 1}
+}
+func (r *MockIdempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) (*domain.IdempotencyRecord, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := idempotencyKey{record.UserID, record.Key}
+	if existing, ok := r.records[key]; ok && !existing.Replaceable(time.Now(), staleBefore) {
+		found := *existing
+		return &found, false, nil
+	}
+	record.ID = r.nextID
+	r.nextID++
+	record.CreatedAt = time.Now()
+	stored := *record
+	r.records[key] = &stored
+	return record, true, nil
+}
+func (r *MockIdempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.records[idempotencyKey{record.UserID, record.Key}]; ok && existing.ID == record.ID && !existing.Completed() {
+		existing.StatusCode = record.StatusCode
+		existing.Headers = record.Headers
+		existing.Body = append([]byte(nil), record.Body...)
+	}
+	return nil
+}
+func (r *MockIdempotencyRepository) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := idempotencyKey{record.UserID, record.Key}
+	if existing, ok := r.records[key]; ok && existing.ID == record.ID && !existing.Completed() {
+		delete(r.records, key)
+	}
+	return nil
+}
+func (r *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for key, record := range r.records {
+		if !record.ExpiresAt.After(now) {
+			delete(r.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,47 @@
+package repository
+import (
+	"context"
+	"sync"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+)
+type MockItemRevisionRepository struct {
+	revisions []*domain.ItemRevision
+	mu        sync.RWMutex
+}
+var _ repoPort.ItemRevisionRepository = (*MockItemRevisionRepository)(nil)
+func NewMockItemRevisionRepository() *MockItemRevisionRepository {
+	return &MockItemRevisionRepository{}
+}
+func (r *MockItemRevisionRepository) Save(ctx context.Context, revision *domain.ItemRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revision.ID = int64(len(r.revisions) + 1)
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = time.Now()
+	}
+	stored := *revision
+	r.revisions = append(r.revisions, &stored)
+	return nil
+}
+func (r *MockItemRevisionRepository) FindByItem(ctx context.Context, itemID int64, limit, offset int) ([]*domain.ItemRevision, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matched []*domain.ItemRevision
+	for i := len(r.revisions) - 1; i >= 0; i-- {
+		if r.revisions[i].ItemID == itemID {
+			found := *r.revisions[i]
+			matched = append(matched, &found)
+		}
+	}
+	total := len(matched)
+	if offset >= total {
+		return []*domain.ItemRevision{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matched[offset:end], total, nil
+}
@@ -0,0 +1,170 @@
+package repository
+import (
+	"context"
+	"sync"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+)
+type MockJobRepository struct {
+	jobs   map[int64]*domain.Job
+	nextID int64
+	mu     sync.Mutex
+}
+var _ repoPort.JobRepository = (*MockJobRepository)(nil)
+func NewMockJobRepository() *MockJobRepository {
+	return &MockJobRepository{
+		jobs:   make(map[int64]*domain.Job),
+		nextID: 1,
+	}
+}
+func (r *MockJobRepository) Save(ctx context.Context, job *domain.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job.ID = r.nextID
+	r.nextID++
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = job.CreatedAt
+	}
+	job.UpdatedAt = job.CreatedAt
+	stored := *job
+	r.jobs[job.ID] = &stored
+	return nil
+}
+func (r *MockJobRepository) FindByID(ctx context.Context, id int64) (*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, domain.ErrJobNotFound
+	}
+	found := *job
+	return &found, nil
+}
+func (r *MockJobRepository) Claim(ctx context.Context, workerID string, now, lockedUntil time.Time) (*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var next *domain.Job
+	for _, job := range r.jobs {
+		if job.Status != domain.JobQueued || job.RunAt.After(now) {
+			continue
+		}
+		if next == nil || job.RunAt.Before(next.RunAt) || (job.RunAt.Equal(next.RunAt) && job.ID < next.ID) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	next.Status = domain.JobRunning
+	next.Attempts++
+	next.LockedBy = workerID
+	next.LockedUntil = &lockedUntil
+	if next.StartedAt == nil {
+		next.StartedAt = &now
+	}
+	next.UpdatedAt = now
+	claimed := *next
+	return &claimed, nil
+}
+func (r *MockJobRepository) Heartbeat(ctx context.Context, id int64, workerID string, processed, total int, lockedUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, exists := r.jobs[id]
+	if !exists || job.Status != domain.JobRunning || job.LockedBy != workerID {
+		return false, domain.ErrJobLockLost
+	}
+	job.Processed = processed
+	job.Total = total
+	job.LockedUntil = &lockedUntil
+	job.UpdatedAt = time.Now()
+	return job.CancelRequested, nil
+}
+func (r *MockJobRepository) Finish(ctx context.Context, job *domain.Job, workerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, exists := r.jobs[job.ID]
+	if !exists || stored.Status != domain.JobRunning || stored.LockedBy != workerID {
+		return domain.ErrJobLockLost
+	}
+	stored.Status = job.Status
+	stored.Attempts = job.Attempts
+	stored.Processed = job.Processed
+	stored.Total = job.Total
+	stored.Result = job.Result
+	stored.LastError = job.LastError
+	stored.RunAt = job.RunAt
+	stored.FinishedAt = job.FinishedAt
+	stored.LockedBy = ""
+	stored.LockedUntil = nil
+	stored.CancelRequested = false
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+func (r *MockJobRepository) RequestCancel(ctx context.Context, id int64, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, exists := r.jobs[id]
+	if !exists {
+		return domain.ErrJobNotFound
+	}
+	switch job.Status {
+	case domain.JobQueued:
+		job.Status = domain.JobCanceled
+		job.FinishedAt = &now
+	case domain.JobRunning:
+		job.CancelRequested = true
+	default:
+		return domain.ErrJobNotCancelable
+	}
+	job.UpdatedAt = now
+	return nil
+}
+func (r *MockJobRepository) Requeue(ctx context.Context, id int64, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, exists := r.jobs[id]
+	if !exists {
+		return domain.ErrJobNotFound
+	}
+	if job.Status != domain.JobFailed && job.Status != domain.JobCanceled {
+		return domain.ErrJobNotRetryable
+	}
+	job.Status = domain.JobQueued
+	job.Attempts = 0
+	job.Processed = 0
+	job.Result = nil
+	job.LastError = ""
+	job.RunAt = now
+	job.FinishedAt = nil
+	job.UpdatedAt = now
+	return nil
+}
+func (r *MockJobRepository) RequeueStale(ctx context.Context, now time.Time, lastError string) (requeued, failed int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, job := range r.jobs {
+		if job.Status != domain.JobRunning || job.LockedUntil == nil || !job.LockedUntil.Before(now) {
+			continue
+		}
+		job.LockedBy = ""
+		job.LockedUntil = nil
+		job.UpdatedAt = now
+		if job.Attempts >= job.MaxAttempts {
+			job.Status = domain.JobFailed
+			job.LastError = lastError
+			job.CancelRequested = false
+			finishedAt := now
+			job.FinishedAt = &finishedAt
+			failed++
+			continue
+		}
+		job.Status = domain.JobQueued
+		job.RunAt = now
+		requeued++
+	}
+	return requeued, failed, nil
+}
@@ -0,0 +1,121 @@
+package repository
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+)
+type MockOutboxRepository struct {
+	events map[int64]*domain.OutboxEvent
+	nextID int64
+	mu     sync.RWMutex
+}
+var _ repoPort.OutboxRepository = (*MockOutboxRepository)(nil)
+func NewMockOutboxRepository() *MockOutboxRepository {
+	return &MockOutboxRepository{
+		events: make(map[int64]*domain.OutboxEvent),
+		nextID: 1,
+	}
+}
+func (r *MockOutboxRepository) Save(ctx context.Context, event *domain.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.ID = r.nextID
+	r.nextID++
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = event.OccurredAt
+	}
+	stored := *event
+	r.events[event.ID] = &stored
+	return nil
+}
+func (r *MockOutboxRepository) Claim(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []*domain.OutboxEvent
+	for _, event := range r.events {
+		if event.PublishedAt == nil && !event.NextAttemptAt.After(now) && !r.hasEarlierPending(event) {
+			pending = append(pending, event)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	claimed := make([]*domain.OutboxEvent, len(pending))
+	for i, event := range pending {
+		event.NextAttemptAt = leaseUntil
+		found := *event
+		claimed[i] = &found
+	}
+	return claimed, nil
+}
+func (r *MockOutboxRepository) hasEarlierPending(event *domain.OutboxEvent) bool {
+	for _, other := range r.events {
+		if other.PublishedAt == nil && other.ID < event.ID && other.AggregateType == event.AggregateType && other.AggregateID == event.AggregateID {
+			return true
+		}
+	}
+	return false
+}
+func (r *MockOutboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if event, exists := r.events[id]; exists {
+		event.PublishedAt = &publishedAt
+		event.Attempts++
+		event.LastError = ""
+	}
+	return nil
+}
+func (r *MockOutboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if event, exists := r.events[id]; exists {
+		event.Attempts++
+		event.LastError = lastError
+		event.NextAttemptAt = nextAttemptAt
+	}
+	return nil
+}
+func (r *MockOutboxRepository) CountPending(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, event := range r.events {
+		if event.PublishedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+func (r *MockOutboxRepository) OldestPending(ctx context.Context) (*time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var oldest *time.Time
+	for _, event := range r.events {
+		if event.PublishedAt == nil && (oldest == nil || event.OccurredAt.Before(*oldest)) {
+			occurredAt := event.OccurredAt
+			oldest = &occurredAt
+		}
+	}
+	return oldest, nil
+}
+// Events returns every stored event in insertion order, for assertions in
+// tests.
+func (r *MockOutboxRepository) Events() []*domain.OutboxEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	events := make([]*domain.OutboxEvent, 0, len(r.events))
+	for _, event := range r.events {
+		found := *event
+		events = append(events, &found)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
@@ -0,0 +1,76 @@
+package repository
+import (
+	"context"
+	"sync"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+)
+type MockRefreshTokenRepository struct {
+	tokens map[int64]*domain.RefreshToken
+	nextID int64
+	mu     sync.RWMutex
+}
+var _ repoPort.RefreshTokenRepository = (*MockRefreshTokenRepository)(nil)
+func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{
+		tokens: make(map[int64]*domain.RefreshToken),
+		nextID: 1,
+	}
+}
+func (r *MockRefreshTokenRepository) Save(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = r.nextID
+	r.nextID++
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+func (r *MockRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrInvalidRefreshToken
+}
+func (r *MockRefreshTokenRepository) MarkUsed(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, exists := r.tokens[id]
+	if !exists || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &usedAt
+	return true, nil
+}
+func (r *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			revoked := revokedAt
+			token.RevokedAt = &revoked
+		}
+	}
+	return nil
+}
+func (r *MockRefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -1,6 +1,8 @@
 package repository
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 	"desafio-api/internal/application/service"
@@ -30,42 +32,83 @@ func (r *MockItemRepository) Save(ctx context.Context, item *domain.Item) error
 	r.nextID++
 	item.CreatedAt = time.Now()
 	item.UpdatedAt = time.Now()
-	r.items[item.ID] = item
+	item.Version = 1
+	stored := *item
+	r.items[item.ID] = &stored
 	return nil
 }
 func (r *MockItemRepository) Update(ctx context.Context, item *domain.Item) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, exists := r.items[item.ID]; !exists {
+	current, exists := r.items[item.ID]
+	if !exists || current.IsDeleted() {
 		return domain.ErrItemNotFound
 	}
+	if current.Version != item.Version {
+		return domain.ErrVersionConflict
+	}
 	for id, existingItem := range r.items {
 		if existingItem.Code == item.Code && id != item.ID {
 			return domain.ErrDuplicateCode
 		}
 	}
 	item.UpdatedAt = time.Now()
-	r.items[item.ID] = item
+	item.Version++
+	stored := *item
+	r.items[item.ID] = &stored
 	return nil
 }
 func (r *MockItemRepository) FindByID(ctx context.Context, id int64) (*domain.Item, error) {
+	item, err := r.FindByIDIncludingDeleted(ctx, id)
+	if err == nil && item.IsDeleted() {
+		return nil, domain.ErrItemNotFound
+	}
+	return item, err
+}
+func (r *MockItemRepository) FindByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Item, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	item, exists := r.items[id]
 	if !exists {
 		return nil, domain.ErrItemNotFound
 	}
-	return item, nil
+	found := *item
+	return &found, nil
 }
-func (r *MockItemRepository) FindAll(ctx context.Context, status string, limit, offset int) ([]*domain.Item, int, error) {
+func (r *MockItemRepository) FindByCode(ctx context.Context, code string) (*domain.Item, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	for _, item := range r.items {
+		if item.Code == code {
+			found := *item
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrItemNotFound
+}
+func (r *MockItemRepository) FindAll(ctx context.Context, filter repoPort.ItemFilter, limit, offset int) ([]*domain.Item, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	terms := repoPort.SearchTerms(filter.Query)
 	var filteredItems []*domain.Item
 	for _, item := range r.items {
-		if status == "" || item.Status == status {
-			filteredItems = append(filteredItems, item)
+		if matchesItemFilter(item, filter, terms) {
+			found := *item
+			filteredItems = append(filteredItems, &found)
 		}
 	}
+	sortFields := filter.Sort
+	if len(sortFields) == 0 {
+		sortFields = []repoPort.SortField{{Field: "updated_at", Desc: true}}
+	}
+	sort.SliceStable(filteredItems, func(i, j int) bool {
+		for _, field := range sortFields {
+			if cmp := compareItemField(filteredItems[i], filteredItems[j], field.Field); cmp != 0 {
+				return (cmp < 0) != field.Desc
+			}
+		}
+		return filteredItems[i].ID > filteredItems[j].ID
+	})
 	total := len(filteredItems)
 	if offset >= total {
 		return []*domain.Item{}, total, nil
@@ -77,15 +120,114 @@ func (r *MockItemRepository) FindAll(ctx context.Context, status string, limit,
 	paginatedItems := filteredItems[offset:end]
 	return paginatedItems, total, nil
 }
-func (r *MockItemRepository) Delete(ctx context.Context, id int64) error {
+func (r *MockItemRepository) FindAfter(ctx context.Context, filter repoPort.ItemFilter, after *repoPort.ItemKey, limit int) ([]*domain.Item, error) {
+	filter.Sort = repoPort.KeysetSort
+	r.mu.RLock()
+	count := len(r.items)
+	r.mu.RUnlock()
+	all, _, err := r.FindAll(ctx, filter, count, 0)
+	if err != nil {
+		return nil, err
+	}
+	items := []*domain.Item{}
+	for _, item := range all {
+		if len(items) == limit {
+			break
+		}
+		if after != nil && !isAfterKey(item, *after) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+func (r *MockItemRepository) Iterate(ctx context.Context, filter repoPort.ItemFilter, fn func(*domain.Item) error) error {
+	r.mu.RLock()
+	count := len(r.items)
+	r.mu.RUnlock()
+	items, _, err := r.FindAll(ctx, filter, count, 0)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (r *MockItemRepository) Delete(ctx context.Context, id int64, expectedVersion int64, deletedBy int) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, exists := r.items[id]; !exists {
+	item, exists := r.items[id]
+	if !exists || item.IsDeleted() {
 		return domain.ErrItemNotFound
 	}
-	delete(r.items, id)
+	if expectedVersion > 0 && item.Version != expectedVersion {
+		return domain.ErrVersionConflict
+	}
+	if item.Reserved > 0 {
+		return domain.ErrItemHasReservations
+	}
+	now := time.Now()
+	item.DeletedAt = &now
+	item.DeletedBy = deletedBy
+	item.Version++
 	return nil
 }
+func (r *MockItemRepository) Restore(ctx context.Context, id int64, restoredBy int) (*domain.Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, exists := r.items[id]
+	if !exists {
+		return nil, domain.ErrItemNotFound
+	}
+	if !item.IsDeleted() {
+		return nil, domain.ErrItemNotDeleted
+	}
+	item.DeletedAt = nil
+	item.DeletedBy = 0
+	item.UpdatedBy = restoredBy
+	item.UpdatedAt = time.Now()
+	item.Version++
+	found := *item
+	return &found, nil
+}
+func (r *MockItemRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var purged int64
+	for id, item := range r.items {
+		if item.IsDeleted() && item.DeletedAt.Before(deletedBefore) {
+			delete(r.items, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+func (r *MockItemRepository) StatsByStatus(ctx context.Context) ([]domain.ItemStatusStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	byStatus := make(map[string]*domain.ItemStatusStats)
+	for _, item := range r.items {
+		if item.IsDeleted() {
+			continue
+		}
+		s, ok := byStatus[item.Status]
+		if !ok {
+			s = &domain.ItemStatusStats{Status: item.Status}
+			byStatus[item.Status] = s
+		}
+		s.Items++
+		s.Stock += int64(item.Stock)
+	}
+	stats := make([]domain.ItemStatusStats, 0, len(byStatus))
+	for _, s := range byStatus {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Status < stats[j].Status })
+	return stats, nil
+}
 func (r *MockItemRepository) ExistsByCode(ctx context.Context, code string, excludeID int64) (bool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -96,6 +238,96 @@ func (r *MockItemRepository) ExistsByCode(ctx context.Context, code string, excl
 	}
 	return false, nil
 }
+func (r *MockItemRepository) AdjustStock(ctx context.Context, id int64, stockDelta, reservedDelta int, updatedBy int) (*domain.Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, exists := r.items[id]
+	if !exists || item.IsDeleted() {
+		return nil, domain.ErrItemNotFound
+	}
+	stock := item.Stock + stockDelta
+	reserved := item.Reserved + reservedDelta
+	if reserved < 0 || stock < reserved {
+		return nil, domain.ErrInsufficientStock
+	}
+	item.Stock = stock
+	item.Reserved = reserved
+	if item.Stock > 0 {
+		item.Status = "ACTIVE"
+	} else {
+		item.Status = "INACTIVE"
+	}
+	item.UpdatedBy = updatedBy
+	item.UpdatedAt = time.Now()
+	item.Version++
+	found := *item
+	return &found, nil
+}
+func matchesItemFilter(item *domain.Item, filter repoPort.ItemFilter, terms []string) bool {
+	switch {
+	case !filter.IncludeDeleted && item.IsDeleted(),
+		filter.Status != "" && item.Status != filter.Status,
+		filter.CodePrefix != "" && !strings.HasPrefix(item.Code, filter.CodePrefix),
+		filter.MinPrice != nil && item.Price < *filter.MinPrice,
+		filter.MaxPrice != nil && item.Price > *filter.MaxPrice,
+		filter.MinStock != nil && item.Stock < *filter.MinStock,
+		filter.MaxStock != nil && item.Stock > *filter.MaxStock,
+		filter.CreatedBy != nil && item.CreatedBy != *filter.CreatedBy,
+		filter.CreatedFrom != nil && item.CreatedAt.Before(*filter.CreatedFrom),
+		filter.CreatedTo != nil && !item.CreatedAt.Before(*filter.CreatedTo),
+		filter.UpdatedFrom != nil && item.UpdatedAt.Before(*filter.UpdatedFrom),
+		filter.UpdatedTo != nil && !item.UpdatedAt.Before(*filter.UpdatedTo):
+		return false
+	}
+	words := repoPort.SearchTerms(item.Title + " " + item.Description)
+	for _, term := range terms {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+func isAfterKey(item *domain.Item, key repoPort.ItemKey) bool {
+	if cmp := item.UpdatedAt.Compare(key.UpdatedAt); cmp != 0 {
+		return cmp < 0
+	}
+	return item.ID < key.ID
+}
+func compareItemField(a, b *domain.Item, field string) int {
+	switch field {
+	case "id":
+		return compareInt64(a.ID, b.ID)
+	case "code":
+		return strings.Compare(strings.ToLower(a.Code), strings.ToLower(b.Code))
+	case "title":
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case "price":
+		return compareInt64(a.Price, b.Price)
+	case "stock":
+		return compareInt64(int64(a.Stock), int64(b.Stock))
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
 type MockUserRepository struct {
 	users  map[int]*domain.User
 	nextID int
@@ -142,3 +374,25 @@ func (r *MockUserRepository) FindByID(ctx context.Context, id int) (*domain.User
 	}
 	return user, nil
 }
+func (r *MockUserRepository) FindAll(ctx context.Context) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]*domain.User, 0, len(r.users))
+	for id := 1; id < r.nextID; id++ {
+		if user, exists := r.users[id]; exists {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+func (r *MockUserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, exists := r.users[id]
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,47 @@
+package repository
+import (
+	"context"
+	"sync"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+)
+type MockStockMovementRepository struct {
+	movements []*domain.StockMovement
+	mu        sync.RWMutex
+}
+var _ repoPort.StockMovementRepository = (*MockStockMovementRepository)(nil)
+func NewMockStockMovementRepository() *MockStockMovementRepository {
+	return &MockStockMovementRepository{}
+}
+func (r *MockStockMovementRepository) Save(ctx context.Context, movement *domain.StockMovement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	movement.ID = int64(len(r.movements) + 1)
+	if movement.CreatedAt.IsZero() {
+		movement.CreatedAt = time.Now()
+	}
+	stored := *movement
+	r.movements = append(r.movements, &stored)
+	return nil
+}
+func (r *MockStockMovementRepository) FindByItem(ctx context.Context, itemID int64, limit, offset int) ([]*domain.StockMovement, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matched []*domain.StockMovement
+	for i := len(r.movements) - 1; i >= 0; i-- {
+		if r.movements[i].ItemID == itemID {
+			found := *r.movements[i]
+			matched = append(matched, &found)
+		}
+	}
+	total := len(matched)
+	if offset >= total {
+		return []*domain.StockMovement{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matched[offset:end], total, nil
+}
@@ -0,0 +1,73 @@
+package repository
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+)
+type MockStockReservationRepository struct {
+	reservations map[int64]*domain.StockReservation
+	nextID       int64
+	mu           sync.RWMutex
+}
+var _ repoPort.StockReservationRepository = (*MockStockReservationRepository)(nil)
+func NewMockStockReservationRepository() *MockStockReservationRepository {
+	return &MockStockReservationRepository{
+		reservations: make(map[int64]*domain.StockReservation),
+		nextID:       1,
+	}
+}
+func (r *MockStockReservationRepository) Save(ctx context.Context, reservation *domain.StockReservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reservation.ID = r.nextID
+	r.nextID++
+	if reservation.CreatedAt.IsZero() {
+		reservation.CreatedAt = time.Now()
+	}
+	reservation.UpdatedAt = reservation.CreatedAt
+	stored := *reservation
+	r.reservations[reservation.ID] = &stored
+	return nil
+}
+func (r *MockStockReservationRepository) FindByID(ctx context.Context, id int64) (*domain.StockReservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reservation, exists := r.reservations[id]
+	if !exists {
+		return nil, domain.ErrReservationNotFound
+	}
+	found := *reservation
+	return &found, nil
+}
+func (r *MockStockReservationRepository) UpdateStatus(ctx context.Context, id int64, from, to string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reservation, exists := r.reservations[id]
+	if !exists || reservation.Status != from {
+		return false, nil
+	}
+	reservation.Status = to
+	reservation.UpdatedAt = time.Now()
+	return true, nil
+}
+func (r *MockStockReservationRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*domain.StockReservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	expired := []*domain.StockReservation{}
+	for _, reservation := range r.reservations {
+		if reservation.IsExpired(now) {
+			found := *reservation
+			expired = append(expired, &found)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].ExpiresAt.Before(expired[j].ExpiresAt)
+	})
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
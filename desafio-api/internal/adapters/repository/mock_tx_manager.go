@@ -0,0 +1,15 @@
+package repository
+import (
+	"context"
+	repoPort "desafio-api/internal/ports/repository"
+)
+// MockTxManager runs fn directly; the in-memory repositories have no
+// rollback, so it only satisfies the port for demos and tests.
+type MockTxManager struct{}
+var _ repoPort.TxManager = MockTxManager{}
+func NewMockTxManager() MockTxManager {
+	return MockTxManager{}
+}
+func (MockTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,166 @@
+package repository
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+)
+type MockWebhookRepository struct {
+	subscriptions      map[int64]*domain.WebhookSubscription
+	deliveries         map[int64]*domain.WebhookDelivery
+	nextSubscriptionID int64
+	nextDeliveryID     int64
+	mu                 sync.RWMutex
+}
+var _ repoPort.WebhookRepository = (*MockWebhookRepository)(nil)
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{
+		subscriptions:      make(map[int64]*domain.WebhookSubscription),
+		deliveries:         make(map[int64]*domain.WebhookDelivery),
+		nextSubscriptionID: 1,
+		nextDeliveryID:     1,
+	}
+}
+func copySubscription(subscription *domain.WebhookSubscription) *domain.WebhookSubscription {
+	copied := *subscription
+	copied.EventTypes = append([]string(nil), subscription.EventTypes...)
+	return &copied
+}
+func (r *MockWebhookRepository) SaveSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	subscription.ID = r.nextSubscriptionID
+	r.nextSubscriptionID++
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+	r.subscriptions[subscription.ID] = copySubscription(subscription)
+	return nil
+}
+func (r *MockWebhookRepository) UpdateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.subscriptions[subscription.ID]; !exists {
+		return domain.ErrWebhookNotFound
+	}
+	subscription.UpdatedAt = time.Now()
+	r.subscriptions[subscription.ID] = copySubscription(subscription)
+	return nil
+}
+func (r *MockWebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.subscriptions[id]; !exists {
+		return domain.ErrWebhookNotFound
+	}
+	delete(r.subscriptions, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.SubscriptionID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+func (r *MockWebhookRepository) FindSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subscription, exists := r.subscriptions[id]
+	if !exists {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return copySubscription(subscription), nil
+}
+func (r *MockWebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subscriptions := make([]*domain.WebhookSubscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, copySubscription(subscription))
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].ID < subscriptions[j].ID })
+	return subscriptions, nil
+}
+func (r *MockWebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.deliveries {
+		if existing.SubscriptionID == delivery.SubscriptionID && existing.EventID == delivery.EventID {
+			delivery.ID = existing.ID
+			return nil
+		}
+	}
+	delivery.ID = r.nextDeliveryID
+	r.nextDeliveryID++
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = delivery.CreatedAt
+	}
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+func (r *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.deliveries[delivery.ID]; !exists {
+		return domain.ErrDeliveryNotFound
+	}
+	delivery.UpdatedAt = time.Now()
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+func (r *MockWebhookRepository) FindDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	delivery, exists := r.deliveries[id]
+	if !exists {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	found := *delivery
+	return &found, nil
+}
+func (r *MockWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, status string, limit, offset int) ([]*domain.WebhookDelivery, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matching []*domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID && (status == "" || delivery.Status == status) {
+			found := *delivery
+			matching = append(matching, &found)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool { return matching[i].ID > matching[j].ID })
+	total := len(matching)
+	if offset >= total {
+		return []*domain.WebhookDelivery{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matching[offset:end], total, nil
+}
+func (r *MockWebhookRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == domain.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]*domain.WebhookDelivery, 0, len(due))
+	for _, delivery := range due {
+		delivery.NextAttemptAt = leaseUntil
+		found := *delivery
+		claimed = append(claimed, &found)
+	}
+	return claimed, nil
+}
@@ -0,0 +1,98 @@
+package repository
+import (
+	"context"
+	"database/sql"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.OutboxRepository = (*outboxRepository)(nil)
+const outboxColumns = "id, aggregate_type, aggregate_id, event_type, payload, occurred_at, published_at, attempts, COALESCE(last_error, '') AS last_error, next_attempt_at"
+type outboxRepository struct {
+	db *sqlx.DB
+}
+func NewOutboxRepository(db *sqlx.DB) *outboxRepository {
+	return &outboxRepository{db: db}
+}
+func (r *outboxRepository) Save(ctx context.Context, event *domain.OutboxEvent) error {
+	query := `
+		INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, occurred_at, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = event.OccurredAt
+	}
+	id, err := insertReturningID(ctx, database.Conn(ctx, r.db), query,
+		event.AggregateType,
+		event.AggregateID,
+		event.Type,
+		string(event.Payload),
+		event.OccurredAt,
+		event.NextAttemptAt,
+	)
+	if err != nil {
+		return err
+	}
+	event.ID = id
+	return nil
+}
+func (r *outboxRepository) Claim(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.OutboxEvent, error) {
+	events := []*domain.OutboxEvent{}
+	err := database.NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+		query := `
+			SELECT ` + outboxColumns + ` FROM outbox
+			WHERE published_at IS NULL AND next_attempt_at <= ?
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox earlier
+					WHERE earlier.aggregate_type = outbox.aggregate_type AND earlier.aggregate_id = outbox.aggregate_id
+						AND earlier.published_at IS NULL AND earlier.id < outbox.id
+				)
+			ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED`
+		if err := conn.SelectContext(ctx, &events, r.db.Rebind(query), now, limit); err != nil || len(events) == 0 {
+			return err
+		}
+		ids := make([]int64, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+			event.NextAttemptAt = leaseUntil
+		}
+		update, args, err := sqlx.In("UPDATE outbox SET next_attempt_at = ? WHERE id IN (?)", leaseUntil, ids)
+		if err != nil {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, r.db.Rebind(update), args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	query := r.db.Rebind("UPDATE outbox SET published_at = ?, attempts = attempts + 1, last_error = NULL WHERE id = ?")
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, publishedAt, id)
+	return err
+}
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	query := r.db.Rebind("UPDATE outbox SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?")
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, lastError, nextAttemptAt, id)
+	return err
+}
+func (r *outboxRepository) CountPending(ctx context.Context) (int, error) {
+	var count int
+	err := database.Conn(ctx, r.db).GetContext(ctx, &count, "SELECT COUNT(*) FROM outbox WHERE published_at IS NULL")
+	return count, err
+}
+func (r *outboxRepository) OldestPending(ctx context.Context) (*time.Time, error) {
+	var oldest sql.NullTime
+	err := database.Conn(ctx, r.db).GetContext(ctx, &oldest, "SELECT MIN(occurred_at) FROM outbox WHERE published_at IS NULL")
+	if err != nil || !oldest.Valid {
+		return nil, err
+	}
+	return &oldest.Time, nil
+}
@@ -0,0 +1,255 @@
+package repository
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.ItemRepository = (*postgresItemRepository)(nil)
+const postgresItemColumns = "id, code, title, description, price, stock, status, created_at, updated_at, COALESCE(created_by, 0) AS created_by, COALESCE(updated_by, 0) AS updated_by, version, reserved, deleted_at, COALESCE(deleted_by, 0) AS deleted_by"
+type postgresItemRepository struct {
+	db *sqlx.DB
+}
+func NewPostgresItemRepository(db *sqlx.DB) *postgresItemRepository {
+	return &postgresItemRepository{db: db}
+}
+func (r *postgresItemRepository) conn(ctx context.Context) database.Querier {
+	return database.Conn(ctx, r.db)
+}
+func (r *postgresItemRepository) Save(ctx context.Context, item *domain.Item) error {
+	query := `
+		INSERT INTO items (code, title, description, price, stock, status, created_at, updated_at, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW(), NULLIF($7, 0), NULLIF($8, 0))
+		RETURNING id, created_at, updated_at, version`
+	err := r.conn(ctx).QueryRowxContext(
+		ctx,
+		query,
+		item.Code,
+		item.Title,
+		item.Description,
+		item.Price,
+		item.Stock,
+		item.Status,
+		item.CreatedBy,
+		item.UpdatedBy,
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt, &item.Version)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateCode
+		}
+		return err
+	}
+	return nil
+}
+func (r *postgresItemRepository) Update(ctx context.Context, item *domain.Item) error {
+	query := `
+		UPDATE items
+		SET code = $1, title = $2, description = $3, price = $4, stock = $5, status = $6, updated_at = NOW(), updated_by = NULLIF($7, 0), version = version + 1
+		WHERE id = $8 AND version = $9 AND deleted_at IS NULL
+		RETURNING updated_at, version`
+	err := r.conn(ctx).QueryRowxContext(
+		ctx,
+		query,
+		item.Code,
+		item.Title,
+		item.Description,
+		item.Price,
+		item.Stock,
+		item.Status,
+		item.UpdatedBy,
+		item.ID,
+		item.Version,
+	).Scan(&item.UpdatedAt, &item.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.missingOrConflict(ctx, item.ID)
+		}
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateCode
+		}
+		return err
+	}
+	return nil
+}
+func (r *postgresItemRepository) FindByID(ctx context.Context, id int64) (*domain.Item, error) {
+	var item domain.Item
+	query := "SELECT " + postgresItemColumns + " FROM items WHERE id = $1 AND deleted_at IS NULL"
+	err := r.conn(ctx).GetContext(ctx, &item, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+func (r *postgresItemRepository) FindByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Item, error) {
+	var item domain.Item
+	query := "SELECT " + postgresItemColumns + " FROM items WHERE id = $1"
+	err := r.conn(ctx).GetContext(ctx, &item, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+func (r *postgresItemRepository) FindByCode(ctx context.Context, code string) (*domain.Item, error) {
+	var item domain.Item
+	query := "SELECT " + postgresItemColumns + " FROM items WHERE code = $1"
+	err := r.conn(ctx).GetContext(ctx, &item, query, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+func (r *postgresItemRepository) FindAll(ctx context.Context, filter repoPort.ItemFilter, limit, offset int) ([]*domain.Item, int, error) {
+	var items []*domain.Item
+	var count int
+	where, whereArgs, orderBy, orderArgs := buildItemQuery(database.DriverPostgres, filter)
+	countQuery := sqlx.Rebind(sqlx.DOLLAR, "SELECT COUNT(*) FROM items"+where)
+	if err := r.conn(ctx).GetContext(ctx, &count, countQuery, whereArgs...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count items: %w", err)
+	}
+	if count == 0 {
+		return []*domain.Item{}, 0, nil
+	}
+	query := sqlx.Rebind(sqlx.DOLLAR, "SELECT "+postgresItemColumns+" FROM items"+where+orderBy+" LIMIT ? OFFSET ?")
+	args := append(append(whereArgs, orderArgs...), limit, offset)
+	if err := r.conn(ctx).SelectContext(ctx, &items, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch items: %w", err)
+	}
+	return items, count, nil
+}
+func (r *postgresItemRepository) FindAfter(ctx context.Context, filter repoPort.ItemFilter, after *repoPort.ItemKey, limit int) ([]*domain.Item, error) {
+	items := []*domain.Item{}
+	where, args, orderBy := buildKeysetQuery(database.DriverPostgres, filter, after)
+	query := sqlx.Rebind(sqlx.DOLLAR, "SELECT "+postgresItemColumns+" FROM items"+where+orderBy+" LIMIT ?")
+	if err := r.conn(ctx).SelectContext(ctx, &items, query, append(args, limit)...); err != nil {
+		return nil, fmt.Errorf("failed to fetch items: %w", err)
+	}
+	return items, nil
+}
+func (r *postgresItemRepository) Iterate(ctx context.Context, filter repoPort.ItemFilter, fn func(*domain.Item) error) error {
+	where, whereArgs, orderBy, orderArgs := buildItemQuery(database.DriverPostgres, filter)
+	query := sqlx.Rebind(sqlx.DOLLAR, "SELECT "+postgresItemColumns+" FROM items"+where+orderBy)
+	return iterateItems(ctx, r.conn(ctx), query, append(whereArgs, orderArgs...), fn)
+}
+func (r *postgresItemRepository) Delete(ctx context.Context, id int64, expectedVersion int64, deletedBy int) error {
+	query := "UPDATE items SET deleted_at = NOW(), deleted_by = NULLIF($1, 0), version = version + 1 WHERE id = $2 AND deleted_at IS NULL AND reserved = 0"
+	args := []interface{}{deletedBy, id}
+	if expectedVersion > 0 {
+		query += " AND version = $3"
+		args = append(args, expectedVersion)
+	}
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return r.deleteRefused(ctx, id)
+	}
+	return nil
+}
+// deleteRefused tells why Delete matched no row.
+func (r *postgresItemRepository) deleteRefused(ctx context.Context, id int64) error {
+	var reserved int
+	err := r.conn(ctx).GetContext(ctx, &reserved, "SELECT reserved FROM items WHERE id = $1 AND deleted_at IS NULL", id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.ErrItemNotFound
+	case err != nil:
+		return err
+	case reserved > 0:
+		return domain.ErrItemHasReservations
+	}
+	return domain.ErrVersionConflict
+}
+func (r *postgresItemRepository) Restore(ctx context.Context, id int64, restoredBy int) (*domain.Item, error) {
+	query := `
+		UPDATE items
+		SET deleted_at = NULL, deleted_by = NULL, version = version + 1, updated_at = NOW(), updated_by = NULLIF($1, 0)
+		WHERE id = $2 AND deleted_at IS NOT NULL
+		RETURNING ` + postgresItemColumns
+	var item domain.Item
+	err := r.conn(ctx).GetContext(ctx, &item, query, restoredBy, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.FindByIDIncludingDeleted(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrItemNotDeleted
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+func (r *postgresItemRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM items WHERE deleted_at IS NOT NULL AND deleted_at < $1", deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+func (r *postgresItemRepository) StatsByStatus(ctx context.Context) ([]domain.ItemStatusStats, error) {
+	query := `
+		SELECT status, COUNT(*) AS items, COALESCE(SUM(stock), 0) AS stock
+		FROM items
+		WHERE deleted_at IS NULL
+		GROUP BY status
+		ORDER BY status`
+	var stats []domain.ItemStatusStats
+	if err := r.conn(ctx).SelectContext(ctx, &stats, query); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+func (r *postgresItemRepository) AdjustStock(ctx context.Context, id int64, stockDelta, reservedDelta int, updatedBy int) (*domain.Item, error) {
+	query := `
+		UPDATE items
+		SET status = CASE WHEN stock + $1 > 0 THEN 'ACTIVE' ELSE 'INACTIVE' END,
+			stock = stock + $1, reserved = reserved + $2, version = version + 1, updated_at = NOW(), updated_by = NULLIF($3, 0)
+		WHERE id = $4 AND deleted_at IS NULL AND reserved + $2 >= 0 AND stock + $1 >= reserved + $2
+		RETURNING ` + postgresItemColumns
+	var item domain.Item
+	err := r.conn(ctx).GetContext(ctx, &item, query, stockDelta, reservedDelta, updatedBy, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInsufficientStock
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+func (r *postgresItemRepository) missingOrConflict(ctx context.Context, id int64) error {
+	var exists bool
+	if err := r.conn(ctx).GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM items WHERE id = $1 AND deleted_at IS NULL)", id); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrVersionConflict
+	}
+	return domain.ErrItemNotFound
+}
+func (r *postgresItemRepository) ExistsByCode(ctx context.Context, code string, excludeID int64) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM items WHERE code = $1 AND id != $2)"
+	err := r.conn(ctx).GetContext(ctx, &exists, query, code, excludeID)
+	return exists, err
+}
@@ -0,0 +1,90 @@
+package repository
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+var _ UserRepositoryInterface = (*PostgresUserRepository)(nil)
+type PostgresUserRepository struct {
+	db *sqlx.DB
+}
+func NewPostgresUserRepository(db *sqlx.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
+}
+func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User) error {
+	query := `
+		INSERT INTO users (username, password, role)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+	err := database.Conn(ctx, r.db).QueryRowxContext(ctx, query, user.Username, user.Password, user.Role).
+		Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrDuplicateUsername
+		}
+		return err
+	}
+	return nil
+}
+func (r *PostgresUserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	query := `
+		SELECT id, username, password, role, created_at, updated_at
+		FROM users
+		WHERE username = $1
+	`
+	var user domain.User
+	err := database.Conn(ctx, r.db).GetContext(ctx, &user, query, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id int) (*domain.User, error) {
+	query := `
+		SELECT id, username, password, role, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+	var user domain.User
+	err := database.Conn(ctx, r.db).GetContext(ctx, &user, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+func (r *PostgresUserRepository) FindAll(ctx context.Context) ([]*domain.User, error) {
+	query := `
+		SELECT id, username, password, role, created_at, updated_at
+		FROM users
+		ORDER BY id
+	`
+	users := []*domain.User{}
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &users, query); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+func (r *PostgresUserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET role = $1 WHERE id = $2", role, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
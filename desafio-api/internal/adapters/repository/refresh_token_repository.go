@@ -0,0 +1,71 @@
+package repository
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.RefreshTokenRepository = (*refreshTokenRepository)(nil)
+type refreshTokenRepository struct {
+	db *sqlx.DB
+}
+func NewRefreshTokenRepository(db *sqlx.DB) *refreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+func (r *refreshTokenRepository) Save(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)`
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	id, err := insertReturningID(ctx, r.db, query, token.UserID, token.TokenHash, token.FamilyID, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+	token.ID = id
+	return nil
+}
+func (r *refreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := r.db.Rebind(`
+		SELECT id, user_id, token_hash, family_id, expires_at, created_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = ?`)
+	var token domain.RefreshToken
+	err := database.Conn(ctx, r.db).GetContext(ctx, &token, query, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+	query := r.db.Rebind("UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL")
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, usedAt, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	query := r.db.Rebind("UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL")
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, revokedAt, familyID)
+	return err
+}
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, r.db.Rebind("DELETE FROM refresh_tokens WHERE expires_at < ?"), before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,53 @@
+package repository
+import (
+	"context"
+	"errors"
+	"fmt"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+// insertReturningID runs an INSERT written with "?" placeholders and returns
+// the generated id on both MySQL (LastInsertId) and PostgreSQL (RETURNING).
+func insertReturningID(ctx context.Context, db database.Querier, query string, args ...interface{}) (int64, error) {
+	if db.DriverName() == database.DriverPostgres {
+		var id int64
+		err := db.QueryRowxContext(ctx, db.Rebind(query+" RETURNING id"), args...).Scan(&id)
+		return id, err
+	}
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+// iterateItems scans rows one at a time so callers never hold the whole
+// result set in memory.
+func iterateItems(ctx context.Context, db database.Querier, query string, args []interface{}, fn func(*domain.Item) error) error {
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch items: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item domain.Item
+		if err := rows.StructScan(&item); err != nil {
+			return fmt.Errorf("failed to scan item: %w", err)
+		}
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+// isUniqueViolation reports whether err is a unique constraint violation on
+// either PostgreSQL or MySQL.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
@@ -0,0 +1,57 @@
+package repository
+import (
+	"context"
+	"fmt"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.StockMovementRepository = (*stockMovementRepository)(nil)
+const stockMovementColumns = "id, item_id, type, stock_delta, reserved_delta, reason, reservation_id, COALESCE(created_by, 0) AS created_by, created_at"
+type stockMovementRepository struct {
+	db *sqlx.DB
+}
+func NewStockMovementRepository(db *sqlx.DB) *stockMovementRepository {
+	return &stockMovementRepository{db: db}
+}
+func (r *stockMovementRepository) Save(ctx context.Context, movement *domain.StockMovement) error {
+	query := `
+		INSERT INTO stock_movements (item_id, type, stock_delta, reserved_delta, reason, reservation_id, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, 0), ?)`
+	if movement.CreatedAt.IsZero() {
+		movement.CreatedAt = time.Now()
+	}
+	id, err := insertReturningID(ctx, database.Conn(ctx, r.db), query,
+		movement.ItemID,
+		movement.Type,
+		movement.StockDelta,
+		movement.ReservedDelta,
+		movement.Reason,
+		movement.ReservationID,
+		movement.CreatedBy,
+		movement.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	movement.ID = id
+	return nil
+}
+func (r *stockMovementRepository) FindByItem(ctx context.Context, itemID int64, limit, offset int) ([]*domain.StockMovement, int, error) {
+	conn := database.Conn(ctx, r.db)
+	var count int
+	if err := conn.GetContext(ctx, &count, r.db.Rebind("SELECT COUNT(*) FROM stock_movements WHERE item_id = ?"), itemID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock movements: %w", err)
+	}
+	movements := []*domain.StockMovement{}
+	if count == 0 {
+		return movements, 0, nil
+	}
+	query := r.db.Rebind("SELECT " + stockMovementColumns + " FROM stock_movements WHERE item_id = ? ORDER BY id DESC LIMIT ? OFFSET ?")
+	if err := conn.SelectContext(ctx, &movements, query, itemID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch stock movements: %w", err)
+	}
+	return movements, count, nil
+}
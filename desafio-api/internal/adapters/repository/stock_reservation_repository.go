@@ -0,0 +1,79 @@
+package repository
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.StockReservationRepository = (*stockReservationRepository)(nil)
+const stockReservationColumns = "id, item_id, quantity, status, reason, expires_at, COALESCE(created_by, 0) AS created_by, created_at, updated_at"
+type stockReservationRepository struct {
+	db *sqlx.DB
+}
+func NewStockReservationRepository(db *sqlx.DB) *stockReservationRepository {
+	return &stockReservationRepository{db: db}
+}
+func (r *stockReservationRepository) Save(ctx context.Context, reservation *domain.StockReservation) error {
+	query := `
+		INSERT INTO stock_reservations (item_id, quantity, status, reason, expires_at, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, 0), ?, ?)`
+	now := time.Now()
+	if reservation.CreatedAt.IsZero() {
+		reservation.CreatedAt = now
+	}
+	reservation.UpdatedAt = reservation.CreatedAt
+	id, err := insertReturningID(ctx, database.Conn(ctx, r.db), query,
+		reservation.ItemID,
+		reservation.Quantity,
+		reservation.Status,
+		reservation.Reason,
+		reservation.ExpiresAt,
+		reservation.CreatedBy,
+		reservation.CreatedAt,
+		reservation.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	reservation.ID = id
+	return nil
+}
+func (r *stockReservationRepository) FindByID(ctx context.Context, id int64) (*domain.StockReservation, error) {
+	var reservation domain.StockReservation
+	query := r.db.Rebind("SELECT " + stockReservationColumns + " FROM stock_reservations WHERE id = ?")
+	err := database.Conn(ctx, r.db).GetContext(ctx, &reservation, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+// UpdateStatus moves a reservation from one status to another and reports
+// whether it was still in the expected status, so concurrent commit/release
+// calls cannot both succeed.
+func (r *stockReservationRepository) UpdateStatus(ctx context.Context, id int64, from, to string) (bool, error) {
+	query := r.db.Rebind("UPDATE stock_reservations SET status = ?, updated_at = ? WHERE id = ? AND status = ?")
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, to, time.Now(), id, from)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+func (r *stockReservationRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*domain.StockReservation, error) {
+	reservations := []*domain.StockReservation{}
+	query := r.db.Rebind("SELECT " + stockReservationColumns + " FROM stock_reservations WHERE status = ? AND expires_at <= ? ORDER BY expires_at LIMIT ?")
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &reservations, query, domain.ReservationPending, now, limit); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
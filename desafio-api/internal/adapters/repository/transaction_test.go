@@ -0,0 +1,37 @@
+package repository
+import (
+	"context"
+	"testing"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+// TestTxManager_FailedNestedCallKeepsOuterTx covers the atomic import, which
+// reports a row whose write failed and goes on with the next rows.
+func TestTxManager_FailedNestedCallKeepsOuterTx(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, driver string, db *sqlx.DB) {
+		var repo repoPort.ItemRepository = NewItemRepository(db)
+		if driver == database.DriverPostgres {
+			repo = NewPostgresItemRepository(db)
+		}
+		ctx := context.Background()
+		userID := createTestUser(t, driver, db)
+		item := &domain.Item{Code: uniqueName("savepoint"), Title: "Item", Price: 100, Stock: 1, Status: "ACTIVE", CreatedBy: userID, UpdatedBy: userID}
+		require.NoError(t, repo.Save(ctx, item))
+		tx := database.NewTxManager(db)
+		err := tx.WithinTx(ctx, func(ctx context.Context) error {
+			duplicate := *item
+			assert.Error(t, tx.WithinTx(ctx, func(ctx context.Context) error {
+				return repo.Save(ctx, &duplicate)
+			}))
+			found, err := repo.FindByCode(ctx, item.Code)
+			require.NoError(t, err)
+			assert.Equal(t, item.ID, found.ID)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
@@ -3,8 +3,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"log"
 	"strings"
+	"desafio-api/internal/adapters/database"
 	"desafio-api/internal/domain"
 	"github.com/jmoiron/sqlx"
 )
@@ -12,6 +12,8 @@ type UserRepositoryInterface interface {
 	Create(ctx context.Context, user *domain.User) error
 	FindByUsername(ctx context.Context, username string) (*domain.User, error)
 	FindByID(ctx context.Context, id int) (*domain.User, error)
+	FindAll(ctx context.Context) ([]*domain.User, error)
+	UpdateRole(ctx context.Context, id int, role string) error
 }
 var _ UserRepositoryInterface = (*UserRepository)(nil)
 type UserRepository struct {
@@ -22,44 +24,37 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 }
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (username, password)
-		VALUES (?, ?)
+		INSERT INTO users (username, password, role)
+		VALUES (?, ?, ?)
 	`
 	existingUser, err := r.FindByUsername(ctx, user.Username)
 	if err == nil && existingUser != nil {
-		log.Printf("[DEBUG] UserRepository.Create: Username já existe: %s", user.Username)
 		return domain.ErrDuplicateUsername
 	} else if err != nil && err != domain.ErrUserNotFound {
-		log.Printf("[ERROR] UserRepository.Create: Erro ao verificar existência do usuário: %v", err)
 		return err
 	}
-	log.Printf("[DEBUG] UserRepository.Create: Inserindo novo usuário: %s", user.Username)
-	result, err := r.db.ExecContext(ctx, query, user.Username, user.Password)
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, user.Username, user.Password, user.Role)
 	if err != nil {
 		if isDuplicateKeyError(err) {
-			log.Printf("[ERROR] UserRepository.Create: Erro de chave duplicada: %v", err)
 			return domain.ErrDuplicateUsername
 		}
-		log.Printf("[ERROR] UserRepository.Create: Erro ao criar usuário: %v", err)
 		return err
 	}
 	id, err := result.LastInsertId()
 	if err != nil {
-		log.Printf("[ERROR] UserRepository.Create: Erro ao obter ID do usuário inserido: %v", err)
 		return err
 	}
 	user.ID = int(id)
-	log.Printf("[INFO] UserRepository.Create: Usuário criado com sucesso: ID=%d, Username=%s", user.ID, user.Username)
 	return nil
 }
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, created_at, updated_at
+		SELECT id, username, password, role, created_at, updated_at
 		FROM users
 		WHERE username = ?
 	`
 	var user domain.User
-	err := r.db.GetContext(ctx, &user, query, username)
+	err := database.Conn(ctx, r.db).GetContext(ctx, &user, query, username)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -70,12 +65,12 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 }
 func (r *UserRepository) FindByID(ctx context.Context, id int) (*domain.User, error) {
 	query := `
-		SELECT id, username, password, created_at, updated_at
+		SELECT id, username, password, role, created_at, updated_at
 		FROM users
 		WHERE id = ?
 	`
 	var user domain.User
-	err := r.db.GetContext(ctx, &user, query, id)
+	err := database.Conn(ctx, r.db).GetContext(ctx, &user, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -84,6 +79,34 @@ func (r *UserRepository) FindByID(ctx context.Context, id int) (*domain.User, er
 	}
 	return &user, nil
 }
+func (r *UserRepository) FindAll(ctx context.Context) ([]*domain.User, error) {
+	query := `
+		SELECT id, username, password, role, created_at, updated_at
+		FROM users
+		ORDER BY id
+	`
+	users := []*domain.User{}
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &users, query); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET role = ? WHERE id = ?", role, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 func isDuplicateKeyError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "Duplicate entry") && strings.Contains(err.Error(), "for key")
 }
@@ -0,0 +1,224 @@
+package repository
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/jmoiron/sqlx"
+)
+var _ repoPort.WebhookRepository = (*webhookRepository)(nil)
+const (
+	webhookSubscriptionColumns = "id, url, secret, event_types, active, COALESCE(created_by, 0) AS created_by, created_at, updated_at"
+	webhookDeliveryColumns     = "id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, COALESCE(last_status_code, 0) AS last_status_code, COALESCE(last_error, '') AS last_error, last_attempt_at, delivered_at, created_at, updated_at"
+)
+// webhookSubscriptionRow stores the event types as a comma separated list.
+type webhookSubscriptionRow struct {
+	domain.WebhookSubscription
+	EventTypeList string `db:"event_types"`
+}
+func (row *webhookSubscriptionRow) subscription() *domain.WebhookSubscription {
+	subscription := row.WebhookSubscription
+	subscription.EventTypes = strings.Split(row.EventTypeList, ",")
+	return &subscription
+}
+type webhookRepository struct {
+	db *sqlx.DB
+}
+func NewWebhookRepository(db *sqlx.DB) *webhookRepository {
+	return &webhookRepository{db: db}
+}
+func (r *webhookRepository) SaveSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, event_types, active, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NULLIF(?, 0), ?, ?)`
+	now := time.Now()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+	id, err := insertReturningID(ctx, database.Conn(ctx, r.db), query,
+		subscription.URL,
+		subscription.Secret,
+		strings.Join(subscription.EventTypes, ","),
+		subscription.Active,
+		subscription.CreatedBy,
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	subscription.ID = id
+	return nil
+}
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	subscription.UpdatedAt = time.Now()
+	query := r.db.Rebind("UPDATE webhook_subscriptions SET url = ?, secret = ?, event_types = ?, active = ?, updated_at = ? WHERE id = ?")
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		subscription.URL, subscription.Secret, strings.Join(subscription.EventTypes, ","), subscription.Active, subscription.UpdatedAt, subscription.ID)
+	if err != nil {
+		return err
+	}
+	return r.updatedOrMissing(ctx, result, "webhook_subscriptions", subscription.ID, domain.ErrWebhookNotFound)
+}
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, r.db.Rebind("DELETE FROM webhook_subscriptions WHERE id = ?"), id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+func (r *webhookRepository) FindSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	var row webhookSubscriptionRow
+	query := r.db.Rebind("SELECT " + webhookSubscriptionColumns + " FROM webhook_subscriptions WHERE id = ?")
+	err := database.Conn(ctx, r.db).GetContext(ctx, &row, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row.subscription(), nil
+}
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	var rows []webhookSubscriptionRow
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &rows, "SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions ORDER BY id"); err != nil {
+		return nil, err
+	}
+	subscriptions := make([]*domain.WebhookSubscription, 0, len(rows))
+	for i := range rows {
+		subscriptions = append(subscriptions, rows[i].subscription())
+	}
+	return subscriptions, nil
+}
+func (r *webhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	conn := database.Conn(ctx, r.db)
+	var existing int64
+	err := conn.GetContext(ctx, &existing, r.db.Rebind("SELECT id FROM webhook_deliveries WHERE subscription_id = ? AND event_id = ?"),
+		delivery.SubscriptionID, delivery.EventID)
+	if err == nil {
+		delivery.ID = existing
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	now := time.Now()
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = now
+	}
+	id, err := insertReturningID(ctx, conn, query,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		string(delivery.Payload),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	delivery.ID = id
+	return nil
+}
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now()
+	query := r.db.Rebind(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = NULLIF(?, 0), last_error = NULLIF(?, ''),
+			last_attempt_at = ?, delivered_at = ?, updated_at = ?
+		WHERE id = ?`)
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError,
+		delivery.LastAttemptAt, delivery.DeliveredAt, delivery.UpdatedAt, delivery.ID)
+	if err != nil {
+		return err
+	}
+	return r.updatedOrMissing(ctx, result, "webhook_deliveries", delivery.ID, domain.ErrDeliveryNotFound)
+}
+func (r *webhookRepository) FindDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	query := r.db.Rebind("SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE id = ?")
+	err := database.Conn(ctx, r.db).GetContext(ctx, &delivery, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, status string, limit, offset int) ([]*domain.WebhookDelivery, int, error) {
+	conn := database.Conn(ctx, r.db)
+	where := " WHERE subscription_id = ?"
+	args := []interface{}{subscriptionID}
+	if status != "" {
+		where += " AND status = ?"
+		args = append(args, status)
+	}
+	var count int
+	if err := conn.GetContext(ctx, &count, r.db.Rebind("SELECT COUNT(*) FROM webhook_deliveries"+where), args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+	deliveries := []*domain.WebhookDelivery{}
+	if count == 0 {
+		return deliveries, 0, nil
+	}
+	query := r.db.Rebind("SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries" + where + " ORDER BY id DESC LIMIT ? OFFSET ?")
+	if err := conn.SelectContext(ctx, &deliveries, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+	return deliveries, count, nil
+}
+func (r *webhookRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	var claimed []*domain.WebhookDelivery
+	err := database.NewTxManager(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+		query := r.db.Rebind("SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ? FOR UPDATE SKIP LOCKED")
+		if err := conn.SelectContext(ctx, &claimed, query, domain.DeliveryPending, now, limit); err != nil {
+			return err
+		}
+		for _, delivery := range claimed {
+			if _, err := conn.ExecContext(ctx, r.db.Rebind("UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ?"), leaseUntil, delivery.ID); err != nil {
+				return err
+			}
+			delivery.NextAttemptAt = leaseUntil
+		}
+		return nil
+	})
+	return claimed, err
+}
+// updatedOrMissing reports notFound when an UPDATE matched no row. MySQL
+// counts only changed rows, so zero affected rows still needs a lookup.
+func (r *webhookRepository) updatedOrMissing(ctx context.Context, result sql.Result, table string, id int64, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected > 0 {
+		return err
+	}
+	var exists bool
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &exists, r.db.Rebind("SELECT EXISTS(SELECT 1 FROM "+table+" WHERE id = ?)"), id); err != nil {
+		return err
+	}
+	if !exists {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package storage
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+// LocalDir keeps job files in a single directory. Every instance that runs
+// workers or serves downloads must see the same directory.
+type LocalDir struct {
+	dir string
+}
+func NewLocalDir(dir string) (*LocalDir, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalDir{dir: dir}, nil
+}
+// Create writes to a temporary file that only replaces name when the writer
+// is closed, so readers never see a partial file.
+func (d *LocalDir) Create(name string) (io.WriteCloser, error) {
+	path, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp(d.dir, "."+name+".*")
+	if err != nil {
+		return nil, err
+	}
+	return &atomicFile{File: file, target: path}, nil
+}
+func (d *LocalDir) Open(name string) (io.ReadCloser, error) {
+	path, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+func (d *LocalDir) Remove(name string) error {
+	path, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+// RemoveOlderThan deletes the files, including temporary files left by a
+// crashed writer, last modified before cutoff, and returns how many it
+// removed.
+func (d *LocalDir) RemoveOlderThan(cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, err
+		}
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(d.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+func (d *LocalDir) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return filepath.Join(d.dir, name), nil
+}
+type atomicFile struct {
+	*os.File
+	target string
+}
+func (f *atomicFile) Close() error {
+	if err := f.File.Close(); err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+	return os.Rename(f.File.Name(), f.target)
+}
@@ -0,0 +1,65 @@
+package storage
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func TestLocalDir_FileAppearsOnlyAfterClose(t *testing.T) {
+	dir, err := NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	w, err := dir.Create("export.csv")
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "code,title\n")
+	require.NoError(t, err)
+	_, err = dir.Open("export.csv")
+	assert.Error(t, err, "a file being written must not be visible")
+	require.NoError(t, w.Close())
+	r, err := dir.Open("export.csv")
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "code,title\n", string(content))
+	require.NoError(t, dir.Remove("export.csv"))
+	require.NoError(t, dir.Remove("export.csv"))
+	_, err = dir.Open("export.csv")
+	assert.Error(t, err)
+}
+func TestLocalDir_RejectsPathsOutsideTheDirectory(t *testing.T) {
+	dir, err := NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	for _, name := range []string{"", "../secret", "sub/file.csv", ".hidden"} {
+		_, err := dir.Create(name)
+		assert.Error(t, err, name)
+		_, err = dir.Open(name)
+		assert.Error(t, err, name)
+	}
+}
+func TestLocalDir_RemoveOlderThan(t *testing.T) {
+	root := t.TempDir()
+	dir, err := NewLocalDir(root)
+	require.NoError(t, err)
+	for _, name := range []string{"old.csv", "new.csv"} {
+		w, err := dir.Create(name)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+	abandoned, err := os.CreateTemp(root, ".crashed.csv.*")
+	require.NoError(t, err)
+	abandoned.Close()
+	past := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "old.csv"), past, past))
+	require.NoError(t, os.Chtimes(abandoned.Name(), past, past))
+	removed, err := dir.RemoveOlderThan(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	_, err = dir.Open("old.csv")
+	assert.Error(t, err)
+	r, err := dir.Open("new.csv")
+	require.NoError(t, err)
+	r.Close()
+}
@@ -0,0 +1,36 @@
+package service
+import (
+	"context"
+	"encoding/json"
+	"desafio-api/internal/domain"
+	"desafio-api/internal/ports/repository"
+)
+// recordItemEvent adds an item event to the outbox. It must run inside the
+// transaction that changes the item so the event exists exactly when the
+// change was committed.
+func recordItemEvent(ctx context.Context, outbox repository.OutboxRepository, eventType string, event domain.ItemEvent) error {
+	if event.ActorID == 0 {
+		event.ActorID, _ = domain.UserIDFromContext(ctx)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return outbox.Save(ctx, &domain.OutboxEvent{
+		AggregateType: domain.AggregateItem,
+		AggregateID:   event.ItemID,
+		Type:          eventType,
+		Payload:       payload,
+	})
+}
+// recordItemRevision stores the audit trail entry for a change, within the
+// same transaction as the change itself.
+func recordItemRevision(ctx context.Context, revisions repository.ItemRevisionRepository, action string, before, after *domain.Item) error {
+	revision, err := domain.NewItemRevision(action, before, after)
+	if err != nil {
+		return err
+	}
+	revision.ActorID, _ = domain.UserIDFromContext(ctx)
+	revision.RequestID = domain.RequestIDFromContext(ctx)
+	return revisions.Save(ctx, revision)
+}
@@ -0,0 +1,139 @@
+package service
+import (
+	"context"
+	"errors"
+	"desafio-api/internal/domain"
+)
+type ImportAction string
+const (
+	ImportActionCreate ImportAction = "create"
+	ImportActionUpdate ImportAction = "update"
+)
+type ImportOptions struct {
+	DryRun   bool
+	Upsert   bool
+	Atomic   bool
+	Progress JobProgress
+}
+// ImportRow is one parsed record; Err carries a parse failure so the row is
+// reported without being validated.
+type ImportRow struct {
+	Line int
+	Item *domain.Item
+	Err  error
+}
+type ImportRowResult struct {
+	Line   int
+	Code   string
+	Action ImportAction
+	ItemID int64
+	Err    error
+}
+// ImportReport counts the rows that were (or, when Applied is false, would
+// have been) created and updated.
+type ImportReport struct {
+	Total   int
+	Created int
+	Updated int
+	Failed  int
+	Applied bool
+	Rows    []ImportRowResult
+}
+var errImportRejected = errors.New("import rejected")
+// Import creates items from rows, or updates them by code when Upsert is
+// set. Without Atomic every valid row is written on its own; with Atomic the
+// batch runs in one transaction that is rolled back if any row fails.
+func (s *ItemService) Import(ctx context.Context, rows []ImportRow, opts ImportOptions) (_ *ImportReport, err error) {
+	ctx, span := startSpan(ctx, "ItemService.Import")
+	defer endSpan(span, &err)
+	report := &ImportReport{Total: len(rows), Rows: make([]ImportRowResult, 0, len(rows))}
+	run := func(ctx context.Context) error {
+		seen := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			result := ImportRowResult{Line: row.Line, Err: row.Err}
+			if result.Err == nil {
+				result.Code = row.Item.Code
+				write := !opts.DryRun && !(opts.Atomic && report.Failed > 0)
+				if row.Item.Code != "" && seen[row.Item.Code] {
+					result.Err = domain.ErrDuplicateImportCode
+				} else {
+					seen[row.Item.Code] = true
+					result.Action, result.ItemID, result.Err = s.importRow(ctx, row.Item, opts.Upsert, write)
+				}
+				if result.Err != nil && !isImportRowError(result.Err) {
+					return result.Err
+				}
+			}
+			switch {
+			case result.Err != nil:
+				result.Action = ""
+				report.Failed++
+			case result.Action == ImportActionCreate:
+				report.Created++
+			default:
+				report.Updated++
+			}
+			report.Rows = append(report.Rows, result)
+			if opts.Progress != nil {
+				opts.Progress(len(report.Rows), len(rows))
+			}
+		}
+		if opts.Atomic && report.Failed > 0 {
+			return errImportRejected
+		}
+		return nil
+	}
+	if opts.Atomic && !opts.DryRun {
+		err = s.tx.WithinTx(ctx, run)
+	} else {
+		err = run(ctx)
+	}
+	if err != nil && err != errImportRejected {
+		return nil, err
+	}
+	report.Applied = !opts.DryRun && err == nil
+	return report, nil
+}
+func (s *ItemService) importRow(ctx context.Context, item *domain.Item, upsert, write bool) (ImportAction, int64, error) {
+	if err := item.Validate(); err != nil {
+		return "", 0, err
+	}
+	existing, err := s.repo.FindByCode(ctx, item.Code)
+	if err != nil && err != domain.ErrItemNotFound {
+		return "", 0, err
+	}
+	// A deleted item keeps its code until purged, so the code can be
+	// neither reused nor upserted.
+	if existing != nil && existing.IsDeleted() {
+		return "", 0, domain.ErrDuplicateCode
+	}
+	if existing == nil {
+		if !write {
+			return ImportActionCreate, 0, nil
+		}
+		err := s.Create(ctx, item)
+		return ImportActionCreate, item.ID, err
+	}
+	if !upsert {
+		return "", 0, domain.ErrDuplicateCode
+	}
+	if item.Stock < existing.Reserved {
+		return "", 0, domain.ErrInsufficientStock
+	}
+	if !write {
+		return ImportActionUpdate, existing.ID, nil
+	}
+	err = s.Update(ctx, existing.ID, item)
+	return ImportActionUpdate, existing.ID, err
+}
+// isImportRowError reports whether err describes a bad row rather than a
+// failure of the import itself.
+func isImportRowError(err error) bool {
+	switch err {
+	case domain.ErrCodeRequired, domain.ErrTitleRequired, domain.ErrDescriptionRequired,
+		domain.ErrInvalidPrice, domain.ErrInvalidStock, domain.ErrDuplicateCode,
+		domain.ErrDuplicateImportCode, domain.ErrInsufficientStock, domain.ErrVersionConflict:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package service_test
+import (
+	"context"
+	"testing"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func importRows(items ...*domain.Item) []service.ImportRow {
+	rows := make([]service.ImportRow, 0, len(items))
+	for i, item := range items {
+		rows = append(rows, service.ImportRow{Line: i + 2, Item: item})
+	}
+	return rows
+}
+func countItems(t *testing.T, itemService *service.ItemService) int {
+	_, total, err := itemService.List(context.Background(), repoPort.ItemFilter{}, 1, 10)
+	require.NoError(t, err)
+	return total
+}
+func TestItemService_ImportCreatesAndReportsRowErrors(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	invalid := newTestItem("CAN-003")
+	invalid.Price = 0
+	rows := importRows(newTestItem("CAN-001"), newTestItem("CAN-002"), invalid, newTestItem("CAN-001"))
+	report, err := itemService.Import(ctx, rows, service.ImportOptions{})
+	require.NoError(t, err)
+	assert.True(t, report.Applied)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, domain.ErrInvalidPrice, report.Rows[2].Err)
+	assert.Equal(t, domain.ErrDuplicateImportCode, report.Rows[3].Err)
+	assert.NotZero(t, report.Rows[0].ItemID)
+	assert.Equal(t, 2, countItems(t, itemService))
+}
+func TestItemService_ImportDryRunWritesNothing(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	require.NoError(t, itemService.Create(ctx, newTestItem("CAN-001")))
+	report, err := itemService.Import(ctx, importRows(newTestItem("CAN-001"), newTestItem("CAN-002")), service.ImportOptions{DryRun: true, Upsert: true})
+	require.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, service.ImportActionUpdate, report.Rows[0].Action)
+	assert.Equal(t, 1, countItems(t, itemService))
+}
+func TestItemService_ImportUpsertByCode(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	existing := newTestItem("CAN-001")
+	require.NoError(t, itemService.Create(ctx, existing))
+	update := newTestItem("CAN-001")
+	update.Title = "Caneta preta"
+	report, err := itemService.Import(ctx, importRows(update), service.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ErrDuplicateCode, report.Rows[0].Err)
+	report, err = itemService.Import(ctx, importRows(update), service.ImportOptions{Upsert: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, existing.ID, report.Rows[0].ItemID)
+	stored, err := itemService.GetByID(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Caneta preta", stored.Title)
+	assert.Equal(t, int64(2), stored.Version)
+}
+func TestItemService_ImportAtomicStopsWritingOnFailure(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	invalid := newTestItem("")
+	report, err := itemService.Import(ctx, importRows(invalid, newTestItem("CAN-001")), service.ImportOptions{Atomic: true})
+	require.NoError(t, err)
+	assert.False(t, report.Applied)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, domain.ErrCodeRequired, report.Rows[0].Err)
+	assert.Equal(t, 0, countItems(t, itemService))
+}
+func TestItemService_ImportTreatsDeletedItemCodeAsTaken(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	deleted := newTestItem("CAN-001")
+	require.NoError(t, itemService.Create(ctx, deleted))
+	require.NoError(t, itemService.Delete(ctx, deleted.ID, 0))
+	for _, opts := range []service.ImportOptions{{DryRun: true}, {}, {DryRun: true, Upsert: true}, {Upsert: true}} {
+		report, err := itemService.Import(ctx, importRows(newTestItem("CAN-001")), opts)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ErrDuplicateCode, report.Rows[0].Err, "%+v", opts)
+	}
+	assert.Equal(t, 0, countItems(t, itemService))
+}
@@ -1,16 +1,23 @@
 package service
 import (
 	"context"
+	"time"
 	"desafio-api/internal/domain"
 	"desafio-api/internal/ports/repository"
 )
 type ItemService struct {
-	repo repository.ItemRepository
+	repo      repository.ItemRepository
+	movements repository.StockMovementRepository
+	outbox    repository.OutboxRepository
+	revisions repository.ItemRevisionRepository
+	tx        repository.TxManager
 }
-func NewItemService(repo repository.ItemRepository) *ItemService {
-	return &ItemService{repo: repo}
+func NewItemService(repo repository.ItemRepository, movements repository.StockMovementRepository, outbox repository.OutboxRepository, revisions repository.ItemRevisionRepository, tx repository.TxManager) *ItemService {
+	return &ItemService{repo: repo, movements: movements, outbox: outbox, revisions: revisions, tx: tx}
 }
-func (s *ItemService) Create(ctx context.Context, item *domain.Item) error {
+func (s *ItemService) Create(ctx context.Context, item *domain.Item) (err error) {
+	ctx, span := startSpan(ctx, "ItemService.Create")
+	defer endSpan(span, &err)
 	if err := item.Validate(); err != nil {
 		return err
 	}
@@ -26,17 +33,36 @@ func (s *ItemService) Create(ctx context.Context, item *domain.Item) error {
 	} else {
 		item.Status = "INACTIVE"
 	}
-	if userID, ok := ctx.Value("userID").(int); ok {
+	if userID, ok := domain.UserIDFromContext(ctx); ok {
 		item.CreatedBy = userID
 		item.UpdatedBy = userID
 	}
-	return s.repo.Save(ctx, item)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Save(ctx, item); err != nil {
+			return err
+		}
+		if err := s.recordAdjustment(ctx, item, item.Stock, "cadastro do item"); err != nil {
+			return err
+		}
+		if err := recordItemRevision(ctx, s.revisions, domain.RevisionCreate, nil, item); err != nil {
+			return err
+		}
+		return recordItemEvent(ctx, s.outbox, domain.EventItemCreated, domain.ItemEvent{ItemID: item.ID, Item: item})
+	})
 }
-func (s *ItemService) Update(ctx context.Context, id int64, item *domain.Item) error {
+func (s *ItemService) Update(ctx context.Context, id int64, item *domain.Item) (err error) {
+	ctx, span := startSpan(ctx, "ItemService.Update")
+	defer endSpan(span, &err)
 	existing, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if item.Version > 0 && item.Version != existing.Version {
+		return domain.ErrVersionConflict
+	}
+	before := *existing
+	previousStock := existing.Stock
+	previousStatus := existing.Status
 	existing.Code = item.Code
 	existing.Title = item.Title
 	existing.Description = item.Description
@@ -47,7 +73,7 @@ func (s *ItemService) Update(ctx context.Context, id int64, item *domain.Item) e
 	} else {
 		existing.Status = "INACTIVE"
 	}
-	if userID, ok := ctx.Value("userID").(int); ok {
+	if userID, ok := domain.UserIDFromContext(ctx); ok {
 		existing.UpdatedBy = userID
 	}
 	if err := existing.Validate(); err != nil {
@@ -60,26 +86,170 @@ func (s *ItemService) Update(ctx context.Context, id int64, item *domain.Item) e
 	if exists {
 		return domain.ErrDuplicateCode
 	}
-	err = s.repo.Update(ctx, existing)
+	if existing.Stock < existing.Reserved {
+		return domain.ErrInsufficientStock
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return err
+		}
+		delta := existing.Stock - previousStock
+		if err := s.recordAdjustment(ctx, existing, delta, "alteração do item"); err != nil {
+			return err
+		}
+		if err := recordItemRevision(ctx, s.revisions, domain.RevisionUpdate, &before, existing); err != nil {
+			return err
+		}
+		event := domain.ItemEvent{ItemID: existing.ID, Item: existing}
+		if existing.Status != previousStatus {
+			event.PreviousStatus = previousStatus
+		}
+		if err := recordItemEvent(ctx, s.outbox, domain.EventItemUpdated, event); err != nil {
+			return err
+		}
+		if delta == 0 {
+			return nil
+		}
+		return recordItemEvent(ctx, s.outbox, domain.EventItemStockChanged, domain.ItemEvent{ItemID: existing.ID, Item: existing, StockDelta: delta})
+	})
 	if err != nil {
 		return err
 	}
 	*item = *existing
 	return nil
 }
-func (s *ItemService) GetByID(ctx context.Context, id int64) (*domain.Item, error) {
+func (s *ItemService) recordAdjustment(ctx context.Context, item *domain.Item, delta int, reason string) error {
+	if delta == 0 {
+		return nil
+	}
+	return s.movements.Save(ctx, &domain.StockMovement{
+		ItemID:     item.ID,
+		Type:       domain.StockMovementAdjustment,
+		StockDelta: delta,
+		Reason:     reason,
+		CreatedBy:  item.UpdatedBy,
+	})
+}
+func (s *ItemService) GetByID(ctx context.Context, id int64) (_ *domain.Item, err error) {
+	ctx, span := startSpan(ctx, "ItemService.GetByID")
+	defer endSpan(span, &err)
 	return s.repo.FindByID(ctx, id)
 }
-func (s *ItemService) List(ctx context.Context, status string, page, limit int) ([]*domain.Item, int, error) {
+func (s *ItemService) GetByIDIncludingDeleted(ctx context.Context, id int64) (_ *domain.Item, err error) {
+	ctx, span := startSpan(ctx, "ItemService.GetByIDIncludingDeleted")
+	defer endSpan(span, &err)
+	return s.repo.FindByIDIncludingDeleted(ctx, id)
+}
+func (s *ItemService) List(ctx context.Context, filter repository.ItemFilter, page, limit int) (_ []*domain.Item, _ int, err error) {
+	ctx, span := startSpan(ctx, "ItemService.List")
+	defer endSpan(span, &err)
 	if page < 1 {
 		page = 1
 	}
-	if limit < 1 || limit > 20 {
+	if limit < 1 || limit > 100 {
 		limit = 10
 	}
 	offset := (page - 1) * limit
-	return s.repo.FindAll(ctx, status, limit, offset)
+	return s.repo.FindAll(ctx, filter, limit, offset)
+}
+// ListAfter returns the page following after in keyset order, plus the key to
+// resume from when more items remain.
+func (s *ItemService) ListAfter(ctx context.Context, filter repository.ItemFilter, after *repository.ItemKey, limit int) (_ []*domain.Item, _ *repository.ItemKey, err error) {
+	ctx, span := startSpan(ctx, "ItemService.ListAfter")
+	defer endSpan(span, &err)
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	items, err := s.repo.FindAfter(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+	items = items[:limit]
+	next := repository.KeyOf(items[limit-1])
+	return items, &next, nil
+}
+func (s *ItemService) Export(ctx context.Context, filter repository.ItemFilter, fn func(*domain.Item) error) (err error) {
+	ctx, span := startSpan(ctx, "ItemService.Export")
+	defer endSpan(span, &err)
+	return s.repo.Iterate(ctx, filter, fn)
+}
+func (s *ItemService) Delete(ctx context.Context, id int64, expectedVersion int64) (err error) {
+	ctx, span := startSpan(ctx, "ItemService.Delete")
+	defer endSpan(span, &err)
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Pending reservations must be committed or released first: once
+		// deleted the item can no longer settle them. Delete checks again in
+		// case one is made meanwhile.
+		if before.Reserved > 0 {
+			return domain.ErrItemHasReservations
+		}
+		actor, _ := domain.UserIDFromContext(ctx)
+		if err := s.repo.Delete(ctx, id, expectedVersion, actor); err != nil {
+			return err
+		}
+		if err := recordItemRevision(ctx, s.revisions, domain.RevisionDelete, before, nil); err != nil {
+			return err
+		}
+		return recordItemEvent(ctx, s.outbox, domain.EventItemDeleted, domain.ItemEvent{ItemID: id})
+	})
 }
-func (s *ItemService) Delete(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+func (s *ItemService) Restore(ctx context.Context, id int64) (_ *domain.Item, err error) {
+	ctx, span := startSpan(ctx, "ItemService.Restore")
+	defer endSpan(span, &err)
+	var restored *domain.Item
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.FindByIDIncludingDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+		actor, _ := domain.UserIDFromContext(ctx)
+		restored, err = s.repo.Restore(ctx, id, actor)
+		if err != nil {
+			return err
+		}
+		if err := recordItemRevision(ctx, s.revisions, domain.RevisionRestore, before, restored); err != nil {
+			return err
+		}
+		return recordItemEvent(ctx, s.outbox, domain.EventItemRestored, domain.ItemEvent{ItemID: id, Item: restored})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+// PurgeDeleted permanently removes the items deleted more than retention
+// ago. Their revisions and stock movements are kept.
+func (s *ItemService) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-retention))
+}
+// Stats counts the items that are not deleted and their stock per status.
+func (s *ItemService) Stats(ctx context.Context) ([]domain.ItemStatusStats, error) {
+	return s.repo.StatsByStatus(ctx)
+}
+// History returns the revisions of an item, newest first. Deleted items keep
+// their history.
+func (s *ItemService) History(ctx context.Context, itemID int64, page, limit int) (_ []*domain.ItemRevision, _ int, err error) {
+	ctx, span := startSpan(ctx, "ItemService.History")
+	defer endSpan(span, &err)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	revisions, total, err := s.revisions.FindByItem(ctx, itemID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, domain.ErrItemNotFound
+	}
+	return revisions, total, nil
 }
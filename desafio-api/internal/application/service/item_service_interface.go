@@ -2,12 +2,19 @@ package service
 import (
 	"context"
 	"desafio-api/internal/domain"
+	"desafio-api/internal/ports/repository"
 )
 type ItemServiceInterface interface {
 	Create(ctx context.Context, item *domain.Item) error
 	GetByID(ctx context.Context, id int64) (*domain.Item, error)
+	GetByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Item, error)
 	Update(ctx context.Context, id int64, item *domain.Item) error
-	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, status string, page, perPage int) ([]*domain.Item, int, error)
+	Delete(ctx context.Context, id int64, expectedVersion int64) error
+	Restore(ctx context.Context, id int64) (*domain.Item, error)
+	List(ctx context.Context, filter repository.ItemFilter, page, perPage int) ([]*domain.Item, int, error)
+	ListAfter(ctx context.Context, filter repository.ItemFilter, after *repository.ItemKey, limit int) ([]*domain.Item, *repository.ItemKey, error)
+	Export(ctx context.Context, filter repository.ItemFilter, fn func(*domain.Item) error) error
+	Import(ctx context.Context, rows []ImportRow, opts ImportOptions) (*ImportReport, error)
+	History(ctx context.Context, itemID int64, page, limit int) ([]*domain.ItemRevision, int, error)
 }
 var _ ItemServiceInterface = (*ItemService)(nil)
@@ -0,0 +1,225 @@
+package service_test
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func newItemService() *service.ItemService {
+	return service.NewItemService(repository.NewMockItemRepository(), repository.NewMockStockMovementRepository(), repository.NewMockOutboxRepository(), repository.NewMockItemRevisionRepository(), repository.NewMockTxManager())
+}
+func newTestItem(code string) *domain.Item {
+	return &domain.Item{Code: code, Title: "Caneta", Description: "Caneta azul", Price: 150, Stock: 10}
+}
+func TestItemService_UpdateIncrementsVersion(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	item := newTestItem("CAN-001")
+	require.NoError(t, itemService.Create(ctx, item))
+	assert.Equal(t, int64(1), item.Version)
+	update := newTestItem("CAN-001")
+	update.Title = "Caneta preta"
+	require.NoError(t, itemService.Update(ctx, item.ID, update))
+	assert.Equal(t, int64(2), update.Version)
+	stored, err := itemService.GetByID(ctx, item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Caneta preta", stored.Title)
+	assert.Equal(t, int64(2), stored.Version)
+}
+func TestItemService_StaleVersionIsRejected(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	item := newTestItem("CAN-001")
+	require.NoError(t, itemService.Create(ctx, item))
+	first, err := itemService.GetByID(ctx, item.ID)
+	require.NoError(t, err)
+	second, err := itemService.GetByID(ctx, item.ID)
+	require.NoError(t, err)
+	first.Title = "Primeira edição"
+	require.NoError(t, itemService.Update(ctx, item.ID, first))
+	second.Title = "Segunda edição"
+	assert.Equal(t, domain.ErrVersionConflict, itemService.Update(ctx, item.ID, second))
+	assert.Equal(t, domain.ErrVersionConflict, itemService.Delete(ctx, item.ID, 1))
+	require.NoError(t, itemService.Delete(ctx, item.ID, 2))
+}
+func TestItemService_UpdateRejectsDuplicateCode(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	require.NoError(t, itemService.Create(ctx, newTestItem("CAN-001")))
+	item := newTestItem("CAN-002")
+	require.NoError(t, itemService.Create(ctx, item))
+	update, err := itemService.GetByID(ctx, item.ID)
+	require.NoError(t, err)
+	update.Code = "CAN-001"
+	assert.Equal(t, domain.ErrDuplicateCode, itemService.Update(ctx, item.ID, update))
+}
+func TestItemService_ListAppliesFilterAndSort(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	for _, item := range []*domain.Item{
+		{Code: "CAN-001", Title: "Caneta azul", Description: "Caneta esferográfica", Price: 150, Stock: 10},
+		{Code: "CAN-002", Title: "Caneta vermelha", Description: "Caneta esferográfica", Price: 300, Stock: 0},
+		{Code: "LAP-001", Title: "Lápis", Description: "Lápis grafite", Price: 80, Stock: 5},
+		{Code: "CAD-001", Title: "Caderno", Description: "Caderno com capa azul", Price: 900, Stock: 3},
+	} {
+		require.NoError(t, itemService.Create(ctx, item))
+	}
+	maxPrice := int64(500)
+	items, total, err := itemService.List(ctx, repoPort.ItemFilter{
+		Query:    "canet esfero",
+		MaxPrice: &maxPrice,
+		Sort:     []repoPort.SortField{{Field: "price", Desc: true}},
+	}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	assert.Equal(t, "CAN-002", items[0].Code)
+	assert.Equal(t, "CAN-001", items[1].Code)
+	items, total, err = itemService.List(ctx, repoPort.ItemFilter{Query: "azul", Sort: []repoPort.SortField{{Field: "title"}}}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	assert.Equal(t, "CAD-001", items[0].Code)
+	items, total, err = itemService.List(ctx, repoPort.ItemFilter{CodePrefix: "CAN-", Status: "ACTIVE"}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, "CAN-001", items[0].Code)
+}
+func TestItemService_ListHonoursLimitUpToHundred(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	for i := 1; i <= 30; i++ {
+		require.NoError(t, itemService.Create(ctx, newTestItem(fmt.Sprintf("CAN-%03d", i))))
+	}
+	items, total, err := itemService.List(ctx, repoPort.ItemFilter{}, 1, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 30, total)
+	assert.Len(t, items, 30)
+	items, _, err = itemService.List(ctx, repoPort.ItemFilter{}, 2, 25)
+	require.NoError(t, err)
+	assert.Len(t, items, 5)
+}
+func TestItemService_ListAfterWalksEveryItemOnce(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	for _, code := range []string{"CAN-001", "CAN-002", "CAN-003", "CAN-004", "CAN-005"} {
+		require.NoError(t, itemService.Create(ctx, newTestItem(code)))
+	}
+	seen := make(map[int64]int)
+	var after *repoPort.ItemKey
+	for pages := 0; pages < 10; pages++ {
+		items, next, err := itemService.ListAfter(ctx, repoPort.ItemFilter{}, after, 2)
+		require.NoError(t, err)
+		for _, item := range items {
+			seen[item.ID]++
+		}
+		if pages == 0 {
+			update := newTestItem(items[0].Code)
+			update.Title = "Caneta preta"
+			require.NoError(t, itemService.Update(ctx, items[0].ID, update))
+		}
+		if next == nil {
+			break
+		}
+		after = next
+	}
+	assert.Len(t, seen, 5)
+	for id, count := range seen {
+		assert.Equal(t, 1, count, "item %d listed more than once", id)
+	}
+}
+func TestItemService_ExportHonoursFilter(t *testing.T) {
+	ctx := context.Background()
+	itemService := newItemService()
+	outOfStock := newTestItem("CAN-002")
+	outOfStock.Stock = 0
+	require.NoError(t, itemService.Create(ctx, newTestItem("CAN-001")))
+	require.NoError(t, itemService.Create(ctx, outOfStock))
+	var codes []string
+	err := itemService.Export(ctx, repoPort.ItemFilter{Status: "ACTIVE"}, func(item *domain.Item) error {
+		codes = append(codes, item.Code)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CAN-001"}, codes)
+}
+func TestItemService_RecordsRevisionHistory(t *testing.T) {
+	revisions := repository.NewMockItemRevisionRepository()
+	itemService := service.NewItemService(repository.NewMockItemRepository(), repository.NewMockStockMovementRepository(), repository.NewMockOutboxRepository(), revisions, repository.NewMockTxManager())
+	ctx := domain.ContextWithRequestID(domain.ContextWithUser(context.Background(), 5, domain.RoleEditor), "req-42")
+	item := newTestItem("CAN-001")
+	require.NoError(t, itemService.Create(ctx, item))
+	update := newTestItem("CAN-001")
+	update.Price = 200
+	update.Stock = 0
+	require.NoError(t, itemService.Update(ctx, item.ID, update))
+	require.NoError(t, itemService.Delete(ctx, item.ID, 0))
+	history, total, err := itemService.History(ctx, item.ID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	assert.Equal(t, domain.RevisionDelete, history[0].Action)
+	assert.Equal(t, domain.RevisionUpdate, history[1].Action)
+	assert.Equal(t, domain.RevisionCreate, history[2].Action)
+	for _, revision := range history {
+		assert.Equal(t, 5, revision.ActorID)
+		assert.Equal(t, "req-42", revision.RequestID)
+	}
+	var changes map[string]domain.FieldChange
+	require.NoError(t, json.Unmarshal(history[1].Changes, &changes))
+	assert.Equal(t, map[string]domain.FieldChange{
+		"price":  {From: float64(150), To: float64(200)},
+		"stock":  {From: float64(10), To: float64(0)},
+		"status": {From: "ACTIVE", To: "INACTIVE"},
+	}, changes)
+	var snapshot domain.Item
+	require.NoError(t, json.Unmarshal(history[0].Snapshot, &snapshot))
+	assert.Equal(t, "CAN-001", snapshot.Code)
+	_, _, err = itemService.History(ctx, 999, 1, 10)
+	assert.Equal(t, domain.ErrItemNotFound, err)
+}
+func TestItemService_SoftDeleteRestoreAndPurge(t *testing.T) {
+	repo := repository.NewMockItemRepository()
+	outbox := repository.NewMockOutboxRepository()
+	itemService := service.NewItemService(repo, repository.NewMockStockMovementRepository(), outbox, repository.NewMockItemRevisionRepository(), repository.NewMockTxManager())
+	ctx := domain.ContextWithUser(context.Background(), 3, domain.RoleAdmin)
+	item := newTestItem("CAN-001")
+	require.NoError(t, itemService.Create(ctx, item))
+	require.NoError(t, itemService.Delete(ctx, item.ID, 0))
+	_, err := itemService.GetByID(ctx, item.ID)
+	assert.Equal(t, domain.ErrItemNotFound, err)
+	items, total, err := itemService.List(ctx, repoPort.ItemFilter{}, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Equal(t, 0, total)
+	_, total, err = itemService.List(ctx, repoPort.ItemFilter{IncludeDeleted: true}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	deleted, err := itemService.GetByIDIncludingDeleted(ctx, item.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted.IsDeleted())
+	assert.Equal(t, 3, deleted.DeletedBy)
+	assert.Equal(t, domain.ErrDuplicateCode, itemService.Create(ctx, newTestItem("CAN-001")), "a deleted item keeps its code until purged")
+	assert.Equal(t, domain.ErrItemNotFound, itemService.Delete(ctx, item.ID, 0))
+	restored, err := itemService.Restore(ctx, item.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+	assert.Equal(t, deleted.Version+1, restored.Version)
+	_, err = itemService.Restore(ctx, item.ID)
+	assert.Equal(t, domain.ErrItemNotDeleted, err)
+	events := outbox.Events()
+	assert.Equal(t, domain.EventItemRestored, events[len(events)-1].Type)
+	require.NoError(t, itemService.Delete(ctx, item.ID, 0))
+	purged, err := itemService.PurgeDeleted(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged, "items within the retention period are kept")
+	purged, err = itemService.PurgeDeleted(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	_, err = itemService.GetByIDIncludingDeleted(ctx, item.ID)
+	assert.Equal(t, domain.ErrItemNotFound, err)
+}
@@ -0,0 +1,185 @@
+package service
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"desafio-api/internal/domain"
+	"desafio-api/internal/ports/repository"
+)
+const (
+	defaultJobMaxAttempts = 3
+	jobLease              = 30 * time.Second
+	jobHeartbeatInterval  = time.Second
+	jobRetryBaseDelay     = 10 * time.Second
+	jobRetryMaxDelay      = 10 * time.Minute
+)
+// JobProgress lets a runner report how far it got; it is cheap to call for
+// every processed record.
+type JobProgress func(processed, total int)
+// JobRunFunc executes a job and returns the JSON document stored as its
+// result. It must stop promptly when ctx is canceled.
+type JobRunFunc func(ctx context.Context, job *domain.Job, progress JobProgress) ([]byte, error)
+type JobService struct {
+	jobs    repository.JobRepository
+	runners map[string]JobRunFunc
+	mu      sync.RWMutex
+	logger  *slog.Logger
+}
+func NewJobService(jobs repository.JobRepository, logger *slog.Logger) *JobService {
+	return &JobService{jobs: jobs, runners: make(map[string]JobRunFunc), logger: logger}
+}
+func (s *JobService) Register(jobType string, run JobRunFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runners[jobType] = run
+}
+func (s *JobService) runner(jobType string) (JobRunFunc, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	run, ok := s.runners[jobType]
+	return run, ok
+}
+func (s *JobService) Enqueue(ctx context.Context, jobType string, payload []byte) (*domain.Job, error) {
+	if _, ok := s.runner(jobType); !ok {
+		return nil, domain.ErrUnknownJobType
+	}
+	job := &domain.Job{
+		Type:        jobType,
+		Status:      domain.JobQueued,
+		Payload:     payload,
+		MaxAttempts: defaultJobMaxAttempts,
+	}
+	if userID, ok := domain.UserIDFromContext(ctx); ok {
+		job.CreatedBy = userID
+	}
+	if err := s.jobs.Save(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+func (s *JobService) Get(ctx context.Context, id int64) (*domain.Job, error) {
+	return s.jobs.FindByID(ctx, id)
+}
+func (s *JobService) Cancel(ctx context.Context, id int64) (*domain.Job, error) {
+	if err := s.jobs.RequestCancel(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.jobs.FindByID(ctx, id)
+}
+func (s *JobService) Retry(ctx context.Context, id int64) (*domain.Job, error) {
+	if err := s.jobs.Requeue(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.jobs.FindByID(ctx, id)
+}
+// RequeueStale recovers the jobs of dead workers. A job that was on its last
+// attempt fails instead, so one that crashes its worker is not run forever.
+func (s *JobService) RequeueStale(ctx context.Context) (requeued, failed int, err error) {
+	return s.jobs.RequeueStale(ctx, time.Now(), "o processamento foi interrompido na última tentativa")
+}
+// RunNext claims and runs one due job, reporting whether there was one.
+// Canceling ctx interrupts the running job, which goes back to the queue
+// without spending an attempt.
+func (s *JobService) RunNext(ctx context.Context, workerID string) (bool, error) {
+	now := time.Now()
+	job, err := s.jobs.Claim(ctx, workerID, now, now.Add(jobLease))
+	if err != nil || job == nil {
+		return false, err
+	}
+	return true, s.run(ctx, job, workerID)
+}
+func (s *JobService) run(ctx context.Context, job *domain.Job, workerID string) error {
+	runCtx, cancel := context.WithCancel(domain.ContextWithUser(ctx, job.CreatedBy, ""))
+	defer cancel()
+	var processed, total atomic.Int64
+	processed.Store(int64(job.Processed))
+	total.Store(int64(job.Total))
+	var canceledByUser atomic.Bool
+	stopHeartbeat := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(jobHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-ticker.C:
+				requested, err := s.jobs.Heartbeat(context.WithoutCancel(ctx), job.ID, workerID, int(processed.Load()), int(total.Load()), time.Now().Add(jobLease))
+				if err != nil {
+					s.logger.WarnContext(ctx, "job heartbeat failed", slog.Int64("job_id", job.ID), slog.Any("error", err))
+				}
+				if requested || errors.Is(err, domain.ErrJobLockLost) {
+					canceledByUser.Store(requested)
+					cancel()
+				}
+			}
+		}
+	}()
+	result, runErr := s.execute(runCtx, job, func(done, of int) {
+		processed.Store(int64(done))
+		total.Store(int64(of))
+	})
+	close(stopHeartbeat)
+	<-heartbeatDone
+	now := time.Now()
+	job.Processed = int(processed.Load())
+	job.Total = int(total.Load())
+	job.LastError = ""
+	switch {
+	case runErr == nil:
+		job.Status = domain.JobSucceeded
+		job.Result = result
+		job.FinishedAt = &now
+	case canceledByUser.Load() || errors.Is(runErr, domain.ErrJobCanceled):
+		job.Status = domain.JobCanceled
+		job.FinishedAt = &now
+	case ctx.Err() != nil:
+		job.Status = domain.JobQueued
+		job.Attempts--
+		job.RunAt = now
+		job.LastError = "interrompido pelo desligamento do servidor"
+	case job.Attempts < job.MaxAttempts:
+		job.Status = domain.JobQueued
+		job.RunAt = now.Add(jobRetryDelay(job.Attempts))
+		job.LastError = runErr.Error()
+	default:
+		job.Status = domain.JobFailed
+		job.LastError = runErr.Error()
+		job.FinishedAt = &now
+	}
+	finishCtx, finishCancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer finishCancel()
+	if err := s.jobs.Finish(finishCtx, job, workerID); err != nil {
+		return fmt.Errorf("failed to finish job %d: %w", job.ID, err)
+	}
+	return nil
+}
+func (s *JobService) execute(ctx context.Context, job *domain.Job, progress JobProgress) (result []byte, err error) {
+	run, ok := s.runner(job.Type)
+	if !ok {
+		return nil, domain.ErrUnknownJobType
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx, job, progress)
+}
+// jobRetryDelay doubles the wait after every failed attempt.
+func jobRetryDelay(attempts int) time.Duration {
+	delay := jobRetryBaseDelay
+	for i := 1; i < attempts && delay < jobRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > jobRetryMaxDelay {
+		delay = jobRetryMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,12 @@
+package service
+import (
+	"context"
+	"desafio-api/internal/domain"
+)
+type JobServiceInterface interface {
+	Enqueue(ctx context.Context, jobType string, payload []byte) (*domain.Job, error)
+	Get(ctx context.Context, id int64) (*domain.Job, error)
+	Cancel(ctx context.Context, id int64) (*domain.Job, error)
+	Retry(ctx context.Context, id int64) (*domain.Job, error)
+}
+var _ JobServiceInterface = (*JobService)(nil)
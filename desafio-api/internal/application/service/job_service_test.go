@@ -0,0 +1,154 @@
+package service_test
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func setupJobs(t *testing.T, run service.JobRunFunc) (*service.JobService, context.Context) {
+	t.Helper()
+	jobs := service.NewJobService(repository.NewMockJobRepository(), logging.Nop())
+	jobs.Register("test", run)
+	return jobs, domain.ContextWithUser(context.Background(), 7, domain.RoleEditor)
+}
+func TestJobService_EnqueueRejectsUnknownType(t *testing.T) {
+	jobs, ctx := setupJobs(t, nil)
+	_, err := jobs.Enqueue(ctx, "unknown", nil)
+	assert.Equal(t, domain.ErrUnknownJobType, err)
+}
+func TestJobService_RunNextStoresResult(t *testing.T) {
+	jobs, ctx := setupJobs(t, func(ctx context.Context, job *domain.Job, progress service.JobProgress) ([]byte, error) {
+		userID, _ := domain.UserIDFromContext(ctx)
+		assert.Equal(t, 7, userID)
+		assert.Equal(t, `{"n":2}`, string(job.Payload))
+		progress(1, 2)
+		progress(2, 2)
+		return []byte(`{"ok":true}`), nil
+	})
+	job, err := jobs.Enqueue(ctx, "test", []byte(`{"n":2}`))
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobQueued, job.Status)
+	assert.Equal(t, 7, job.CreatedBy)
+	ran, err := jobs.RunNext(context.Background(), "worker-1")
+	require.NoError(t, err)
+	assert.True(t, ran)
+	job, err = jobs.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobSucceeded, job.Status)
+	assert.Equal(t, `{"ok":true}`, string(job.Result))
+	assert.Equal(t, 100, job.Percent())
+	assert.Equal(t, 1, job.Attempts)
+	assert.NotNil(t, job.FinishedAt)
+	ran, err = jobs.RunNext(context.Background(), "worker-1")
+	require.NoError(t, err)
+	assert.False(t, ran)
+}
+func TestJobService_FailedRunIsRetriedWithBackoff(t *testing.T) {
+	jobs, ctx := setupJobs(t, func(ctx context.Context, job *domain.Job, progress service.JobProgress) ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+	job, err := jobs.Enqueue(ctx, "test", nil)
+	require.NoError(t, err)
+	_, err = jobs.RunNext(context.Background(), "worker-1")
+	require.NoError(t, err)
+	job, err = jobs.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobQueued, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, "boom", job.LastError)
+	assert.True(t, job.RunAt.After(time.Now().Add(5*time.Second)))
+	ran, err := jobs.RunNext(context.Background(), "worker-1")
+	require.NoError(t, err)
+	assert.False(t, ran, "the retry must wait for its backoff")
+	_, err = jobs.Retry(ctx, job.ID)
+	assert.Equal(t, domain.ErrJobNotRetryable, err)
+}
+func TestJobService_PanicFailsTheAttempt(t *testing.T) {
+	jobs, ctx := setupJobs(t, func(ctx context.Context, job *domain.Job, progress service.JobProgress) ([]byte, error) {
+		panic("unexpected")
+	})
+	job, err := jobs.Enqueue(ctx, "test", nil)
+	require.NoError(t, err)
+	_, err = jobs.RunNext(context.Background(), "worker-1")
+	require.NoError(t, err)
+	job, err = jobs.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobQueued, job.Status)
+	assert.Contains(t, job.LastError, "unexpected")
+}
+func TestJobService_CancelQueuedJobAndRetry(t *testing.T) {
+	jobs, ctx := setupJobs(t, func(ctx context.Context, job *domain.Job, progress service.JobProgress) ([]byte, error) {
+		return []byte(`{}`), nil
+	})
+	job, err := jobs.Enqueue(ctx, "test", nil)
+	require.NoError(t, err)
+	job, err = jobs.Cancel(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobCanceled, job.Status)
+	ran, err := jobs.RunNext(context.Background(), "worker-1")
+	require.NoError(t, err)
+	assert.False(t, ran)
+	_, err = jobs.Cancel(ctx, job.ID)
+	assert.Equal(t, domain.ErrJobNotCancelable, err)
+	job, err = jobs.Retry(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobQueued, job.Status)
+	ran, err = jobs.RunNext(context.Background(), "worker-1")
+	require.NoError(t, err)
+	assert.True(t, ran)
+}
+func TestJobService_CancelRunningJob(t *testing.T) {
+	started := make(chan int64)
+	jobs, ctx := setupJobs(t, func(ctx context.Context, job *domain.Job, progress service.JobProgress) ([]byte, error) {
+		started <- job.ID
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_, err := jobs.Enqueue(ctx, "test", nil)
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		_, err := jobs.RunNext(context.Background(), "worker-1")
+		done <- err
+	}()
+	id := <-started
+	job, err := jobs.Cancel(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobRunning, job.Status)
+	assert.True(t, job.CancelRequested)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("running job was not canceled")
+	}
+	job, err = jobs.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobCanceled, job.Status)
+}
+func TestJobWorkerPool_ShutdownRequeuesInterruptedJobs(t *testing.T) {
+	started := make(chan int64, 1)
+	jobs, ctx := setupJobs(t, func(ctx context.Context, job *domain.Job, progress service.JobProgress) ([]byte, error) {
+		started <- job.ID
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_, err := jobs.Enqueue(ctx, "test", nil)
+	require.NoError(t, err)
+	pool := service.NewJobWorkerPool(jobs, 2, 10*time.Millisecond)
+	pool.Start()
+	id := <-started
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, pool.Shutdown(shutdownCtx))
+	job, err := jobs.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobQueued, job.Status)
+	assert.Equal(t, 0, job.Attempts, "an interrupted run must not spend an attempt")
+}
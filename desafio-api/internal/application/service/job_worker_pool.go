@@ -0,0 +1,73 @@
+package service
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+// JobWorkerPool polls the job queue with a fixed number of workers.
+type JobWorkerPool struct {
+	jobs         *JobService
+	workers      int
+	pollInterval time.Duration
+	stopClaiming context.CancelFunc
+	abort        context.CancelFunc
+	wg           sync.WaitGroup
+}
+func NewJobWorkerPool(jobs *JobService, workers int, pollInterval time.Duration) *JobWorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &JobWorkerPool{jobs: jobs, workers: workers, pollInterval: pollInterval}
+}
+func (p *JobWorkerPool) Start() {
+	claimCtx, stopClaiming := context.WithCancel(context.Background())
+	runCtx, abort := context.WithCancel(context.Background())
+	p.stopClaiming, p.abort = stopClaiming, abort
+	hostname, _ := os.Hostname()
+	for i := 1; i <= p.workers; i++ {
+		workerID := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), i)
+		p.wg.Add(1)
+		go p.work(claimCtx, runCtx, workerID)
+	}
+}
+func (p *JobWorkerPool) work(claimCtx, runCtx context.Context, workerID string) {
+	defer p.wg.Done()
+	for claimCtx.Err() == nil {
+		ran, err := p.jobs.RunNext(runCtx, workerID)
+		if err != nil {
+			p.jobs.logger.ErrorContext(runCtx, "job worker failed", slog.String("worker_id", workerID), slog.Any("error", err))
+		}
+		if ran && err == nil {
+			continue
+		}
+		select {
+		case <-claimCtx.Done():
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
+// Shutdown stops claiming new jobs and waits for the running ones. If ctx
+// expires first they are interrupted and returned to the queue.
+func (p *JobWorkerPool) Shutdown(ctx context.Context) error {
+	if p.stopClaiming == nil {
+		return nil
+	}
+	p.stopClaiming()
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.abort()
+		return nil
+	case <-ctx.Done():
+		p.abort()
+		<-done
+		return ctx.Err()
+	}
+}
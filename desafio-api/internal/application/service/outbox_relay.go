@@ -0,0 +1,85 @@
+package service
+import (
+	"context"
+	"log/slog"
+	"time"
+	"desafio-api/internal/domain"
+	"desafio-api/internal/ports/repository"
+)
+const (
+	outboxBatchSize      = 100
+	outboxLease          = time.Minute
+	outboxRetryBaseDelay = time.Second
+	outboxRetryMaxDelay  = 5 * time.Minute
+)
+// EventPublisher delivers an outbox event to the outside world. Delivery is
+// at least once: an event is published again if marking it fails.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *domain.OutboxEvent) error
+}
+// OutboxRelay moves events from the outbox to a publisher, keeping the order
+// of the events of each aggregate.
+type OutboxRelay struct {
+	outbox    repository.OutboxRepository
+	publisher EventPublisher
+	logger    *slog.Logger
+}
+func NewOutboxRelay(outbox repository.OutboxRepository, publisher EventPublisher, logger *slog.Logger) *OutboxRelay {
+	return &OutboxRelay{outbox: outbox, publisher: publisher, logger: logger}
+}
+// RelayPending publishes every due event and returns how many were
+// published. A failed event is retried later with backoff and holds back the
+// events that follow it for the same aggregate.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		published, err := r.relayBatch(ctx)
+		total += published
+		if err != nil || published == 0 {
+			return total, err
+		}
+	}
+}
+// relayBatch claims a batch of events and publishes them outside any
+// transaction, so a slow publisher holds no locks. Events left unpublished
+// when the lease runs short are claimed again once it expires.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	claimedAt := time.Now()
+	events, err := r.outbox.Claim(ctx, claimedAt, claimedAt.Add(outboxLease), outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return published, err
+		}
+		if time.Since(claimedAt) > outboxLease/2 {
+			break
+		}
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			r.logger.WarnContext(ctx, "publishing outbox event failed", slog.Int64("event_id", event.ID), slog.String("event_type", event.Type), slog.Int("attempts", event.Attempts+1), slog.Any("error", err))
+			next := time.Now().Add(outboxRetryDelay(event.Attempts + 1))
+			if err := r.outbox.MarkFailed(ctx, event.ID, err.Error(), next); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := r.outbox.MarkPublished(ctx, event.ID, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+// outboxRetryDelay doubles the wait after every failed attempt.
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBaseDelay
+	for i := 1; i < attempts && delay < outboxRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > outboxRetryMaxDelay {
+		delay = outboxRetryMaxDelay
+	}
+	return delay
+}
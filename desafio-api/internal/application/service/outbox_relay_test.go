@@ -0,0 +1,141 @@
+package service_test
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/events"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+type outboxFixture struct {
+	ctx       context.Context
+	outbox    *repository.MockOutboxRepository
+	publisher *events.MemoryPublisher
+	items     *service.ItemService
+	stock     *service.StockService
+	relay     *service.OutboxRelay
+}
+func setupOutbox() *outboxFixture {
+	itemRepo := repository.NewMockItemRepository()
+	movements := repository.NewMockStockMovementRepository()
+	tx := repository.NewMockTxManager()
+	f := &outboxFixture{
+		ctx:       domain.ContextWithUser(context.Background(), 7, domain.RoleEditor),
+		outbox:    repository.NewMockOutboxRepository(),
+		publisher: events.NewMemoryPublisher(),
+	}
+	f.items = service.NewItemService(itemRepo, movements, f.outbox, repository.NewMockItemRevisionRepository(), tx)
+	f.stock = service.NewStockService(itemRepo, movements, repository.NewMockStockReservationRepository(), f.outbox, repository.NewMockItemRevisionRepository(), tx)
+	f.relay = service.NewOutboxRelay(f.outbox, f.publisher, logging.Nop())
+	return f
+}
+func eventTypes(list []*domain.OutboxEvent) []string {
+	types := make([]string, len(list))
+	for i, event := range list {
+		types[i] = event.Type
+	}
+	return types
+}
+func TestItemService_WritesEventsToOutbox(t *testing.T) {
+	f := setupOutbox()
+	item := newTestItem("CAN-001")
+	require.NoError(t, f.items.Create(f.ctx, item))
+	update := newTestItem("CAN-001")
+	update.Stock = 0
+	require.NoError(t, f.items.Update(f.ctx, item.ID, update))
+	_, err := f.stock.Increment(f.ctx, item.ID, 4, "recebimento")
+	require.NoError(t, err)
+	require.NoError(t, f.items.Delete(f.ctx, item.ID, 0))
+	stored := f.outbox.Events()
+	assert.Equal(t, []string{
+		domain.EventItemCreated,
+		domain.EventItemUpdated,
+		domain.EventItemStockChanged,
+		domain.EventItemStockChanged,
+		domain.EventItemDeleted,
+	}, eventTypes(stored))
+	var updated domain.ItemEvent
+	require.NoError(t, json.Unmarshal(stored[1].Payload, &updated))
+	assert.Equal(t, "ACTIVE", updated.PreviousStatus)
+	assert.Equal(t, "INACTIVE", updated.Item.Status)
+	assert.Equal(t, 7, updated.ActorID)
+	var changed domain.ItemEvent
+	require.NoError(t, json.Unmarshal(stored[2].Payload, &changed))
+	assert.Equal(t, -10, changed.StockDelta)
+	for _, event := range stored {
+		assert.Equal(t, domain.AggregateItem, event.AggregateType)
+		assert.Equal(t, item.ID, event.AggregateID)
+	}
+}
+func TestItemService_FailedWriteLeavesNoEvent(t *testing.T) {
+	f := setupOutbox()
+	item := newTestItem("CAN-001")
+	require.NoError(t, f.items.Create(f.ctx, item))
+	assert.Equal(t, domain.ErrDuplicateCode, f.items.Create(f.ctx, newTestItem("CAN-001")))
+	assert.Equal(t, domain.ErrItemNotFound, f.items.Delete(f.ctx, 999, 0))
+	assert.Len(t, f.outbox.Events(), 1)
+}
+func TestOutboxRelay_PublishesInOrder(t *testing.T) {
+	f := setupOutbox()
+	first := newTestItem("CAN-001")
+	require.NoError(t, f.items.Create(f.ctx, first))
+	second := newTestItem("CAN-002")
+	require.NoError(t, f.items.Create(f.ctx, second))
+	require.NoError(t, f.items.Delete(f.ctx, first.ID, 0))
+	published, err := f.relay.RelayPending(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, published)
+	assert.Equal(t, []string{domain.EventItemCreated, domain.EventItemCreated, domain.EventItemDeleted}, eventTypes(f.publisher.Events()))
+	pending, err := f.outbox.CountPending(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pending)
+	published, err = f.relay.RelayPending(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, published)
+}
+func TestOutboxRelay_FailureIsRetriedLater(t *testing.T) {
+	f := setupOutbox()
+	item := newTestItem("CAN-001")
+	require.NoError(t, f.items.Create(f.ctx, item))
+	require.NoError(t, f.items.Delete(f.ctx, item.ID, 0))
+	f.publisher.FailWith(errors.New("broker unavailable"))
+	published, err := f.relay.RelayPending(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, published)
+	stored := f.outbox.Events()
+	assert.Equal(t, 1, stored[0].Attempts)
+	assert.Equal(t, "broker unavailable", stored[0].LastError)
+	assert.True(t, stored[0].NextAttemptAt.After(stored[0].OccurredAt))
+	assert.Equal(t, 0, stored[1].Attempts, "later events of the item wait for the failed one")
+	f.publisher.FailWith(nil)
+	published, err = f.relay.RelayPending(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, published, "the failed event is not due yet")
+}
+// relayingPublisher runs another relay while each event is being published.
+type relayingPublisher struct {
+	other  *service.OutboxRelay
+	counts []int
+}
+func (p *relayingPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	published, err := p.other.RelayPending(ctx)
+	p.counts = append(p.counts, published)
+	return err
+}
+func TestOutboxRelay_ClaimedEventsAreSkippedByOtherRelays(t *testing.T) {
+	f := setupOutbox()
+	require.NoError(t, f.items.Create(f.ctx, newTestItem("CAN-001")))
+	require.NoError(t, f.items.Create(f.ctx, newTestItem("CAN-002")))
+	publisher := &relayingPublisher{other: f.relay}
+	relay := service.NewOutboxRelay(f.outbox, publisher, logging.Nop())
+	published, err := relay.RelayPending(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []int{0, 0}, publisher.counts)
+	assert.Empty(t, f.publisher.Events())
+}
@@ -0,0 +1,255 @@
+package service
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"desafio-api/internal/domain"
+	"desafio-api/internal/ports/repository"
+)
+const (
+	DefaultReservationTTL    = 15 * time.Minute
+	MaxReservationTTL        = 24 * time.Hour
+	expiredReservationBatch  = 100
+	expiredReservationReason = "reserva expirada"
+)
+type StockService struct {
+	items        repository.ItemRepository
+	movements    repository.StockMovementRepository
+	reservations repository.StockReservationRepository
+	outbox       repository.OutboxRepository
+	revisions    repository.ItemRevisionRepository
+	tx           repository.TxManager
+}
+func NewStockService(items repository.ItemRepository, movements repository.StockMovementRepository, reservations repository.StockReservationRepository, outbox repository.OutboxRepository, revisions repository.ItemRevisionRepository, tx repository.TxManager) *StockService {
+	return &StockService{
+		items:        items,
+		movements:    movements,
+		reservations: reservations,
+		outbox:       outbox,
+		revisions:    revisions,
+		tx:           tx,
+	}
+}
+func (s *StockService) Increment(ctx context.Context, itemID int64, quantity int, reason string) (*domain.Item, error) {
+	return s.adjust(ctx, itemID, domain.StockMovementIncrement, quantity, reason)
+}
+func (s *StockService) Decrement(ctx context.Context, itemID int64, quantity int, reason string) (*domain.Item, error) {
+	return s.adjust(ctx, itemID, domain.StockMovementDecrement, quantity, reason)
+}
+func (s *StockService) adjust(ctx context.Context, itemID int64, movementType string, quantity int, reason string) (*domain.Item, error) {
+	if quantity <= 0 {
+		return nil, domain.ErrInvalidQuantity
+	}
+	delta := quantity
+	if movementType == domain.StockMovementDecrement {
+		delta = -quantity
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, domain.ErrReasonRequired
+	}
+	actor, _ := domain.UserIDFromContext(ctx)
+	var item *domain.Item
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = s.items.AdjustStock(ctx, itemID, delta, 0, actor)
+		if err != nil {
+			return err
+		}
+		err = s.movements.Save(ctx, &domain.StockMovement{
+			ItemID:     itemID,
+			Type:       movementType,
+			StockDelta: delta,
+			Reason:     reason,
+			CreatedBy:  actor,
+		})
+		if err != nil {
+			return err
+		}
+		return s.recordStockChanged(ctx, item, delta, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+func (s *StockService) Reserve(ctx context.Context, itemID int64, quantity int, ttl time.Duration, reason string) (*domain.StockReservation, error) {
+	if quantity <= 0 {
+		return nil, domain.ErrInvalidQuantity
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, domain.ErrReasonRequired
+	}
+	if ttl == 0 {
+		ttl = DefaultReservationTTL
+	}
+	if ttl < 0 || ttl > MaxReservationTTL {
+		return nil, domain.ErrInvalidReservationTTL
+	}
+	actor, _ := domain.UserIDFromContext(ctx)
+	now := time.Now()
+	reservation := &domain.StockReservation{
+		ItemID:    itemID,
+		Quantity:  quantity,
+		Status:    domain.ReservationPending,
+		Reason:    reason,
+		ExpiresAt: now.Add(ttl),
+		CreatedBy: actor,
+		CreatedAt: now,
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := s.items.AdjustStock(ctx, itemID, 0, quantity, actor)
+		if err != nil {
+			return err
+		}
+		if err := s.reservations.Save(ctx, reservation); err != nil {
+			return err
+		}
+		err = s.movements.Save(ctx, &domain.StockMovement{
+			ItemID:        itemID,
+			Type:          domain.StockMovementReserve,
+			ReservedDelta: quantity,
+			Reason:        reason,
+			ReservationID: &reservation.ID,
+			CreatedBy:     actor,
+		})
+		if err != nil {
+			return err
+		}
+		return s.recordStockChanged(ctx, item, 0, quantity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+func (s *StockService) GetReservation(ctx context.Context, id int64) (*domain.StockReservation, error) {
+	return s.reservations.FindByID(ctx, id)
+}
+func (s *StockService) CommitReservation(ctx context.Context, id int64, reason string) (*domain.StockReservation, error) {
+	return s.settle(ctx, id, domain.ReservationCommitted, domain.StockMovementCommit, reason)
+}
+func (s *StockService) ReleaseReservation(ctx context.Context, id int64, reason string) (*domain.StockReservation, error) {
+	return s.settle(ctx, id, domain.ReservationReleased, domain.StockMovementRelease, reason)
+}
+func (s *StockService) settle(ctx context.Context, id int64, status, movementType, reason string) (*domain.StockReservation, error) {
+	reservation, err := s.reservations.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.Status != domain.ReservationPending {
+		return nil, domain.ErrReservationNotPending
+	}
+	if status == domain.ReservationCommitted && reservation.IsExpired(time.Now()) {
+		return nil, domain.ErrReservationExpired
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = reservation.Reason
+	}
+	if err := s.finish(ctx, reservation, status, movementType, reason); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+// finish claims the pending reservation before touching the item so that a
+// commit, a release and the expiry job racing on it apply at most once.
+func (s *StockService) finish(ctx context.Context, reservation *domain.StockReservation, status, movementType, reason string) error {
+	actor, _ := domain.UserIDFromContext(ctx)
+	stockDelta := 0
+	if movementType == domain.StockMovementCommit {
+		stockDelta = -reservation.Quantity
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		claimed, err := s.reservations.UpdateStatus(ctx, reservation.ID, domain.ReservationPending, status)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return domain.ErrReservationNotPending
+		}
+		item, err := s.items.AdjustStock(ctx, reservation.ItemID, stockDelta, -reservation.Quantity, actor)
+		if err != nil {
+			return err
+		}
+		err = s.movements.Save(ctx, &domain.StockMovement{
+			ItemID:        reservation.ItemID,
+			Type:          movementType,
+			StockDelta:    stockDelta,
+			ReservedDelta: -reservation.Quantity,
+			Reason:        reason,
+			ReservationID: &reservation.ID,
+			CreatedBy:     actor,
+		})
+		if err != nil {
+			return err
+		}
+		return s.recordStockChanged(ctx, item, stockDelta, -reservation.Quantity)
+	})
+	if err != nil {
+		return err
+	}
+	reservation.Status = status
+	reservation.UpdatedAt = time.Now()
+	return nil
+}
+// recordStockChanged records the update revision and the event of a stock
+// change, given the item as AdjustStock returned it.
+func (s *StockService) recordStockChanged(ctx context.Context, item *domain.Item, stockDelta, reservedDelta int) error {
+	// The item before the change is rebuilt from the deltas rather than read
+	// beforehand, when a concurrent change could still slip in between.
+	before := *item
+	before.Stock -= stockDelta
+	before.Reserved -= reservedDelta
+	before.Version--
+	before.Status = "INACTIVE"
+	if before.Stock > 0 {
+		before.Status = "ACTIVE"
+	}
+	if err := recordItemRevision(ctx, s.revisions, domain.RevisionUpdate, &before, item); err != nil {
+		return err
+	}
+	return recordItemEvent(ctx, s.outbox, domain.EventItemStockChanged, domain.ItemEvent{
+		ItemID:        item.ID,
+		Item:          item,
+		StockDelta:    stockDelta,
+		ReservedDelta: reservedDelta,
+	})
+}
+func (s *StockService) ExpireReservations(ctx context.Context) (int, error) {
+	expired, err := s.reservations.FindExpired(ctx, time.Now(), expiredReservationBatch)
+	if err != nil {
+		return 0, err
+	}
+	// One reservation that cannot be released must not hold back the rest of
+	// the batch, so failures are collected and reported together.
+	released := 0
+	var errs []error
+	for _, reservation := range expired {
+		err := s.finish(ctx, reservation, domain.ReservationExpired, domain.StockMovementRelease, expiredReservationReason)
+		if err == domain.ErrReservationNotPending {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reservation %d: %w", reservation.ID, err))
+			continue
+		}
+		released++
+	}
+	return released, errors.Join(errs...)
+}
+func (s *StockService) ListMovements(ctx context.Context, itemID int64, page, limit int) ([]*domain.StockMovement, int, error) {
+	if _, err := s.items.FindByID(ctx, itemID); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.movements.FindByItem(ctx, itemID, limit, (page-1)*limit)
+}
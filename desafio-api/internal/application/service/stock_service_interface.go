@@ -0,0 +1,16 @@
+package service
+import (
+	"context"
+	"time"
+	"desafio-api/internal/domain"
+)
+type StockServiceInterface interface {
+	Increment(ctx context.Context, itemID int64, quantity int, reason string) (*domain.Item, error)
+	Decrement(ctx context.Context, itemID int64, quantity int, reason string) (*domain.Item, error)
+	Reserve(ctx context.Context, itemID int64, quantity int, ttl time.Duration, reason string) (*domain.StockReservation, error)
+	GetReservation(ctx context.Context, id int64) (*domain.StockReservation, error)
+	CommitReservation(ctx context.Context, id int64, reason string) (*domain.StockReservation, error)
+	ReleaseReservation(ctx context.Context, id int64, reason string) (*domain.StockReservation, error)
+	ListMovements(ctx context.Context, itemID int64, page, limit int) ([]*domain.StockMovement, int, error)
+}
+var _ StockServiceInterface = (*StockService)(nil)
@@ -0,0 +1,186 @@
+package service_test
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	repoPort "desafio-api/internal/ports/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+type stockFixture struct {
+	ctx    context.Context
+	repo   repoPort.ItemRepository
+	items  *service.ItemService
+	stock  *service.StockService
+	itemID int64
+}
+func setupStock(t *testing.T, initialStock int) *stockFixture {
+	t.Helper()
+	itemRepo := repository.NewMockItemRepository()
+	movements := repository.NewMockStockMovementRepository()
+	revisions := repository.NewMockItemRevisionRepository()
+	tx := repository.NewMockTxManager()
+	fixture := &stockFixture{
+		ctx:   domain.ContextWithUser(context.Background(), 7, domain.RoleEditor),
+		repo:  itemRepo,
+		items: service.NewItemService(itemRepo, movements, repository.NewMockOutboxRepository(), revisions, tx),
+		stock: service.NewStockService(itemRepo, movements, repository.NewMockStockReservationRepository(), repository.NewMockOutboxRepository(), revisions, tx),
+	}
+	item := newTestItem("CAN-001")
+	item.Stock = initialStock
+	require.NoError(t, fixture.items.Create(fixture.ctx, item))
+	fixture.itemID = item.ID
+	return fixture
+}
+func (f *stockFixture) item(t *testing.T) *domain.Item {
+	item, err := f.items.GetByID(f.ctx, f.itemID)
+	require.NoError(t, err)
+	return item
+}
+func TestStockService_IncrementAndDecrement(t *testing.T) {
+	f := setupStock(t, 10)
+	item, err := f.stock.Increment(f.ctx, f.itemID, 5, "recebimento NF 123")
+	require.NoError(t, err)
+	assert.Equal(t, 15, item.Stock)
+	item, err = f.stock.Decrement(f.ctx, f.itemID, 15, "inventário")
+	require.NoError(t, err)
+	assert.Equal(t, 0, item.Stock)
+	assert.Equal(t, "INACTIVE", item.Status)
+	_, err = f.stock.Decrement(f.ctx, f.itemID, 1, "venda")
+	assert.Equal(t, domain.ErrInsufficientStock, err)
+	_, err = f.stock.Increment(f.ctx, f.itemID, 0, "nada")
+	assert.Equal(t, domain.ErrInvalidQuantity, err)
+	_, err = f.stock.Increment(f.ctx, f.itemID, 1, "  ")
+	assert.Equal(t, domain.ErrReasonRequired, err)
+	movements, total, err := f.stock.ListMovements(f.ctx, f.itemID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	assert.Equal(t, domain.StockMovementDecrement, movements[0].Type)
+	assert.Equal(t, -15, movements[0].StockDelta)
+	assert.Equal(t, 7, movements[0].CreatedBy)
+	assert.Equal(t, domain.StockMovementAdjustment, movements[2].Type)
+	assert.Equal(t, 10, movements[2].StockDelta)
+}
+func TestStockService_ReserveAndCommit(t *testing.T) {
+	f := setupStock(t, 10)
+	reservation, err := f.stock.Reserve(f.ctx, f.itemID, 4, time.Minute, "pedido 42")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReservationPending, reservation.Status)
+	item := f.item(t)
+	assert.Equal(t, 10, item.Stock)
+	assert.Equal(t, 6, item.Available())
+	_, err = f.stock.Reserve(f.ctx, f.itemID, 7, time.Minute, "pedido 43")
+	assert.Equal(t, domain.ErrInsufficientStock, err)
+	_, err = f.stock.Decrement(f.ctx, f.itemID, 7, "venda balcão")
+	assert.Equal(t, domain.ErrInsufficientStock, err)
+	committed, err := f.stock.CommitReservation(f.ctx, reservation.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReservationCommitted, committed.Status)
+	item = f.item(t)
+	assert.Equal(t, 6, item.Stock)
+	assert.Equal(t, 0, item.Reserved)
+	_, err = f.stock.ReleaseReservation(f.ctx, reservation.ID, "cancelado")
+	assert.Equal(t, domain.ErrReservationNotPending, err)
+	movements, _, err := f.stock.ListMovements(f.ctx, f.itemID, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StockMovementCommit, movements[0].Type)
+	assert.Equal(t, "pedido 42", movements[0].Reason)
+	assert.Equal(t, reservation.ID, *movements[0].ReservationID)
+}
+func TestStockService_ReleaseAndExpire(t *testing.T) {
+	f := setupStock(t, 10)
+	released, err := f.stock.Reserve(f.ctx, f.itemID, 3, time.Minute, "pedido 1")
+	require.NoError(t, err)
+	_, err = f.stock.ReleaseReservation(f.ctx, released.ID, "pedido cancelado")
+	require.NoError(t, err)
+	expiring, err := f.stock.Reserve(f.ctx, f.itemID, 5, time.Millisecond, "pedido 2")
+	require.NoError(t, err)
+	assert.Equal(t, 5, f.item(t).Reserved)
+	time.Sleep(5 * time.Millisecond)
+	_, err = f.stock.CommitReservation(f.ctx, expiring.ID, "")
+	assert.Equal(t, domain.ErrReservationExpired, err)
+	count, err := f.stock.ExpireReservations(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	reservation, err := f.stock.GetReservation(f.ctx, expiring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReservationExpired, reservation.Status)
+	item := f.item(t)
+	assert.Equal(t, 10, item.Stock)
+	assert.Equal(t, 0, item.Reserved)
+	_, err = f.stock.Reserve(f.ctx, f.itemID, 1, 25*time.Hour, "pedido 3")
+	assert.Equal(t, domain.ErrInvalidReservationTTL, err)
+}
+func TestStockService_ExpireSkipsReservationsThatCannotBeReleased(t *testing.T) {
+	f := setupStock(t, 10)
+	other := newTestItem("CAN-002")
+	require.NoError(t, f.items.Create(f.ctx, other))
+	_, err := f.stock.Reserve(f.ctx, other.ID, 2, time.Millisecond, "pedido 1")
+	require.NoError(t, err)
+	expiring, err := f.stock.Reserve(f.ctx, f.itemID, 3, 2*time.Millisecond, "pedido 2")
+	require.NoError(t, err)
+	// Leave the first reservation pointing at a deleted item, as one made
+	// before deletes checked for reserved stock could be.
+	_, err = f.repo.AdjustStock(f.ctx, other.ID, 0, -2, 7)
+	require.NoError(t, err)
+	require.NoError(t, f.repo.Delete(f.ctx, other.ID, 0, 7))
+	time.Sleep(5 * time.Millisecond)
+	count, err := f.stock.ExpireReservations(f.ctx)
+	assert.ErrorIs(t, err, domain.ErrItemNotFound)
+	assert.Equal(t, 1, count)
+	reservation, err := f.stock.GetReservation(f.ctx, expiring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReservationExpired, reservation.Status)
+}
+func TestStockService_ChangesAppearInHistory(t *testing.T) {
+	f := setupStock(t, 1)
+	_, err := f.stock.Decrement(f.ctx, f.itemID, 1, "venda")
+	require.NoError(t, err)
+	_, err = f.stock.Increment(f.ctx, f.itemID, 3, "recebimento")
+	require.NoError(t, err)
+	reservation, err := f.stock.Reserve(f.ctx, f.itemID, 2, time.Minute, "pedido 1")
+	require.NoError(t, err)
+	_, err = f.stock.CommitReservation(f.ctx, reservation.ID, "")
+	require.NoError(t, err)
+	revisions, total, err := f.items.History(f.ctx, f.itemID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 5, total, "the creation and one update per stock change")
+	item := f.item(t)
+	assert.Equal(t, domain.RevisionUpdate, revisions[0].Action)
+	assert.Equal(t, 7, revisions[0].ActorID)
+	assert.JSONEq(t, `{"stock":{"from":3,"to":1}}`, string(revisions[0].Changes))
+	assert.JSONEq(t, `{}`, string(revisions[1].Changes), "a reservation only changes reserved")
+	assert.JSONEq(t, `{"stock":{"from":0,"to":3},"status":{"from":"INACTIVE","to":"ACTIVE"}}`, string(revisions[2].Changes))
+	assert.JSONEq(t, `{"stock":{"from":1,"to":0},"status":{"from":"ACTIVE","to":"INACTIVE"}}`, string(revisions[3].Changes))
+	var snapshot domain.Item
+	require.NoError(t, json.Unmarshal(revisions[0].Snapshot, &snapshot))
+	assert.Equal(t, item.Version, snapshot.Version)
+}
+func TestItemService_DeleteRejectsItemWithReservations(t *testing.T) {
+	f := setupStock(t, 10)
+	reservation, err := f.stock.Reserve(f.ctx, f.itemID, 4, time.Minute, "pedido 42")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ErrItemHasReservations, f.items.Delete(f.ctx, f.itemID, 0))
+	_, err = f.stock.ReleaseReservation(f.ctx, reservation.ID, "pedido cancelado")
+	require.NoError(t, err)
+	require.NoError(t, f.items.Delete(f.ctx, f.itemID, 0))
+}
+func TestItemService_UpdateCannotDropStockBelowReserved(t *testing.T) {
+	f := setupStock(t, 10)
+	_, err := f.stock.Reserve(f.ctx, f.itemID, 6, time.Minute, "pedido 42")
+	require.NoError(t, err)
+	update := f.item(t)
+	update.Stock = 5
+	assert.Equal(t, domain.ErrInsufficientStock, f.items.Update(f.ctx, f.itemID, update))
+	update = f.item(t)
+	update.Stock = 8
+	require.NoError(t, f.items.Update(f.ctx, f.itemID, update))
+	movements, _, err := f.stock.ListMovements(f.ctx, f.itemID, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StockMovementAdjustment, movements[0].Type)
+	assert.Equal(t, -2, movements[0].StockDelta)
+}
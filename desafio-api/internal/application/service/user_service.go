@@ -1,157 +1,267 @@
 package service
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"time"
 	"desafio-api/internal/domain"
+	"desafio-api/internal/ports/repository"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
 )
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	FindByUsername(ctx context.Context, username string) (*domain.User, error)
 	FindByID(ctx context.Context, id int) (*domain.User, error)
+	FindAll(ctx context.Context) ([]*domain.User, error)
+	UpdateRole(ctx context.Context, id int, role string) error
+}
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	Methods() []string
 }
 type UserService struct {
-	userRepo  UserRepository
-	jwtSecret string
+	userRepo         UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	signer           TokenSigner
+	logger           *slog.Logger
 }
-func NewUserService(userRepo UserRepository) *UserService {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-super-secret-jwt-key"
-		log.Printf("[WARN] JWT Secret not found in environment variables, using default secret (NOT SAFE FOR PRODUCTION)")
-	}
-	log.Printf("[INFO] JWT Secret configured with length: %d", len(secret))
+func NewUserService(userRepo UserRepository, refreshTokenRepo repository.RefreshTokenRepository, signer TokenSigner, logger *slog.Logger) *UserService {
 	return &UserService{
-		userRepo:  userRepo,
-		jwtSecret: secret,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		signer:           signer,
+		logger:           logger,
 	}
 }
-func (s *UserService) Register(ctx context.Context, user *domain.User) error {
-	log.Printf("[DEBUG] UserService.Register: Registering user: %s", user.Username)
+func (s *UserService) Register(ctx context.Context, user *domain.User) (err error) {
+	ctx, span := startSpan(ctx, "UserService.Register")
+	defer endSpan(span, &err)
+	user.Role = domain.RoleViewer
 	if err := user.Validate(); err != nil {
-		log.Printf("[ERROR] UserService.Register: User validation failed: %v", err)
 		return err
 	}
-	if err := user.HashPassword(); err != nil {
-		log.Printf("[ERROR] UserService.Register: Failed to hash password: %v", err)
+	_, hashSpan := startSpan(ctx, "bcrypt.Hash")
+	err = user.HashPassword()
+	hashSpan.End()
+	if err != nil {
 		return err
 	}
 	if err := s.userRepo.Create(ctx, user); err != nil {
-		log.Printf("[ERROR] UserService.Register: Failed to create user in repository: %v", err)
 		return err
 	}
-	log.Printf("[INFO] UserService.Register: User registered successfully: %s (ID: %d)", user.Username, user.ID)
+	s.logger.InfoContext(ctx, "user registered", slog.String("username", user.Username), slog.Int("new_user_id", user.ID))
 	return nil
 }
-func (s *UserService) Login(ctx context.Context, username, password string) (string, error) {
-	log.Printf("[DEBUG] UserService.Login: Login attempt for user: %s", username)
+func (s *UserService) Login(ctx context.Context, username, password string) (_ *domain.TokenPair, err error) {
+	ctx, span := startSpan(ctx, "UserService.Login")
+	defer endSpan(span, &err)
 	if username == "" {
-		log.Printf("[ERROR] UserService.Login: Nome de usuário vazio")
-		return "", domain.ErrInvalidCredentials
+		return nil, domain.ErrInvalidCredentials
 	}
 	if password == "" {
-		log.Printf("[ERROR] UserService.Login: Senha vazia")
-		return "", domain.ErrInvalidCredentials
+		return nil, domain.ErrInvalidCredentials
 	}
 	user, err := s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
-		log.Printf("[ERROR] UserService.Login: User not found: %s, error: %v", username, err)
-		return "", domain.ErrInvalidCredentials
+		s.logger.DebugContext(ctx, "login rejected: user not found", slog.String("username", username), slog.Any("error", err))
+		return nil, domain.ErrInvalidCredentials
 	}
-	if !user.ComparePassword(password) {
-		log.Printf("[ERROR] UserService.Login: Invalid password for user: %s", username)
-		return "", domain.ErrInvalidCredentials
+	_, compareSpan := startSpan(ctx, "bcrypt.Compare")
+	matches := user.ComparePassword(password)
+	compareSpan.End()
+	if !matches {
+		s.logger.DebugContext(ctx, "login rejected: wrong password", slog.String("username", username))
+		return nil, domain.ErrInvalidCredentials
 	}
 	if user.ID <= 0 {
-		log.Printf("[ERROR] UserService.Login: User has invalid ID: %d", user.ID)
-		return "", fmt.Errorf("usuário com ID inválido")
+		s.logger.ErrorContext(ctx, "user has an invalid id", slog.Int("invalid_user_id", user.ID))
+		return nil, fmt.Errorf("usuário com ID inválido")
+	}
+	pair, err := s.issueTokenPair(ctx, user, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+// RefreshToken rotates a refresh token: the presented token is consumed and
+// a new pair is issued in the same family. Presenting a token that was
+// already consumed or revoked revokes the whole family.
+func (s *UserService) RefreshToken(ctx context.Context, refreshToken string) (_ *domain.TokenPair, err error) {
+	ctx, span := startSpan(ctx, "UserService.RefreshToken")
+	defer endSpan(span, &err)
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, stored)
+	}
+	if !now.Before(stored.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
 	}
-	token, err := s.generateToken(user)
+	consumed, err := s.refreshTokenRepo.MarkUsed(ctx, stored.ID, now)
 	if err != nil {
-		log.Printf("[ERROR] UserService.Login: Failed to generate token: %v", err)
+		return nil, err
+	}
+	if !consumed {
+		return nil, s.revokeReusedFamily(ctx, stored)
+	}
+	user, err := s.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "refresh token owner not found", slog.Int("token_user_id", stored.UserID), slog.Any("error", err))
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	return s.issueTokenPair(ctx, user, stored.FamilyID)
+}
+func (s *UserService) Logout(ctx context.Context, refreshToken string) (err error) {
+	ctx, span := startSpan(ctx, "UserService.Logout")
+	defer endSpan(span, &err)
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, hashRefreshToken(refreshToken))
+	if err == domain.ErrInvalidRefreshToken {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "revoking refresh token family", slog.Int("token_user_id", stored.UserID))
+	return s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID, time.Now())
+}
+func (s *UserService) PurgeExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	return s.refreshTokenRepo.DeleteExpired(ctx, time.Now())
+}
+func (s *UserService) revokeReusedFamily(ctx context.Context, stored *domain.RefreshToken) error {
+	s.logger.WarnContext(ctx, "refresh token reuse detected, revoking token family", slog.Int("token_user_id", stored.UserID))
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID, time.Now()); err != nil {
+		return err
+	}
+	return domain.ErrRefreshTokenReused
+}
+func (s *UserService) issueTokenPair(ctx context.Context, user *domain.User, familyID string) (*domain.TokenPair, error) {
+	accessToken, err := s.generateToken(user)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	stored := &domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashRefreshToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(refreshTokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.refreshTokenRepo.Save(ctx, stored); err != nil {
+		return nil, err
+	}
+	return &domain.TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(accessTokenTTL.Seconds()),
+		RefreshExpiresIn: int64(refreshTokenTTL.Seconds()),
+	}, nil
+}
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
 		return "", err
 	}
-	log.Printf("[INFO] UserService.Login: Login successful for user: %s", username)
-	return token, nil
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 func (s *UserService) ValidateToken(tokenString string) (*domain.JWTClaims, error) {
 	if len(tokenString) < 10 {
-		log.Printf("[ERROR] UserService.ValidateToken: Token too short: %s", tokenString)
 		return nil, domain.ErrInvalidToken
 	}
-	log.Printf("[DEBUG] UserService.ValidateToken: Validating token: %s...", tokenString[:10])
-	token, err := jwt.ParseWithClaims(tokenString, &domain.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			log.Printf("[ERROR] UserService.ValidateToken: Unexpected signing method: %v", token.Header["alg"])
-			return nil, domain.ErrInvalidToken
-		}
-		return []byte(s.jwtSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &domain.JWTClaims{}, s.signer.Keyfunc, jwt.WithValidMethods(s.signer.Methods()))
 	if err != nil {
-		log.Printf("[ERROR] UserService.ValidateToken: Token validation failed: %v", err)
+		s.logger.Debug("access token rejected", slog.Any("error", err))
 		return nil, domain.ErrInvalidToken
 	}
 	claims, ok := token.Claims.(*domain.JWTClaims)
 	if !ok || !token.Valid {
-		log.Printf("[ERROR] UserService.ValidateToken: Invalid token claims")
 		return nil, domain.ErrInvalidToken
 	}
-	log.Printf("[DEBUG] UserService.ValidateToken: Token validated successfully for user ID: %d", claims.UserID)
 	return claims, nil
 }
 func (s *UserService) GetUserByID(ctx context.Context, id int) (*domain.User, error) {
-	log.Printf("[DEBUG] UserService.GetUserByID: Getting user with ID: %d", id)
 	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
-		log.Printf("[ERROR] UserService.GetUserByID: Failed to find user with ID %d: %v", id, err)
 		return nil, err
 	}
 	return user, nil
 }
 func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
-	log.Printf("[DEBUG] UserService.GetUserByUsername: Buscando usuário pelo username: %s", username)
 	user, err := s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
-		log.Printf("[ERROR] UserService.GetUserByUsername: Erro ao buscar usuário: %v", err)
 		return nil, err
 	}
 	return user, nil
 }
+func (s *UserService) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	return s.userRepo.FindAll(ctx)
+}
+func (s *UserService) AssignRole(ctx context.Context, userID int, role string) (*domain.User, error) {
+	if !domain.IsValidRole(role) {
+		return nil, domain.ErrInvalidRole
+	}
+	if actorID, ok := domain.UserIDFromContext(ctx); ok && actorID == userID {
+		return nil, domain.ErrCannotChangeOwnRole
+	}
+	if err := s.userRepo.UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "role assigned", slog.Int("target_user_id", userID), slog.String("role", role))
+	return s.userRepo.FindByID(ctx, userID)
+}
+func (s *UserService) BootstrapAdmin(ctx context.Context, username string) error {
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user.Role == domain.RoleAdmin {
+		return nil
+	}
+	s.logger.InfoContext(ctx, "promoting bootstrap admin", slog.String("username", username))
+	return s.userRepo.UpdateRole(ctx, user.ID, domain.RoleAdmin)
+}
 func (s *UserService) GetRepository() interface{} {
 	return s.userRepo
 }
-func (s *UserService) GetJWTSecret() string {
-	return s.jwtSecret
-}
 func (s *UserService) generateToken(user *domain.User) (string, error) {
-	log.Printf("[DEBUG] UserService.generateToken: Generating token for user ID: %d", user.ID)
 	now := time.Now()
-	expirationTime := now.Add(1 * time.Hour)
+	expirationTime := now.Add(accessTokenTTL)
 	if user.ID <= 0 {
-		log.Printf("[ERROR] UserService.generateToken: Tentando gerar token para usuário com ID inválido: %d", user.ID)
 		return "", fmt.Errorf("ID de usuário inválido")
 	}
 	claims := &domain.JWTClaims{
 		UserID:   user.ID,
 		Username: user.Username,
+		Role:     user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	if s.jwtSecret == "" {
-		log.Printf("[ERROR] UserService.generateToken: JWT secret está vazio")
-		s.jwtSecret = "your-super-secret-jwt-key" 
-	}
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	tokenString, err := s.signer.Sign(claims)
 	if err != nil {
-		log.Printf("[ERROR] UserService.generateToken: Failed to sign token: %v", err)
 		return "", err
 	}
-	log.Printf("[INFO] UserService.generateToken: Token generated successfully for user: %s", user.Username)
 	return tokenString, nil
 }
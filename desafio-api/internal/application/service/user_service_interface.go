@@ -5,11 +5,14 @@ import (
 )
 type UserServiceInterface interface {
 	Register(ctx context.Context, user *domain.User) error
-	Login(ctx context.Context, username, password string) (string, error)
+	Login(ctx context.Context, username, password string) (*domain.TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*domain.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
 	ValidateToken(tokenString string) (*domain.JWTClaims, error)
 	GetUserByID(ctx context.Context, id int) (*domain.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
-	GetJWTSecret() string
+	ListUsers(ctx context.Context) ([]*domain.User, error)
+	AssignRole(ctx context.Context, userID int, role string) (*domain.User, error)
 	GetRepository() interface{}
 }
 var _ UserServiceInterface = (*UserService)(nil)
@@ -0,0 +1,75 @@
+package service_test
+import (
+	"context"
+	"testing"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/auth"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/adapters/tracing"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+func setupUserService(t *testing.T) *service.UserService {
+	t.Helper()
+	signer, err := auth.NewEphemeralKeySet()
+	require.NoError(t, err)
+	userService := service.NewUserService(repository.NewMockUserRepository(), repository.NewMockRefreshTokenRepository(), signer, logging.Nop())
+	err = userService.Register(context.Background(), &domain.User{Username: "maria", Password: "password123"})
+	require.NoError(t, err)
+	return userService
+}
+func TestUserService_LoginIssuesTokenPair(t *testing.T) {
+	userService := setupUserService(t)
+	pair, err := userService.Login(context.Background(), "maria", "password123")
+	require.NoError(t, err)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
+	claims, err := userService.ValidateToken(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "maria", claims.Username)
+	assert.Equal(t, domain.RoleViewer, claims.Role)
+}
+func TestUserService_RefreshTokenRotation(t *testing.T) {
+	userService := setupUserService(t)
+	ctx := context.Background()
+	first, err := userService.Login(ctx, "maria", "password123")
+	require.NoError(t, err)
+	second, err := userService.RefreshToken(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	_, err = userService.RefreshToken(ctx, first.RefreshToken)
+	assert.Equal(t, domain.ErrRefreshTokenReused, err)
+	_, err = userService.RefreshToken(ctx, second.RefreshToken)
+	assert.Equal(t, domain.ErrRefreshTokenReused, err, "reuse must revoke the whole family")
+}
+func TestUserService_Logout(t *testing.T) {
+	userService := setupUserService(t)
+	ctx := context.Background()
+	pair, err := userService.Login(ctx, "maria", "password123")
+	require.NoError(t, err)
+	require.NoError(t, userService.Logout(ctx, pair.RefreshToken))
+	_, err = userService.RefreshToken(ctx, pair.RefreshToken)
+	assert.Error(t, err)
+	assert.NoError(t, userService.Logout(ctx, "unknown-token"))
+}
+func TestUserService_RefreshUnknownToken(t *testing.T) {
+	userService := setupUserService(t)
+	_, err := userService.RefreshToken(context.Background(), "unknown-token")
+	assert.Equal(t, domain.ErrInvalidRefreshToken, err)
+}
+func TestUserService_LoginTracesPasswordCheck(t *testing.T) {
+	userService := setupUserService(t)
+	spans := tracing.InMemory()
+	_, err := userService.Login(context.Background(), "maria", "wrong-password")
+	assert.Equal(t, domain.ErrInvalidCredentials, err)
+	ended := spans.GetSpans()
+	require.Len(t, ended, 2)
+	compare, login := ended[0], ended[1]
+	assert.Equal(t, "bcrypt.Compare", compare.Name)
+	assert.Equal(t, "UserService.Login", login.Name)
+	assert.Equal(t, login.SpanContext.SpanID(), compare.Parent.SpanID())
+	assert.Equal(t, codes.Error, login.Status.Code)
+}
@@ -0,0 +1,225 @@
+package service
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+	"desafio-api/internal/domain"
+	"desafio-api/internal/ports/repository"
+)
+const (
+	DefaultWebhookMaxAttempts = 8
+	webhookDeliveryBatch      = 20
+	webhookDeliveryLease      = time.Minute
+	webhookRetryBaseDelay     = 30 * time.Second
+	webhookRetryMaxDelay      = time.Hour
+)
+// WebhookSender POSTs a delivery to its subscription and returns the HTTP
+// status of the response, if one was received.
+type WebhookSender interface {
+	Send(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error)
+}
+// WebhookService manages subscriptions and delivers item events to them. It
+// is also an EventPublisher: publishing an event queues one delivery per
+// interested subscription.
+type WebhookService struct {
+	webhooks    repository.WebhookRepository
+	sender      WebhookSender
+	maxAttempts int
+	logger      *slog.Logger
+}
+func NewWebhookService(webhooks repository.WebhookRepository, sender WebhookSender, maxAttempts int, logger *slog.Logger) *WebhookService {
+	if maxAttempts < 1 {
+		maxAttempts = DefaultWebhookMaxAttempts
+	}
+	return &WebhookService{webhooks: webhooks, sender: sender, maxAttempts: maxAttempts, logger: logger}
+}
+// Create stores the subscription, generating a secret when none is given.
+func (s *WebhookService) Create(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	subscription.URL = strings.TrimSpace(subscription.URL)
+	if err := subscription.Validate(); err != nil {
+		return err
+	}
+	if subscription.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return err
+		}
+		subscription.Secret = secret
+	}
+	if userID, ok := domain.UserIDFromContext(ctx); ok {
+		subscription.CreatedBy = userID
+	}
+	return s.webhooks.SaveSubscription(ctx, subscription)
+}
+// Update replaces the url, events and active flag; the secret only changes
+// when a new one is given.
+func (s *WebhookService) Update(ctx context.Context, id int64, changes *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	subscription, err := s.webhooks.FindSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.URL = strings.TrimSpace(changes.URL)
+	subscription.EventTypes = changes.EventTypes
+	subscription.Active = changes.Active
+	if changes.Secret != "" {
+		subscription.Secret = changes.Secret
+	}
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.webhooks.UpdateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+func (s *WebhookService) Delete(ctx context.Context, id int64) error {
+	return s.webhooks.DeleteSubscription(ctx, id)
+}
+func (s *WebhookService) Get(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	return s.webhooks.FindSubscription(ctx, id)
+}
+func (s *WebhookService) List(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return s.webhooks.ListSubscriptions(ctx)
+}
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID int64, status string, page, limit int) ([]*domain.WebhookDelivery, int, error) {
+	if _, err := s.webhooks.FindSubscription(ctx, subscriptionID); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.webhooks.ListDeliveries(ctx, subscriptionID, status, limit, (page-1)*limit)
+}
+// Redeliver queues a delivery again with a fresh set of attempts, including
+// ones that already succeeded or were dead-lettered.
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID int64) (*domain.WebhookDelivery, error) {
+	delivery, err := s.webhooks.FindDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	delivery.Status = domain.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.DeliveredAt = nil
+	if err := s.webhooks.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+// Publish queues the event for every active subscription that wants it. It
+// runs inside the outbox relay transaction.
+func (s *WebhookService) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	subscriptions, err := s.webhooks.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event.Envelope()); err != nil {
+				return err
+			}
+		}
+		err := s.webhooks.SaveDelivery(ctx, &domain.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         domain.DeliveryPending,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// DeliverDue sends every delivery whose attempt is due and returns how many
+// succeeded.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		now := time.Now()
+		deliveries, err := s.webhooks.ClaimDue(ctx, now, now.Add(webhookDeliveryLease), webhookDeliveryBatch)
+		if err != nil || len(deliveries) == 0 {
+			return delivered, err
+		}
+		for _, delivery := range deliveries {
+			if err := ctx.Err(); err != nil {
+				return delivered, err
+			}
+			ok, err := s.deliver(ctx, delivery)
+			if err != nil {
+				return delivered, err
+			}
+			if ok {
+				delivered++
+			}
+		}
+	}
+}
+func (s *WebhookService) deliver(ctx context.Context, delivery *domain.WebhookDelivery) (bool, error) {
+	subscription, err := s.webhooks.FindSubscription(ctx, delivery.SubscriptionID)
+	if err == domain.ErrWebhookNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if !subscription.Active {
+		delivery.Status = domain.DeliveryDead
+		delivery.LastError = "assinatura desativada"
+		return false, s.webhooks.UpdateDelivery(ctx, delivery)
+	}
+	statusCode, sendErr := s.sender.Send(ctx, subscription, delivery)
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.DeliverySucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.maxAttempts:
+		delivery.Status = domain.DeliveryDead
+		delivery.LastError = sendErr.Error()
+		s.logger.WarnContext(ctx, "webhook delivery dead after max attempts", slog.Int64("delivery_id", delivery.ID), slog.Int64("webhook_id", subscription.ID), slog.Int("attempts", delivery.Attempts), slog.Any("error", sendErr))
+	default:
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = now.Add(webhookRetryDelay(delivery.Attempts))
+	}
+	updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	return sendErr == nil, s.webhooks.UpdateDelivery(updateCtx, delivery)
+}
+// webhookRetryDelay doubles the wait after every failed attempt.
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempts && delay < webhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookRetryMaxDelay {
+		delay = webhookRetryMaxDelay
+	}
+	return delay
+}
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
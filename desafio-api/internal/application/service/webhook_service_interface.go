@@ -0,0 +1,15 @@
+package service
+import (
+	"context"
+	"desafio-api/internal/domain"
+)
+type WebhookServiceInterface interface {
+	Create(ctx context.Context, subscription *domain.WebhookSubscription) error
+	Update(ctx context.Context, id int64, changes *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	Delete(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+	List(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	ListDeliveries(ctx context.Context, subscriptionID int64, status string, page, limit int) ([]*domain.WebhookDelivery, int, error)
+	Redeliver(ctx context.Context, subscriptionID, deliveryID int64) (*domain.WebhookDelivery, error)
+}
+var _ WebhookServiceInterface = (*WebhookService)(nil)
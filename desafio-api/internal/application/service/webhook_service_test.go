@@ -0,0 +1,154 @@
+package service_test
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/events"
+	"desafio-api/internal/adapters/repository"
+	"desafio-api/internal/application/service"
+	"desafio-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+type webhookReceiver struct {
+	*httptest.Server
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+	mu       sync.Mutex
+}
+func newWebhookReceiver(t *testing.T, status int) *webhookReceiver {
+	receiver := &webhookReceiver{status: status}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		w.WriteHeader(receiver.status)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+type webhookFixture struct {
+	*outboxFixture
+	webhooks *repository.MockWebhookRepository
+	service  *service.WebhookService
+}
+func setupWebhooks(maxAttempts int) *webhookFixture {
+	f := &webhookFixture{outboxFixture: setupOutbox(), webhooks: repository.NewMockWebhookRepository()}
+	f.service = service.NewWebhookService(f.webhooks, events.NewWebhookSender(time.Second), maxAttempts, logging.Nop())
+	f.relay = service.NewOutboxRelay(f.outbox, f.service, logging.Nop())
+	return f
+}
+func (f *webhookFixture) subscribe(t *testing.T, url string, eventTypes ...string) *domain.WebhookSubscription {
+	subscription := &domain.WebhookSubscription{URL: url, Secret: "s3cret", EventTypes: eventTypes, Active: true}
+	require.NoError(t, f.service.Create(f.ctx, subscription))
+	return subscription
+}
+func (f *webhookFixture) createItemAndRelay(t *testing.T) *domain.Item {
+	item := newTestItem("CAN-001")
+	require.NoError(t, f.items.Create(f.ctx, item))
+	_, err := f.relay.RelayPending(f.ctx)
+	require.NoError(t, err)
+	return item
+}
+func TestWebhookService_DeliversSignedEvents(t *testing.T) {
+	f := setupWebhooks(3)
+	receiver := newWebhookReceiver(t, http.StatusOK)
+	subscription := f.subscribe(t, receiver.URL, domain.EventItemCreated)
+	f.subscribe(t, receiver.URL, domain.EventItemDeleted)
+	item := f.createItemAndRelay(t)
+	delivered, err := f.service.DeliverDue(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	require.Len(t, receiver.requests, 1)
+	request, body := receiver.requests[0], receiver.bodies[0]
+	assert.Equal(t, events.Sign("s3cret", body), request.Header.Get("X-Signature"))
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", request.Header.Get("X-Signature"))
+	assert.Equal(t, domain.EventItemCreated, request.Header.Get("X-Event-Type"))
+	var envelope domain.EventEnvelope
+	require.NoError(t, json.Unmarshal(body, &envelope))
+	assert.Equal(t, domain.EventItemCreated, envelope.Type)
+	assert.Equal(t, item.ID, envelope.AggregateID)
+	deliveries, total, err := f.service.ListDeliveries(f.ctx, subscription.ID, "", 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, domain.DeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, http.StatusOK, deliveries[0].LastStatusCode)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+	delivered, err = f.service.DeliverDue(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+}
+func TestWebhookService_RepublishedEventIsDeliveredOnce(t *testing.T) {
+	f := setupWebhooks(3)
+	receiver := newWebhookReceiver(t, http.StatusOK)
+	subscription := f.subscribe(t, receiver.URL, domain.EventItemCreated)
+	f.createItemAndRelay(t)
+	event := f.outbox.Events()[0]
+	require.NoError(t, f.service.Publish(f.ctx, event))
+	_, total, err := f.service.ListDeliveries(f.ctx, subscription.ID, "", 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+func TestWebhookService_RetriesThenDeadLetters(t *testing.T) {
+	f := setupWebhooks(2)
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError)
+	subscription := f.subscribe(t, receiver.URL, domain.EventItemCreated)
+	f.createItemAndRelay(t)
+	_, err := f.service.DeliverDue(f.ctx)
+	require.NoError(t, err)
+	deliveries, _, err := f.service.ListDeliveries(f.ctx, subscription.ID, domain.DeliveryPending, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+	assert.Equal(t, "webhook responded with status 500", delivery.LastError)
+	assert.True(t, delivery.NextAttemptAt.After(time.Now().Add(20*time.Second)), "retries back off")
+	delivery.NextAttemptAt = time.Now()
+	require.NoError(t, f.webhooks.UpdateDelivery(f.ctx, delivery))
+	_, err = f.service.DeliverDue(f.ctx)
+	require.NoError(t, err)
+	dead, err := f.webhooks.FindDelivery(f.ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryDead, dead.Status)
+	assert.Equal(t, 2, dead.Attempts)
+	assert.Len(t, receiver.requests, 2)
+	receiver.status = http.StatusNoContent
+	redelivered, err := f.service.Redeliver(f.ctx, subscription.ID, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryPending, redelivered.Status)
+	assert.Equal(t, 0, redelivered.Attempts)
+	delivered, err := f.service.DeliverDue(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	_, err = f.service.Redeliver(f.ctx, subscription.ID+1, delivery.ID)
+	assert.Equal(t, domain.ErrDeliveryNotFound, err)
+}
+func TestWebhookService_ValidatesSubscriptions(t *testing.T) {
+	f := setupWebhooks(3)
+	for _, subscription := range []*domain.WebhookSubscription{
+		{URL: "ftp://example.com/hook", EventTypes: []string{domain.EventItemCreated}},
+		{URL: "/relative", EventTypes: []string{domain.EventItemCreated}},
+		{URL: "https://example.com/hook"},
+		{URL: "https://example.com/hook", EventTypes: []string{"UserCreated"}},
+	} {
+		assert.Error(t, f.service.Create(f.ctx, subscription), subscription.URL)
+	}
+	subscription := &domain.WebhookSubscription{URL: " https://example.com/hook ", EventTypes: []string{domain.EventItemDeleted}, Active: true}
+	require.NoError(t, f.service.Create(f.ctx, subscription))
+	assert.Equal(t, "https://example.com/hook", subscription.URL)
+	assert.Len(t, subscription.Secret, 64, "a secret is generated when none is given")
+	assert.Equal(t, 7, subscription.CreatedBy)
+	updated, err := f.service.Update(f.ctx, subscription.ID, &domain.WebhookSubscription{URL: "https://example.com/v2", EventTypes: domain.ItemEventTypes})
+	require.NoError(t, err)
+	assert.False(t, updated.Active)
+	assert.Equal(t, subscription.Secret, updated.Secret, "the secret is kept when not replaced")
+}
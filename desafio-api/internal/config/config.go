@@ -0,0 +1,271 @@
+package config
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"desafio-api/internal/adapters/logging"
+	"desafio-api/internal/adapters/ratelimit"
+	"desafio-api/internal/adapters/tracing"
+	"desafio-api/internal/application/service"
+)
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+// Config is every setting of the API. Each leaf field is read, in increasing
+// order of precedence, from its default, the config file (keys follow the
+// config tags, e.g. database.host), the environment variable in its env tag
+// and the command line flag of the same name as the file key. Fields tagged
+// secret are hidden by Redacted.
+type Config struct {
+	Env       string          `config:"env" env:"APP_ENV"`
+	Server    ServerConfig    `config:"server"`
+	Database  DatabaseConfig  `config:"database"`
+	Auth      AuthConfig      `config:"auth"`
+	RateLimit RateLimitConfig `config:"rate_limit"`
+	Jobs      JobsConfig      `config:"jobs"`
+	Events    EventsConfig    `config:"events"`
+	Items     ItemsConfig     `config:"items"`
+	Log       LogConfig       `config:"log"`
+	Tracing   TracingConfig   `config:"tracing"`
+	Health    HealthConfig    `config:"health"`
+	Debug     DebugConfig     `config:"debug"`
+	Metrics   MetricsConfig   `config:"metrics"`
+}
+type ServerConfig struct {
+	Port            int           `config:"port" env:"APP_PORT"`
+	ReadTimeout     time.Duration `config:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `config:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `config:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `config:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// ShutdownDrain is how long /readyz fails before the server stops
+	// accepting connections.
+	ShutdownDrain   time.Duration `config:"shutdown_drain" env:"SHUTDOWN_DRAIN"`
+	// TrustedProxies lists the comma separated addresses or CIDRs of the
+	// proxies whose X-Forwarded-For is believed; empty trusts none.
+	TrustedProxies  string        `config:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+// TrustedProxyList splits TrustedProxies, returning nil when it is empty.
+func (c ServerConfig) TrustedProxyList() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(c.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+type DatabaseConfig struct {
+	Driver       string        `config:"driver" env:"DB_DRIVER"`
+	Host         string        `config:"host" env:"DB_HOST"`
+	// Port defaults to 3306 for MySQL and 5432 for PostgreSQL.
+	Port         string        `config:"port" env:"DB_PORT"`
+	User         string        `config:"user" env:"DB_USER"`
+	Password     string        `config:"password" env:"DB_PASSWORD" secret:"true"`
+	Name         string        `config:"name" env:"DB_NAME"`
+	SSLMode      string        `config:"sslmode" env:"DB_SSLMODE"`
+	AutoMigrate  bool          `config:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	MaxOpenConns int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	MaxIdleTime  time.Duration `config:"max_idle_time" env:"DB_MAX_IDLE_TIME"`
+}
+type AuthConfig struct {
+	JWTSecret     string `config:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	JWTKeysDir    string `config:"jwt_keys_dir" env:"JWT_KEYS_DIR"`
+	JWTActiveKey  string `config:"jwt_active_kid" env:"JWT_ACTIVE_KID"`
+	CursorSecret  string `config:"cursor_secret" env:"CURSOR_SECRET" secret:"true"`
+	AdminUsername string `config:"admin_username" env:"ADMIN_USERNAME"`
+	LoginLockout  int    `config:"login_lockout_threshold" env:"LOGIN_LOCKOUT_THRESHOLD"`
+}
+type RateLimitConfig struct {
+	Auth       ratelimit.Limit `config:"auth" env:"RATE_LIMIT_AUTH"`
+	APIPerIP   ratelimit.Limit `config:"api_ip" env:"RATE_LIMIT_API_IP"`
+	APIPerUser ratelimit.Limit `config:"api_user" env:"RATE_LIMIT_API_USER"`
+}
+type JobsConfig struct {
+	Dir           string        `config:"dir" env:"JOBS_DIR"`
+	Workers       int           `config:"workers" env:"JOB_WORKERS"`
+	// FileRetention is how long finished export files stay downloadable.
+	FileRetention time.Duration `config:"file_retention" env:"JOB_FILE_RETENTION"`
+}
+type EventsConfig struct {
+	Publisher          string        `config:"publisher" env:"EVENTS_PUBLISHER"`
+	WebhookURL         string        `config:"webhook_url" env:"EVENTS_WEBHOOK_URL"`
+	WebhookMaxAttempts int           `config:"webhook_max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookTimeout     time.Duration `config:"webhook_timeout" env:"WEBHOOK_TIMEOUT"`
+}
+type ItemsConfig struct {
+	RetentionDays int `config:"deleted_retention_days" env:"DELETED_ITEMS_RETENTION_DAYS"`
+}
+type LogConfig struct {
+	Level  string `config:"level" env:"LOG_LEVEL"`
+	Format string `config:"format" env:"LOG_FORMAT"`
+}
+type TracingConfig struct {
+	Exporter    string `config:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string `config:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+type HealthConfig struct {
+	OutboxMaxLag  time.Duration `config:"outbox_max_lag" env:"HEALTH_OUTBOX_MAX_LAG"`
+	DiskMinFreeMB int           `config:"disk_min_free_mb" env:"HEALTH_DISK_MIN_FREE_MB"`
+}
+// DebugConfig controls the /debug routes, which are only mounted when
+// Enabled and always require an admin or the debug token.
+type DebugConfig struct {
+	Enabled bool   `config:"enabled" env:"DEBUG_ENABLED"`
+	// Addr serves the debug routes on their own listener, e.g.
+	// 127.0.0.1:6060; empty mounts them on the main server.
+	Addr    string `config:"addr" env:"DEBUG_ADDR"`
+	Token   string `config:"token" env:"DEBUG_TOKEN" secret:"true"`
+	Pprof   bool   `config:"pprof" env:"DEBUG_PPROF"`
+}
+// MetricsConfig controls GET /metrics, which has no authentication and is
+// only served when Enabled.
+type MetricsConfig struct {
+	Enabled bool   `config:"enabled" env:"METRICS_ENABLED"`
+	// Addr serves /metrics on its own listener, e.g. 127.0.0.1:9090; empty
+	// mounts it on the main server.
+	Addr    string `config:"addr" env:"METRICS_ADDR"`
+}
+func Default() Config {
+	return Config{
+		Env: EnvDevelopment,
+		Server: ServerConfig{
+			Port:            8080,
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+			ShutdownDrain:   5 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:       database.DriverMySQL,
+			Host:         "localhost",
+			User:         "root",
+			Name:         "mercadolibre_challenge",
+			SSLMode:      "disable",
+			AutoMigrate:  true,
+			MaxOpenConns: 25,
+			MaxIdleConns: 25,
+			MaxIdleTime:  15 * time.Minute,
+		},
+		Auth: AuthConfig{
+			LoginLockout: ratelimit.DefaultLockoutPolicy.Threshold,
+		},
+		RateLimit: RateLimitConfig{
+			Auth:       ratelimit.Limit{Requests: 10, Window: time.Minute},
+			APIPerIP:   ratelimit.Limit{Requests: 600, Window: time.Minute},
+			APIPerUser: ratelimit.Limit{Requests: 300, Window: time.Minute},
+		},
+		Jobs: JobsConfig{
+			Dir:           filepath.Join(os.TempDir(), "desafio-api-jobs"),
+			Workers:       2,
+			FileRetention: 24 * time.Hour,
+		},
+		Events: EventsConfig{
+			Publisher:          "log",
+			WebhookMaxAttempts: service.DefaultWebhookMaxAttempts,
+			WebhookTimeout:     10 * time.Second,
+		},
+		Items: ItemsConfig{RetentionDays: 30},
+		Log:   LogConfig{Level: "info", Format: logging.FormatJSON},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "desafio-api",
+		},
+		Health: HealthConfig{
+			OutboxMaxLag:  5 * time.Minute,
+			DiskMinFreeMB: 100,
+		},
+		Debug: DebugConfig{Pprof: true},
+	}
+}
+func (c Config) Production() bool {
+	return c.Env == EnvProduction
+}
+// defaultSecrets are placeholders from the docs and examples that must never
+// sign anything in production.
+var defaultSecrets = map[string]bool{
+	"your-super-secret-jwt-key": true,
+	"secret":                    true,
+	"changeme":                  true,
+	"change-me":                 true,
+	"password":                  true,
+}
+const minSecretLength = 32
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(c.Env == EnvDevelopment || c.Env == EnvProduction, "env: must be %s or %s, got %q", EnvDevelopment, EnvProduction, c.Env)
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port: must be between 1 and 65535, got %d", c.Server.Port)
+	check(c.Server.ShutdownDrain >= 0, "server.shutdown_drain: must not be negative")
+	for _, proxy := range c.Server.TrustedProxyList() {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "server.trusted_proxies: %q is not an IP address or CIDR", proxy)
+	}
+	check(c.Database.Driver == database.DriverMySQL || c.Database.Driver == database.DriverPostgres,
+		"database.driver: must be %s or %s, got %q", database.DriverMySQL, database.DriverPostgres, c.Database.Driver)
+	check(c.Database.Host != "", "database.host: required")
+	check(c.Database.Name != "", "database.name: required")
+	check(c.Database.MaxOpenConns >= 0 && c.Database.MaxIdleConns >= 0, "database: connection pool sizes must not be negative")
+	check(c.Jobs.Dir != "", "jobs.dir: required")
+	check(c.Jobs.Workers > 0, "jobs.workers: must be at least 1, got %d", c.Jobs.Workers)
+	check(c.Jobs.FileRetention > 0, "jobs.file_retention: must be positive")
+	check(c.Events.Publisher == "log" || c.Events.Publisher == "webhook", "events.publisher: must be log or webhook, got %q", c.Events.Publisher)
+	check(c.Events.Publisher != "webhook" || c.Events.WebhookURL != "", "events.webhook_url: required when events.publisher is webhook")
+	check(c.Events.WebhookMaxAttempts > 0, "events.webhook_max_attempts: must be at least 1, got %d", c.Events.WebhookMaxAttempts)
+	check(c.Items.RetentionDays > 0, "items.deleted_retention_days: must be at least 1, got %d", c.Items.RetentionDays)
+	check(c.Auth.LoginLockout >= 0, "auth.login_lockout_threshold: must not be negative")
+	if err := (logging.Config{Level: c.Log.Level, Format: c.Log.Format}).Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("log: %w", err))
+	}
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter: must be %s, %s or %s, got %q", tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout, c.Tracing.Exporter))
+	}
+	if c.Debug.Enabled && c.Debug.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Debug.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("debug.addr: %w", err))
+		}
+	}
+	if c.Metrics.Enabled && c.Metrics.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("metrics.addr: %w", err))
+		}
+	}
+	if c.Production() {
+		if c.Auth.JWTKeysDir == "" {
+			errs = append(errs, productionSecret("auth.jwt_secret", c.Auth.JWTSecret, " (or set auth.jwt_keys_dir)"))
+		}
+		errs = append(errs, productionSecret("auth.cursor_secret", c.Auth.CursorSecret, ""))
+		if c.Debug.Enabled && c.Debug.Token != "" {
+			errs = append(errs, productionSecret("debug.token", c.Debug.Token, ""))
+		}
+		if defaultSecrets[c.Database.Password] {
+			errs = append(errs, errors.New("database.password: default password not allowed in production"))
+		}
+	}
+	return errors.Join(errs...)
+}
+func productionSecret(key, value, alternative string) error {
+	switch {
+	case value == "":
+		return fmt.Errorf("%s: required in production%s", key, alternative)
+	case defaultSecrets[value]:
+		return fmt.Errorf("%s: default secret not allowed in production", key)
+	case len(value) < minSecretLength:
+		return fmt.Errorf("%s: must have at least %d characters in production", key, minSecretLength)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package config
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"desafio-api/internal/adapters/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+func TestLoad_Defaults(t *testing.T) {
+	cfg, args, err := Load([]string{"migrate", "up"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"migrate", "up"}, args)
+	assert.Equal(t, 8080, cfg.Server.Port)
+	assert.Equal(t, "3306", cfg.Database.Port)
+	assert.Equal(t, ratelimit.Limit{Requests: 10, Window: time.Minute}, cfg.RateLimit.Auth)
+}
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+  shutdown_drain: 1s
+database:
+  driver: postgres
+  host: file-host
+  name: file-db
+rate_limit:
+  auth: 5/30s
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("DB_NAME", "env-db")
+	t.Setenv("JOB_WORKERS", "")
+	cfg, _, err := Load([]string{"-database.name=flag-db", "-database.auto_migrate=false"})
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Server.Port)
+	assert.Equal(t, time.Second, cfg.Server.ShutdownDrain)
+	assert.Equal(t, "postgres", cfg.Database.Driver)
+	assert.Equal(t, "5432", cfg.Database.Port)
+	assert.Equal(t, "env-host", cfg.Database.Host)
+	assert.Equal(t, "flag-db", cfg.Database.Name)
+	assert.False(t, cfg.Database.AutoMigrate)
+	assert.Equal(t, 2, cfg.Jobs.Workers)
+	assert.Equal(t, ratelimit.Limit{Requests: 5, Window: 30 * time.Second}, cfg.RateLimit.Auth)
+}
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+env = "development"
+[events]
+publisher = "webhook"
+webhook_url = "https://example.com/events"
+webhook_timeout = "3s"
+`)
+	cfg, _, err := Load([]string{"-config", path})
+	require.NoError(t, err)
+	assert.Equal(t, "webhook", cfg.Events.Publisher)
+	assert.Equal(t, 3*time.Second, cfg.Events.WebhookTimeout)
+}
+func TestLoad_Errors(t *testing.T) {
+	_, _, err := Load([]string{"-config", writeFile(t, "config.yaml", "database:\n  hots: typo\n")})
+	assert.ErrorContains(t, err, "database.hots: unknown key")
+	t.Setenv("APP_PORT", "eighty")
+	_, _, err = Load(nil)
+	assert.ErrorContains(t, err, "APP_PORT")
+	t.Setenv("APP_PORT", "70000")
+	_, _, err = Load(nil)
+	assert.ErrorContains(t, err, "server.port")
+}
+func TestValidate_Production(t *testing.T) {
+	cfg := Default()
+	cfg.Env = EnvProduction
+	cfg.Auth.JWTSecret = "your-super-secret-jwt-key"
+	cfg.Auth.CursorSecret = "short"
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "auth.jwt_secret: default secret not allowed in production")
+	assert.ErrorContains(t, err, "auth.cursor_secret: must have at least 32 characters")
+	cfg.Auth.JWTSecret = ""
+	cfg.Auth.JWTKeysDir = "/etc/desafio-api/keys"
+	cfg.Auth.CursorSecret = "0123456789abcdef0123456789abcdef"
+	assert.NoError(t, cfg.Validate())
+	cfg.Env = EnvDevelopment
+	cfg.Auth.CursorSecret = "secret"
+	assert.NoError(t, cfg.Validate())
+}
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "hunter2"
+	cfg.Auth.JWTSecret = "jwt"
+	dump := cfg.Redacted()
+	database := dump["database"].(map[string]interface{})
+	assert.Equal(t, redacted, database["password"])
+	assert.Equal(t, "localhost", database["host"])
+	auth := dump["auth"].(map[string]interface{})
+	assert.Equal(t, redacted, auth["jwt_secret"])
+	assert.Equal(t, "", auth["cursor_secret"])
+	assert.Equal(t, "10/1m0s", dump["rate_limit"].(map[string]interface{})["auth"])
+	assert.Equal(t, "5s", dump["server"].(map[string]interface{})["shutdown_drain"])
+}
+func TestValidate_Debug(t *testing.T) {
+	cfg := Default()
+	cfg.Debug.Enabled = true
+	cfg.Debug.Addr = "6060"
+	assert.ErrorContains(t, cfg.Validate(), "debug.addr")
+	cfg.Debug.Addr = "127.0.0.1:6060"
+	assert.NoError(t, cfg.Validate())
+	cfg.Env = EnvProduction
+	cfg.Auth.JWTKeysDir = "/etc/desafio-api/keys"
+	cfg.Auth.CursorSecret = "0123456789abcdef0123456789abcdef"
+	cfg.Debug.Token = "changeme"
+	assert.ErrorContains(t, cfg.Validate(), "debug.token: default secret not allowed in production")
+	cfg.Debug.Token = ""
+	assert.NoError(t, cfg.Validate())
+}
+func TestValidate_TrustedProxies(t *testing.T) {
+	cfg := Default()
+	assert.Nil(t, cfg.Server.TrustedProxyList())
+	cfg.Server.TrustedProxies = "10.0.0.0/8, 192.168.1.10"
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, cfg.Server.TrustedProxyList())
+	assert.NoError(t, cfg.Validate())
+	cfg.Server.TrustedProxies = "10.0.0.0/8,proxy.internal"
+	assert.ErrorContains(t, cfg.Validate(), `server.trusted_proxies: "proxy.internal" is not an IP address or CIDR`)
+}
+func TestValidate_Metrics(t *testing.T) {
+	cfg := Default()
+	assert.False(t, cfg.Metrics.Enabled)
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.Addr = "9090"
+	assert.ErrorContains(t, cfg.Validate(), "metrics.addr")
+	cfg.Metrics.Addr = "127.0.0.1:9090"
+	assert.NoError(t, cfg.Validate())
+}
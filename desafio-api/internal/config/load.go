@@ -0,0 +1,226 @@
+package config
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"desafio-api/internal/adapters/database"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+const redacted = "[REDACTED]"
+// field is a leaf setting, addressed by its dotted key such as
+// "database.host".
+type field struct {
+	key    string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, ok := sf.Tag.Lookup("config")
+			if !ok {
+				continue
+			}
+			key := prefix + name
+			value := v.Field(i)
+			if value.Kind() == reflect.Struct && !value.Addr().Type().Implements(textUnmarshalerType) {
+				walk(key+".", value)
+				continue
+			}
+			out = append(out, field{key: key, env: sf.Tag.Get("env"), secret: sf.Tag.Get("secret") == "true", value: value})
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return out
+}
+func (f field) set(raw string) error {
+	if u, ok := f.value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	if f.value.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+		return nil
+	}
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+// flagValue collects a command line value so it can be applied after the
+// file and the environment.
+type flagValue struct {
+	raw   string
+	isSet bool
+	bool  bool
+}
+func (v *flagValue) String() string {
+	return v.raw
+}
+func (v *flagValue) Set(raw string) error {
+	v.raw, v.isSet = raw, true
+	return nil
+}
+func (v *flagValue) IsBoolFlag() bool {
+	return v.bool
+}
+// Load builds the configuration from the defaults, the file given by
+// -config or CONFIG_FILE (YAML or TOML, by extension), the environment and
+// the flags in args, then validates it. It returns the arguments left after
+// the flags, such as the migrate subcommand.
+func Load(args []string) (Config, []string, error) {
+	cfg := Default()
+	leaves := fields(&cfg)
+	fs := flag.NewFlagSet("desafio-api", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file (overrides $CONFIG_FILE)")
+	values := make(map[string]*flagValue, len(leaves))
+	for _, leaf := range leaves {
+		value := &flagValue{bool: leaf.value.Kind() == reflect.Bool}
+		values[leaf.key] = value
+		usage := "see config file key " + leaf.key
+		if leaf.env != "" {
+			usage = "overrides $" + leaf.env
+		}
+		fs.Var(value, leaf.key, usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, nil, err
+	}
+	if *configFile != "" {
+		if err := loadFile(*configFile, leaves); err != nil {
+			return cfg, nil, err
+		}
+	}
+	for _, leaf := range leaves {
+		raw, ok := os.LookupEnv(leaf.env)
+		// An empty variable only clears strings; for other types it is
+		// treated as unset.
+		if leaf.env == "" || !ok || (raw == "" && leaf.value.Kind() != reflect.String) {
+			continue
+		}
+		if err := leaf.set(raw); err != nil {
+			return cfg, nil, fmt.Errorf("%s: %w", leaf.env, err)
+		}
+	}
+	for _, leaf := range leaves {
+		if value := values[leaf.key]; value.isSet {
+			if err := leaf.set(value.raw); err != nil {
+				return cfg, nil, fmt.Errorf("-%s: %w", leaf.key, err)
+			}
+		}
+	}
+	if cfg.Database.Port == "" {
+		cfg.Database.Port = "3306"
+		if cfg.Database.Driver == database.DriverPostgres {
+			cfg.Database.Port = "5432"
+		}
+	}
+	return cfg, fs.Args(), cfg.Validate()
+}
+func loadFile(path string, leaves []field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	doc := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension (use .yaml, .yml or .toml)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	byKey := make(map[string]field, len(leaves))
+	for _, leaf := range leaves {
+		byKey[leaf.key] = leaf
+	}
+	var errs []error
+	var apply func(prefix string, values map[string]interface{})
+	apply = func(prefix string, values map[string]interface{}) {
+		for name, value := range values {
+			key := prefix + name
+			if nested, ok := value.(map[string]interface{}); ok {
+				apply(key+".", nested)
+				continue
+			}
+			leaf, ok := byKey[key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown key", key))
+				continue
+			}
+			if err := leaf.set(fmt.Sprint(value)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+	apply("", doc)
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+// Redacted returns the settings as nested maps keyed like the config file,
+// with secrets replaced, for diagnostics.
+func (c Config) Redacted() map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, leaf := range fields(&c) {
+		value := leaf.value.Interface()
+		if stringer, ok := value.(fmt.Stringer); ok {
+			value = stringer.String()
+		}
+		if leaf.secret && !leaf.value.IsZero() {
+			value = redacted
+		}
+		node := out
+		parts := strings.Split(leaf.key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = value
+	}
+	return out
+}
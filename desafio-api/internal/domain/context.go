@@ -0,0 +1,27 @@
+package domain
+import "context"
+type contextKey string
+const (
+	userIDContextKey    contextKey = "userID"
+	roleContextKey      contextKey = "role"
+	requestIDContextKey contextKey = "requestID"
+)
+func ContextWithUser(ctx context.Context, userID int, role string) context.Context {
+	ctx = context.WithValue(ctx, userIDContextKey, userID)
+	return context.WithValue(ctx, roleContextKey, role)
+}
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(int)
+	return userID, ok
+}
+func RoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(roleContextKey).(string)
+	return role, ok
+}
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
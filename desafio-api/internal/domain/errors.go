@@ -2,6 +2,7 @@ package domain
 import "errors"
 var (
     ErrItemNotFound      = errors.New("item not found")
+    ErrItemNotDeleted    = errors.New("item is not deleted")
     ErrCodeRequired      = errors.New("code is required")
     ErrTitleRequired     = errors.New("title is required")
     ErrDescriptionRequired = errors.New("description is required")
@@ -14,4 +15,28 @@ var (
     ErrDuplicateUsername = errors.New("user with this username already exists")
     ErrInvalidCredentials = errors.New("invalid username or password")
     ErrInvalidToken      = errors.New("invalid or expired token")
+    ErrInvalidRole       = errors.New("role must be one of admin, editor or viewer")
+    ErrCannotChangeOwnRole = errors.New("users cannot change their own role")
+    ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+    ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+    ErrVersionConflict     = errors.New("item was modified by another request")
+    ErrInvalidQuantity     = errors.New("quantity must be greater than zero")
+    ErrReasonRequired      = errors.New("reason is required")
+    ErrInsufficientStock   = errors.New("insufficient available stock")
+    ErrInvalidReservationTTL = errors.New("reservation ttl is out of range")
+    ErrReservationNotFound   = errors.New("reservation not found")
+    ErrReservationNotPending = errors.New("reservation is no longer pending")
+    ErrReservationExpired    = errors.New("reservation has expired")
+    ErrItemHasReservations   = errors.New("item has pending stock reservations")
+    ErrDuplicateImportCode   = errors.New("code appears more than once in the import")
+    ErrJobNotFound           = errors.New("job not found")
+    ErrJobNotCancelable      = errors.New("job has already finished")
+    ErrJobNotRetryable       = errors.New("only failed or canceled jobs can be retried")
+    ErrJobCanceled           = errors.New("job was canceled")
+    ErrJobLockLost           = errors.New("job is no longer held by this worker")
+    ErrUnknownJobType        = errors.New("unknown job type")
+    ErrWebhookNotFound       = errors.New("webhook subscription not found")
+    ErrInvalidWebhookURL     = errors.New("webhook url must be an absolute http or https url")
+    ErrInvalidWebhookEvents  = errors.New("webhook event types must be known item events")
+    ErrDeliveryNotFound      = errors.New("webhook delivery not found")
 )
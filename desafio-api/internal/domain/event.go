@@ -0,0 +1,57 @@
+package domain
+import (
+    "encoding/json"
+    "time"
+)
+const (
+    EventItemCreated      = "ItemCreated"
+    EventItemUpdated      = "ItemUpdated"
+    EventItemStockChanged = "ItemStockChanged"
+    EventItemDeleted      = "ItemDeleted"
+    EventItemRestored     = "ItemRestored"
+)
+const AggregateItem = "item"
+// OutboxEvent is a domain event waiting in the outbox to be published.
+// Payload is the JSON document delivered to subscribers.
+type OutboxEvent struct {
+    ID            int64      `json:"id" db:"id"`
+    AggregateType string     `json:"aggregate_type" db:"aggregate_type"`
+    AggregateID   int64      `json:"aggregate_id" db:"aggregate_id"`
+    Type          string     `json:"type" db:"event_type"`
+    Payload       []byte     `json:"-" db:"payload"`
+    OccurredAt    time.Time  `json:"occurred_at" db:"occurred_at"`
+    PublishedAt   *time.Time `json:"published_at,omitempty" db:"published_at"`
+    Attempts      int        `json:"attempts" db:"attempts"`
+    LastError     string     `json:"last_error,omitempty" db:"last_error"`
+    NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+}
+// ItemEvent is the payload of every item event. Item is the state after the
+// change and is omitted for deletions.
+type ItemEvent struct {
+    ItemID         int64  `json:"item_id"`
+    Item           *Item  `json:"item,omitempty"`
+    PreviousStatus string `json:"previous_status,omitempty"`
+    StockDelta     int    `json:"stock_delta,omitempty"`
+    ReservedDelta  int    `json:"reserved_delta,omitempty"`
+    ActorID        int    `json:"actor_id,omitempty"`
+}
+// EventEnvelope is the document published for every outbox event; Data is
+// the event payload as written by the service.
+type EventEnvelope struct {
+    ID            int64           `json:"id"`
+    Type          string          `json:"type"`
+    AggregateType string          `json:"aggregate_type"`
+    AggregateID   int64           `json:"aggregate_id"`
+    OccurredAt    time.Time       `json:"occurred_at"`
+    Data          json.RawMessage `json:"data"`
+}
+func (e *OutboxEvent) Envelope() EventEnvelope {
+    return EventEnvelope{
+        ID:            e.ID,
+        Type:          e.Type,
+        AggregateType: e.AggregateType,
+        AggregateID:   e.AggregateID,
+        OccurredAt:    e.OccurredAt.UTC(),
+        Data:          json.RawMessage(e.Payload),
+    }
+}
@@ -0,0 +1,23 @@
+package domain
+import "time"
+// IdempotencyRecord is the response stored for an Idempotency-Key. While the
+// first request is still running StatusCode is zero.
+type IdempotencyRecord struct {
+    ID          int64
+    UserID      int
+    Key         string
+    Fingerprint string
+    StatusCode  int
+    Headers     map[string]string
+    Body        []byte
+    CreatedAt   time.Time
+    ExpiresAt   time.Time
+}
+func (r *IdempotencyRecord) Completed() bool {
+    return r.StatusCode != 0
+}
+// Replaceable reports whether a new request may take the key: the record
+// expired, or it is still in progress since before staleBefore.
+func (r *IdempotencyRecord) Replaceable(now, staleBefore time.Time) bool {
+    return !r.ExpiresAt.After(now) || (!r.Completed() && r.CreatedAt.Before(staleBefore))
+}
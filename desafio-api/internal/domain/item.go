@@ -1,17 +1,34 @@
 package domain
 import "time"
 type Item struct {
-    ID          int64     `json:"id" db:"id"`
-    Code        string    `json:"code" db:"code"`
-    Title       string    `json:"title" db:"title"`
-    Description string    `json:"description" db:"description"`
-    Price       int64     `json:"price" db:"price"`
-    Stock       int       `json:"stock" db:"stock"`
-    Status      string    `json:"status" db:"status"`
-    CreatedAt   time.Time `json:"created_at" db:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-    CreatedBy   int       `json:"created_by" db:"created_by"`
-    UpdatedBy   int       `json:"updated_by" db:"updated_by"`
+    ID          int64      `json:"id" db:"id"`
+    Code        string     `json:"code" db:"code"`
+    Title       string     `json:"title" db:"title"`
+    Description string     `json:"description" db:"description"`
+    Price       int64      `json:"price" db:"price"`
+    Stock       int        `json:"stock" db:"stock"`
+    Reserved    int        `json:"reserved" db:"reserved"`
+    Status      string     `json:"status" db:"status"`
+    CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+    CreatedBy   int        `json:"created_by" db:"created_by"`
+    UpdatedBy   int        `json:"updated_by" db:"updated_by"`
+    Version     int64      `json:"version" db:"version"`
+    DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+    DeletedBy   int        `json:"deleted_by,omitempty" db:"deleted_by"`
+}
+// ItemStatusStats aggregates the items that are not deleted with a given
+// status.
+type ItemStatusStats struct {
+    Status string `json:"status" db:"status"`
+    Items  int    `json:"items" db:"items"`
+    Stock  int64  `json:"stock" db:"stock"`
+}
+func (i *Item) IsDeleted() bool {
+    return i.DeletedAt != nil
+}
+func (i *Item) Available() int {
+    return i.Stock - i.Reserved
 }
 func (i *Item) Validate() error {
     if i.Code == "" {
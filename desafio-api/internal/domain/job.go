@@ -0,0 +1,50 @@
+package domain
+import "time"
+const (
+    JobQueued    = "QUEUED"
+    JobRunning   = "RUNNING"
+    JobSucceeded = "SUCCEEDED"
+    JobFailed    = "FAILED"
+    JobCanceled  = "CANCELED"
+)
+// Job is a unit of background work. Payload and Result are opaque JSON
+// documents owned by the runner registered for Type.
+type Job struct {
+    ID              int64      `json:"id" db:"id"`
+    Type            string     `json:"type" db:"type"`
+    Status          string     `json:"status" db:"status"`
+    Payload         []byte     `json:"-" db:"payload"`
+    Result          []byte     `json:"-" db:"result"`
+    LastError       string     `json:"last_error,omitempty" db:"last_error"`
+    Processed       int        `json:"processed" db:"processed"`
+    Total           int        `json:"total" db:"total"`
+    Attempts        int        `json:"attempts" db:"attempts"`
+    MaxAttempts     int        `json:"max_attempts" db:"max_attempts"`
+    CancelRequested bool       `json:"cancel_requested" db:"cancel_requested"`
+    RunAt           time.Time  `json:"run_at" db:"run_at"`
+    LockedBy        string     `json:"-" db:"locked_by"`
+    LockedUntil     *time.Time `json:"-" db:"locked_until"`
+    CreatedBy       int        `json:"created_by" db:"created_by"`
+    CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+    StartedAt       *time.Time `json:"started_at,omitempty" db:"started_at"`
+    FinishedAt      *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+    UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+func (j *Job) IsFinished() bool {
+    return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCanceled
+}
+// Percent is the share of Total already processed; it is 0 until the runner
+// knows the total.
+func (j *Job) Percent() int {
+    if j.Status == JobSucceeded {
+        return 100
+    }
+    if j.Total <= 0 {
+        return 0
+    }
+    percent := j.Processed * 100 / j.Total
+    if percent > 100 {
+        percent = 100
+    }
+    return percent
+}
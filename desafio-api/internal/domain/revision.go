@@ -0,0 +1,72 @@
+package domain
+import (
+    "encoding/json"
+    "time"
+)
+const (
+    RevisionCreate  = "CREATE"
+    RevisionUpdate  = "UPDATE"
+    RevisionDelete  = "DELETE"
+    RevisionRestore = "RESTORE"
+)
+// ItemRevision records one change of an item. Snapshot is the item as JSON
+// after the change (before it, for deletions) and Changes maps each changed
+// field to its FieldChange.
+type ItemRevision struct {
+    ID        int64     `json:"id" db:"id"`
+    ItemID    int64     `json:"item_id" db:"item_id"`
+    Action    string    `json:"action" db:"action"`
+    Snapshot  []byte    `json:"-" db:"snapshot"`
+    Changes   []byte    `json:"-" db:"changes"`
+    ActorID   int       `json:"actor_id" db:"actor_id"`
+    RequestID string    `json:"request_id,omitempty" db:"request_id"`
+    CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+type FieldChange struct {
+    From interface{} `json:"from"`
+    To   interface{} `json:"to"`
+}
+// NewItemRevision builds the revision for a change from before to after;
+// before is nil for a creation and after is nil for a deletion.
+func NewItemRevision(action string, before, after *Item) (*ItemRevision, error) {
+    current := after
+    if current == nil {
+        current = before
+    }
+    snapshot, err := json.Marshal(current)
+    if err != nil {
+        return nil, err
+    }
+    changes, err := json.Marshal(DiffItems(before, after))
+    if err != nil {
+        return nil, err
+    }
+    return &ItemRevision{ItemID: current.ID, Action: action, Snapshot: snapshot, Changes: changes}, nil
+}
+// DiffItems compares the fields users can change, plus the ones derived from
+// them and whether the item is deleted. A nil side means the item did not
+// exist.
+func DiffItems(before, after *Item) map[string]FieldChange {
+    changes := make(map[string]FieldChange)
+    fields := func(item *Item) map[string]interface{} {
+        if item == nil {
+            return map[string]interface{}{}
+        }
+        return map[string]interface{}{
+            "code":        item.Code,
+            "title":       item.Title,
+            "description": item.Description,
+            "price":       item.Price,
+            "stock":       item.Stock,
+            "status":      item.Status,
+            "deleted":     item.IsDeleted(),
+        }
+    }
+    from, to := fields(before), fields(after)
+    for _, name := range []string{"code", "title", "description", "price", "stock", "status", "deleted"} {
+        if from[name] != to[name] {
+            changes[name] = FieldChange{From: from[name], To: to[name]}
+        }
+    }
+    return changes
+}
@@ -0,0 +1,30 @@
+package domain
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+type Permission string
+const (
+	PermissionItemsRead   Permission = "items:read"
+	PermissionItemsWrite  Permission = "items:write"
+	PermissionItemsDelete Permission = "items:delete"
+	PermissionUsersManage Permission = "users:manage"
+)
+var rolePermissions = map[string][]Permission{
+	RoleAdmin:  {PermissionItemsRead, PermissionItemsWrite, PermissionItemsDelete, PermissionUsersManage},
+	RoleEditor: {PermissionItemsRead, PermissionItemsWrite},
+	RoleViewer: {PermissionItemsRead},
+}
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+func HasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package domain
+import "time"
+const (
+    StockMovementIncrement  = "INCREMENT"
+    StockMovementDecrement  = "DECREMENT"
+    StockMovementAdjustment = "ADJUSTMENT"
+    StockMovementReserve    = "RESERVE"
+    StockMovementRelease    = "RELEASE"
+    StockMovementCommit     = "COMMIT"
+)
+const (
+    ReservationPending   = "PENDING"
+    ReservationCommitted = "COMMITTED"
+    ReservationReleased  = "RELEASED"
+    ReservationExpired   = "EXPIRED"
+)
+// StockMovement is an append-only ledger entry. StockDelta changes the
+// on-hand quantity and ReservedDelta the quantity held by reservations.
+type StockMovement struct {
+    ID            int64     `json:"id" db:"id"`
+    ItemID        int64     `json:"item_id" db:"item_id"`
+    Type          string    `json:"type" db:"type"`
+    StockDelta    int       `json:"stock_delta" db:"stock_delta"`
+    ReservedDelta int       `json:"reserved_delta" db:"reserved_delta"`
+    Reason        string    `json:"reason" db:"reason"`
+    ReservationID *int64    `json:"reservation_id,omitempty" db:"reservation_id"`
+    CreatedBy     int       `json:"created_by" db:"created_by"`
+    CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+type StockReservation struct {
+    ID        int64     `json:"id" db:"id"`
+    ItemID    int64     `json:"item_id" db:"item_id"`
+    Quantity  int       `json:"quantity" db:"quantity"`
+    Status    string    `json:"status" db:"status"`
+    Reason    string    `json:"reason" db:"reason"`
+    ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+    CreatedBy int       `json:"created_by" db:"created_by"`
+    CreatedAt time.Time `json:"created_at" db:"created_at"`
+    UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+func (r *StockReservation) IsExpired(now time.Time) bool {
+    return r.Status == ReservationPending && !now.Before(r.ExpiresAt)
+}
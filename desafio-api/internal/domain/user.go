@@ -7,6 +7,7 @@ type User struct {
 	ID        int       `json:"id" db:"id"`
 	Username  string    `json:"username" db:"username"`
 	Password  string    `json:"-" db:"password"` 
+	Role      string    `json:"role" db:"role"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -29,5 +30,8 @@ func (u *User) Validate() error {
 	if u.ID == 0 && len(u.Password) < 6 {
 		return ErrPasswordTooShort
 	}
+	if u.Role != "" && !IsValidRole(u.Role) {
+		return ErrInvalidRole
+	}
 	return nil
 }
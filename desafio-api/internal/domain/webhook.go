@@ -0,0 +1,74 @@
+package domain
+import (
+    "net/url"
+    "time"
+)
+const (
+    DeliveryPending   = "PENDING"
+    DeliverySucceeded = "SUCCEEDED"
+    DeliveryDead      = "DEAD"
+)
+// ItemEventTypes lists the events a webhook can subscribe to.
+var ItemEventTypes = []string{EventItemCreated, EventItemUpdated, EventItemStockChanged, EventItemDeleted, EventItemRestored}
+type WebhookSubscription struct {
+    ID         int64     `json:"id" db:"id"`
+    URL        string    `json:"url" db:"url"`
+    Secret     string    `json:"-" db:"secret"`
+    EventTypes []string  `json:"event_types" db:"-"`
+    Active     bool      `json:"active" db:"active"`
+    CreatedBy  int       `json:"created_by" db:"created_by"`
+    CreatedAt  time.Time `json:"created_at" db:"created_at"`
+    UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+func (w *WebhookSubscription) Validate() error {
+    parsed, err := url.Parse(w.URL)
+    if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+        return ErrInvalidWebhookURL
+    }
+    if len(w.EventTypes) == 0 {
+        return ErrInvalidWebhookEvents
+    }
+    for _, eventType := range w.EventTypes {
+        if !w.knownEvent(eventType) {
+            return ErrInvalidWebhookEvents
+        }
+    }
+    return nil
+}
+func (w *WebhookSubscription) knownEvent(eventType string) bool {
+    for _, known := range ItemEventTypes {
+        if eventType == known {
+            return true
+        }
+    }
+    return false
+}
+func (w *WebhookSubscription) Wants(eventType string) bool {
+    if !w.Active {
+        return false
+    }
+    for _, subscribed := range w.EventTypes {
+        if subscribed == eventType {
+            return true
+        }
+    }
+    return false
+}
+// WebhookDelivery tracks one event sent to one subscription. Payload is the
+// exact body POSTed on every attempt.
+type WebhookDelivery struct {
+    ID             int64      `json:"id" db:"id"`
+    SubscriptionID int64      `json:"subscription_id" db:"subscription_id"`
+    EventID        int64      `json:"event_id" db:"event_id"`
+    EventType      string     `json:"event_type" db:"event_type"`
+    Payload        []byte     `json:"-" db:"payload"`
+    Status         string     `json:"status" db:"status"`
+    Attempts       int        `json:"attempts" db:"attempts"`
+    NextAttemptAt  time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+    LastStatusCode int        `json:"last_status_code,omitempty" db:"last_status_code"`
+    LastError      string     `json:"last_error,omitempty" db:"last_error"`
+    LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+    DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+    CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+    UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package repository
+import (
+    "context"
+    "time"
+    "desafio-api/internal/domain"
+)
+type IdempotencyRepository interface {
+    // Reserve stores record as in progress and returns true. When an
+    // unexpired record already exists for the same user and key it returns
+    // that record and false instead, unless it is still in progress and was
+    // created before staleBefore: that request is taken as abandoned and
+    // record replaces it.
+    Reserve(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) (*domain.IdempotencyRecord, bool, error)
+    // Complete saves the response of a reserved record. Complete and Release
+    // do nothing once the reservation was taken over.
+    Complete(ctx context.Context, record *domain.IdempotencyRecord) error
+    // Release drops an in-progress reservation so the key can be retried.
+    Release(ctx context.Context, record *domain.IdempotencyRecord) error
+    DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
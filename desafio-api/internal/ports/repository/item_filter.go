@@ -0,0 +1,81 @@
+package repository
+import (
+    "fmt"
+    "strings"
+    "time"
+    "unicode"
+    "desafio-api/internal/domain"
+)
+var ItemSortFields = map[string]bool{
+    "id":         true,
+    "code":       true,
+    "title":      true,
+    "price":      true,
+    "stock":      true,
+    "created_at": true,
+    "updated_at": true,
+}
+type SortField struct {
+    Field string
+    Desc  bool
+}
+// ItemFilter is the listing criteria every ItemRepository must honour.
+// Nil bounds are ignored; "From" bounds are inclusive and "To" bounds
+// exclusive. Without Sort, results are ordered by relevance when Query is
+// set and by most recently updated otherwise, always breaking ties by id.
+// Soft-deleted items are left out unless IncludeDeleted is set.
+type ItemFilter struct {
+    Status         string
+    Query          string
+    CodePrefix     string
+    MinPrice       *int64
+    MaxPrice       *int64
+    MinStock       *int
+    MaxStock       *int
+    CreatedBy      *int
+    CreatedFrom    *time.Time
+    CreatedTo      *time.Time
+    UpdatedFrom    *time.Time
+    UpdatedTo      *time.Time
+    Sort           []SortField
+    IncludeDeleted bool
+}
+// ItemKey is an item's position in the keyset ordering used by FindAfter:
+// updated_at descending, then id descending.
+type ItemKey struct {
+    UpdatedAt time.Time
+    ID        int64
+}
+func KeyOf(item *domain.Item) ItemKey {
+    return ItemKey{UpdatedAt: item.UpdatedAt, ID: item.ID}
+}
+var KeysetSort = []SortField{{Field: "updated_at", Desc: true}, {Field: "id", Desc: true}}
+// ParseSort reads a comma separated list such as "-price,title" where a
+// leading "-" means descending order.
+func ParseSort(value string) ([]SortField, error) {
+    var fields []SortField
+    seen := make(map[string]bool)
+    for _, part := range strings.Split(value, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+        if !ItemSortFields[field.Field] {
+            return nil, fmt.Errorf("unknown sort field %q", field.Field)
+        }
+        if seen[field.Field] {
+            return nil, fmt.Errorf("duplicate sort field %q", field.Field)
+        }
+        seen[field.Field] = true
+        fields = append(fields, field)
+    }
+    return fields, nil
+}
+// SearchTerms splits a free-text query into the words adapters match as
+// prefixes; punctuation is dropped so it cannot reach the search syntax.
+func SearchTerms(query string) []string {
+    return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+}
@@ -1,13 +1,33 @@
 package repository
 import (
     "context"
+    "time"
     "desafio-api/internal/domain"
 )
 type ItemRepository interface {
     Save(ctx context.Context, item *domain.Item) error
+    // Update, FindByID and AdjustStock ignore soft-deleted items. FindByCode
+    // and ExistsByCode do not, since a deleted item keeps its code.
     Update(ctx context.Context, item *domain.Item) error
     FindByID(ctx context.Context, id int64) (*domain.Item, error)
-    FindAll(ctx context.Context, status string, limit, offset int) ([]*domain.Item, int, error)
-    Delete(ctx context.Context, id int64) error
+    FindByIDIncludingDeleted(ctx context.Context, id int64) (*domain.Item, error)
+    FindByCode(ctx context.Context, code string) (*domain.Item, error)
+    FindAll(ctx context.Context, filter ItemFilter, limit, offset int) ([]*domain.Item, int, error)
+    // FindAfter returns up to limit items strictly after the given key in
+    // keyset order (from the start when after is nil). Filter.Sort is ignored.
+    FindAfter(ctx context.Context, filter ItemFilter, after *ItemKey, limit int) ([]*domain.Item, error)
+    // Iterate streams every item matching filter, in listing order, to fn
+    // and stops at the first error fn returns.
+    Iterate(ctx context.Context, filter ItemFilter, fn func(*domain.Item) error) error
+    // Delete marks the item as deleted, unless it has reserved stock
+    // (ErrItemHasReservations); Restore undoes it and Purge removes for good
+    // the items deleted before the given time.
+    Delete(ctx context.Context, id int64, expectedVersion int64, deletedBy int) error
+    Restore(ctx context.Context, id int64, restoredBy int) (*domain.Item, error)
+    Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+    // Soft-deleted items keep their code reserved until purged so they can
+    // always be restored.
     ExistsByCode(ctx context.Context, code string, excludeID int64) (bool, error)
+    AdjustStock(ctx context.Context, id int64, stockDelta, reservedDelta int, updatedBy int) (*domain.Item, error)
+    StatsByStatus(ctx context.Context) ([]domain.ItemStatusStats, error)
 }
@@ -0,0 +1,11 @@
+package repository
+import (
+    "context"
+    "desafio-api/internal/domain"
+)
+type ItemRevisionRepository interface {
+    Save(ctx context.Context, revision *domain.ItemRevision) error
+    // FindByItem returns the revisions of an item, newest first, and the
+    // total count.
+    FindByItem(ctx context.Context, itemID int64, limit, offset int) ([]*domain.ItemRevision, int, error)
+}
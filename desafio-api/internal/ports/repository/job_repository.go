@@ -0,0 +1,30 @@
+package repository
+import (
+    "context"
+    "time"
+    "desafio-api/internal/domain"
+)
+type JobRepository interface {
+    Save(ctx context.Context, job *domain.Job) error
+    FindByID(ctx context.Context, id int64) (*domain.Job, error)
+    // Claim marks the oldest due QUEUED job as RUNNING, held by workerID
+    // until lockedUntil. It returns nil when nothing is due.
+    Claim(ctx context.Context, workerID string, now, lockedUntil time.Time) (*domain.Job, error)
+    // Heartbeat stores progress and extends the lock, reporting whether a
+    // cancellation was requested. It fails with ErrJobLockLost when the job
+    // is no longer held by workerID.
+    Heartbeat(ctx context.Context, id int64, workerID string, processed, total int, lockedUntil time.Time) (bool, error)
+    // Finish releases the lock and stores the job's status, attempts,
+    // progress, result, error, run_at and finished_at.
+    Finish(ctx context.Context, job *domain.Job, workerID string) error
+    // RequestCancel cancels a queued job at once and flags a running one so
+    // its worker stops.
+    RequestCancel(ctx context.Context, id int64, now time.Time) error
+    // Requeue puts a failed or canceled job back in the queue with its
+    // attempts reset.
+    Requeue(ctx context.Context, id int64, now time.Time) error
+    // RequeueStale returns RUNNING jobs whose lock expired (their worker
+    // died) to the queue, or fails them with lastError when they have no
+    // attempts left.
+    RequeueStale(ctx context.Context, now time.Time, lastError string) (requeued, failed int, err error)
+}
@@ -0,0 +1,20 @@
+package repository
+import (
+    "context"
+    "time"
+    "desafio-api/internal/domain"
+)
+type OutboxRepository interface {
+    Save(ctx context.Context, event *domain.OutboxEvent) error
+    // Claim returns unpublished events due at now, oldest first, leaving out
+    // events queued behind an unpublished one of the same aggregate. The
+    // claimed events are not due again before leaseUntil, so other relays
+    // skip them while they are being published.
+    Claim(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.OutboxEvent, error)
+    MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error
+    MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error
+    CountPending(ctx context.Context) (int, error)
+    // OldestPending returns when the oldest unpublished event occurred, or
+    // nil when every event was published.
+    OldestPending(ctx context.Context) (*time.Time, error)
+}
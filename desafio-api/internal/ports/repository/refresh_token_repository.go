@@ -0,0 +1,13 @@
+package repository
+import (
+    "context"
+    "time"
+    "desafio-api/internal/domain"
+)
+type RefreshTokenRepository interface {
+    Save(ctx context.Context, token *domain.RefreshToken) error
+    FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+    MarkUsed(ctx context.Context, id int64, usedAt time.Time) (bool, error)
+    RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+    DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,9 @@
+package repository
+import (
+    "context"
+    "desafio-api/internal/domain"
+)
+type StockMovementRepository interface {
+    Save(ctx context.Context, movement *domain.StockMovement) error
+    FindByItem(ctx context.Context, itemID int64, limit, offset int) ([]*domain.StockMovement, int, error)
+}
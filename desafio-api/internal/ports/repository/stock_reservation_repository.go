@@ -0,0 +1,12 @@
+package repository
+import (
+    "context"
+    "time"
+    "desafio-api/internal/domain"
+)
+type StockReservationRepository interface {
+    Save(ctx context.Context, reservation *domain.StockReservation) error
+    FindByID(ctx context.Context, id int64) (*domain.StockReservation, error)
+    UpdateStatus(ctx context.Context, id int64, from, to string) (bool, error)
+    FindExpired(ctx context.Context, now time.Time, limit int) ([]*domain.StockReservation, error)
+}
@@ -0,0 +1,5 @@
+package repository
+import "context"
+type TxManager interface {
+    WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
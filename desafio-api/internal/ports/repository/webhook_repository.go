@@ -0,0 +1,23 @@
+package repository
+import (
+    "context"
+    "time"
+    "desafio-api/internal/domain"
+)
+type WebhookRepository interface {
+    SaveSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error
+    UpdateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error
+    DeleteSubscription(ctx context.Context, id int64) error
+    FindSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+    ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+    // SaveDelivery ignores a delivery whose subscription already has one for
+    // the same event, so republishing an event never duplicates it.
+    SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+    UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+    FindDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error)
+    ListDeliveries(ctx context.Context, subscriptionID int64, status string, limit, offset int) ([]*domain.WebhookDelivery, int, error)
+    // ClaimDue returns pending deliveries due at now and pushes their next
+    // attempt to leaseUntil, so other workers leave them alone while they
+    // are being sent.
+    ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.WebhookDelivery, error)
+}
@@ -0,0 +1,4 @@
+package migrations
+import "embed"
+//go:embed mysql/*.sql postgres/*.sql
+var FS embed.FS
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS before_item_update;
+DROP TABLE IF EXISTS items;
@@ -12,8 +12,10 @@ CREATE TABLE IF NOT EXISTS items (
     INDEX idx_code (code)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+DROP TRIGGER IF EXISTS before_item_update;
+
 -- Trigger para atualizar o status baseado no estoque
-DELIMITER //
+-- +migrate StatementBegin
 CREATE TRIGGER before_item_update
 BEFORE UPDATE ON items
 FOR EACH ROW
@@ -23,5 +25,5 @@ BEGIN
     ELSE
         SET NEW.status = 'INACTIVE';
     END IF;
-END //
-DELIMITER ;
+END;
+-- +migrate StatementEnd
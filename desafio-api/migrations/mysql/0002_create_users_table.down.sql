@@ -0,0 +1,9 @@
+ALTER TABLE items
+DROP FOREIGN KEY fk_items_created_by,
+DROP FOREIGN KEY fk_items_updated_by;
+
+ALTER TABLE items
+DROP COLUMN created_by,
+DROP COLUMN updated_by;
+
+DROP TABLE IF EXISTS users;
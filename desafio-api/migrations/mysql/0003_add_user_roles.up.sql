@@ -0,0 +1,5 @@
+ALTER TABLE users
+ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'viewer' AFTER password;
+
+-- Usuários existentes já podiam criar e alterar itens
+UPDATE users SET role = 'editor';
@@ -0,0 +1,36 @@
+ALTER TABLE items
+ADD COLUMN reserved INT NOT NULL DEFAULT 0 AFTER stock;
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    item_id BIGINT NOT NULL,
+    quantity INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    reason VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_by INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_stock_reservations_pending (status, expires_at),
+    CONSTRAINT fk_stock_reservations_item FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Livro-razão de movimentações: apenas inserções
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    item_id BIGINT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    stock_delta INT NOT NULL,
+    reserved_delta INT NOT NULL DEFAULT 0,
+    reason VARCHAR(255) NOT NULL,
+    reservation_id BIGINT NULL,
+    created_by INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_stock_movements_item (item_id, id),
+    CONSTRAINT fk_stock_movements_item FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_movements_reservation FOREIGN KEY (reservation_id) REFERENCES stock_reservations(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Estoque já existente entra no livro-razão como movimentação inicial
+INSERT INTO stock_movements (item_id, type, stock_delta, reserved_delta, reason, created_by)
+SELECT id, 'ADJUSTMENT', stock, 0, 'saldo inicial', updated_by FROM items WHERE stock <> 0;
@@ -0,0 +1,5 @@
+DROP INDEX idx_items_updated_at ON items;
+
+DROP INDEX idx_items_price ON items;
+
+ALTER TABLE items DROP INDEX ft_items_search;
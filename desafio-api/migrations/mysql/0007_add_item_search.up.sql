@@ -0,0 +1,6 @@
+ALTER TABLE items
+ADD FULLTEXT INDEX ft_items_search (title, description);
+
+CREATE INDEX idx_items_price ON items(price);
+
+CREATE INDEX idx_items_updated_at ON items(updated_at, id);
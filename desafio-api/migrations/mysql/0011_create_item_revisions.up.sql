@@ -0,0 +1,12 @@
+-- Histórico de alterações: sem chave estrangeira para sobreviver à exclusão do item
+CREATE TABLE IF NOT EXISTS item_revisions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    item_id BIGINT NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    snapshot TEXT NOT NULL,
+    changes TEXT NOT NULL,
+    actor_id INT NULL,
+    request_id VARCHAR(100) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_item_revisions_item (item_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
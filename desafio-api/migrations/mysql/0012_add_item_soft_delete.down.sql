@@ -0,0 +1,6 @@
+DELETE FROM items WHERE deleted_at IS NOT NULL;
+ALTER TABLE items
+DROP FOREIGN KEY fk_items_deleted_by,
+DROP INDEX idx_items_deleted_at,
+DROP COLUMN deleted_by,
+DROP COLUMN deleted_at;
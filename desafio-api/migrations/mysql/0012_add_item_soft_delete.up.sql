@@ -0,0 +1,5 @@
+ALTER TABLE items
+ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL AFTER version,
+ADD COLUMN deleted_by INT NULL AFTER deleted_at,
+ADD CONSTRAINT fk_items_deleted_by FOREIGN KEY (deleted_by) REFERENCES users(id),
+ADD INDEX idx_items_deleted_at (deleted_at);
@@ -0,0 +1,5 @@
+DELETE FROM stock_movements WHERE item_id NOT IN (SELECT id FROM items);
+ALTER TABLE stock_movements DROP FOREIGN KEY fk_stock_movements_reservation;
+ALTER TABLE stock_movements
+ADD CONSTRAINT fk_stock_movements_item FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+ADD CONSTRAINT fk_stock_movements_reservation FOREIGN KEY (reservation_id) REFERENCES stock_reservations(id) ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+-- O livro-razão é mantido quando um item excluído é removido definitivamente
+ALTER TABLE stock_movements
+DROP FOREIGN KEY fk_stock_movements_item,
+DROP FOREIGN KEY fk_stock_movements_reservation;
+ALTER TABLE stock_movements
+ADD CONSTRAINT fk_stock_movements_reservation FOREIGN KEY (reservation_id) REFERENCES stock_reservations(id) ON DELETE SET NULL;
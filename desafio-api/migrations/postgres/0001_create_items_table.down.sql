@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS update_items_status_trigger ON items;
+DROP FUNCTION IF EXISTS update_item_status();
+DROP TRIGGER IF EXISTS update_items_updated_at ON items;
+DROP FUNCTION IF EXISTS update_updated_at_column();
+DROP INDEX IF EXISTS idx_items_code;
+DROP INDEX IF EXISTS idx_items_status;
+DROP TABLE IF EXISTS items;
@@ -19,6 +19,7 @@ CREATE INDEX IF NOT EXISTS idx_items_status ON items(status);
 CREATE INDEX IF NOT EXISTS idx_items_code ON items(code);
 
 
+-- +migrate StatementBegin
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -26,12 +27,14 @@ BEGIN
     RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
 
 CREATE TRIGGER update_items_updated_at
 BEFORE UPDATE ON items
 FOR EACH ROW
 EXECUTE FUNCTION update_updated_at_column();
 
+-- +migrate StatementBegin
 CREATE OR REPLACE FUNCTION update_item_status()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -43,17 +46,9 @@ BEGIN
     RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
 
 CREATE TRIGGER update_items_status_trigger
 BEFORE INSERT OR UPDATE OF stock ON items
 FOR EACH ROW
 EXECUTE FUNCTION update_item_status();
-
-
-DROP TRIGGER IF EXISTS update_items_status_trigger ON items;
-DROP FUNCTION IF EXISTS update_item_status();
-DROP TRIGGER IF EXISTS update_items_updated_at ON items;
-DROP FUNCTION IF EXISTS update_updated_at_column();
-DROP INDEX IF EXISTS idx_items_code;
-DROP INDEX IF EXISTS idx_items_status;
-DROP TABLE IF EXISTS items;
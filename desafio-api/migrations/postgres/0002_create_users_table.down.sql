@@ -0,0 +1,4 @@
+ALTER TABLE items DROP COLUMN IF EXISTS updated_by;
+ALTER TABLE items DROP COLUMN IF EXISTS created_by;
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+DROP TABLE IF EXISTS users;
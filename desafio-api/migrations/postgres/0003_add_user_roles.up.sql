@@ -0,0 +1,6 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'viewer',
+ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'editor', 'viewer'));
+
+-- Usuários existentes já podiam criar e alterar itens
+UPDATE users SET role = 'editor';
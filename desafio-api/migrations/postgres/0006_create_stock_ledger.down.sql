@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS stock_movements;
+
+DROP TABLE IF EXISTS stock_reservations;
+
+ALTER TABLE items DROP COLUMN IF EXISTS reserved;
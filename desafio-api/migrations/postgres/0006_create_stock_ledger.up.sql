@@ -0,0 +1,41 @@
+ALTER TABLE items
+ADD COLUMN IF NOT EXISTS reserved INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id BIGSERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    reason VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by INTEGER NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (status IN ('PENDING', 'COMMITTED', 'RELEASED', 'EXPIRED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_pending ON stock_reservations(status, expires_at);
+
+CREATE TRIGGER update_stock_reservations_updated_at
+BEFORE UPDATE ON stock_reservations
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Livro-razão de movimentações: apenas inserções
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    stock_delta INTEGER NOT NULL,
+    reserved_delta INTEGER NOT NULL DEFAULT 0,
+    reason VARCHAR(255) NOT NULL,
+    reservation_id BIGINT NULL REFERENCES stock_reservations(id) ON DELETE CASCADE,
+    created_by INTEGER NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_item ON stock_movements(item_id, id);
+
+-- Estoque já existente entra no livro-razão como movimentação inicial
+INSERT INTO stock_movements (item_id, type, stock_delta, reserved_delta, reason, created_by)
+SELECT id, 'ADJUSTMENT', stock, 0, 'saldo inicial', updated_by FROM items WHERE stock <> 0;
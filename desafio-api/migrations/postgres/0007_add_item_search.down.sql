@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_items_updated_at;
+
+DROP INDEX IF EXISTS idx_items_price;
+
+DROP INDEX IF EXISTS idx_items_search;
+
+ALTER TABLE items DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,9 @@
+ALTER TABLE items
+ADD COLUMN IF NOT EXISTS search_vector tsvector
+GENERATED ALWAYS AS (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(description, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_items_search ON items USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_items_price ON items(price);
+
+CREATE INDEX IF NOT EXISTS idx_items_updated_at ON items(updated_at, id);
@@ -0,0 +1,14 @@
+-- Histórico de alterações: sem chave estrangeira para sobreviver à exclusão do item
+CREATE TABLE IF NOT EXISTS item_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    snapshot TEXT NOT NULL,
+    changes TEXT NOT NULL,
+    actor_id INTEGER NULL,
+    request_id VARCHAR(100) NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (action IN ('CREATE', 'UPDATE', 'DELETE'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_revisions_item ON item_revisions(item_id, id);
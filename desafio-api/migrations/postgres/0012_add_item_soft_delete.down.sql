@@ -0,0 +1,8 @@
+DELETE FROM item_revisions WHERE action = 'RESTORE';
+ALTER TABLE item_revisions DROP CONSTRAINT IF EXISTS item_revisions_action_check;
+ALTER TABLE item_revisions ADD CONSTRAINT item_revisions_action_check CHECK (action IN ('CREATE', 'UPDATE', 'DELETE'));
+
+DELETE FROM items WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_items_deleted_at;
+ALTER TABLE items DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE items DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,8 @@
+ALTER TABLE items
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE NULL,
+ADD COLUMN IF NOT EXISTS deleted_by INTEGER NULL REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_items_deleted_at ON items(deleted_at);
+
+ALTER TABLE item_revisions DROP CONSTRAINT IF EXISTS item_revisions_action_check;
+ALTER TABLE item_revisions ADD CONSTRAINT item_revisions_action_check CHECK (action IN ('CREATE', 'UPDATE', 'DELETE', 'RESTORE'));
@@ -0,0 +1,5 @@
+DELETE FROM stock_movements WHERE item_id NOT IN (SELECT id FROM items);
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reservation_id_fkey;
+ALTER TABLE stock_movements
+ADD CONSTRAINT stock_movements_item_id_fkey FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+ADD CONSTRAINT stock_movements_reservation_id_fkey FOREIGN KEY (reservation_id) REFERENCES stock_reservations(id) ON DELETE CASCADE;
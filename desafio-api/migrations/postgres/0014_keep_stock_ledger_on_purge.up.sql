@@ -0,0 +1,5 @@
+-- O livro-razão é mantido quando um item excluído é removido definitivamente
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_item_id_fkey;
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reservation_id_fkey;
+ALTER TABLE stock_movements
+ADD CONSTRAINT stock_movements_reservation_id_fkey FOREIGN KEY (reservation_id) REFERENCES stock_reservations(id) ON DELETE SET NULL;
@@ -0,0 +1,40 @@
+package xlsx
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+func TestStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewStreamWriter(&buf, "Itens & Cia")
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteRow("code", "price"))
+	require.NoError(t, writer.WriteRow("A<1>", int64(150)))
+	require.NoError(t, writer.Close())
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		files[file.Name] = string(content)
+	}
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files["xl/workbook.xml"], `name="Itens &amp; Cia"`)
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">code</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">A&lt;1&gt;</t></is></c><c r="B2"><v>150</v></c></row>`)
+	assert.Contains(t, sheet, `</sheetData></worksheet>`)
+}
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}
@@ -23,10 +23,7 @@ GRANT ALL PRIVILEGES ON $MYSQL_DATABASE.* TO '$MYSQL_USER'@'%';
 FLUSH PRIVILEGES;
 "
 
-# Executar migrações
-for migration in migrations/*.sql; do
-    echo "Aplicando migração: $migration"
-    mysql -h "$DB_HOST" -u root -p"$MYSQL_ROOT_PASSWORD" "$MYSQL_DATABASE" < "$migration"
-done
+# As migrações são embutidas no binário e aplicadas pela própria API na
+# inicialização (ou manualmente com `desafio-api migrate up`).
 
 echo "Banco de dados configurado com sucesso!"
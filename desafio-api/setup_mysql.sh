@@ -26,6 +26,7 @@ $MYSQL_CMD -e "CREATE DATABASE IF NOT EXISTS $DB_NAME CHARACTER SET utf8mb4 COLL
 
 # Executa o script de migração
 echo "Aplicando migrações..."
-$MYSQL_CMD $DB_NAME < migrations/001_create_items_table.mysql.sql
+DB_DRIVER=mysql DB_HOST=$DB_HOST DB_PORT=$DB_PORT DB_USER=$DB_USER DB_PASSWORD=$DB_PASSWORD DB_NAME=$DB_NAME \
+    go run ./cmd/api migrate up
 
 echo "Configuração do banco de dados concluída com sucesso!"